@@ -0,0 +1,187 @@
+package automod
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+)
+
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+)
+
+// Username is the account AutoModerator replies are posted as. It cannot be registered.
+const Username = "AutoModerator"
+
+// Item is a post or comment being created or edited.
+type Item struct {
+	Type  string `json:"type" binding:"required,oneof=post comment"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	Flair string `json:"flair"`
+	// Edit is set when an existing item changes. Rules still act on edits but do not reply again.
+	Edit bool `json:"edit"`
+}
+
+// Author provides facts about an item's author. They are only looked up when a rule needs them.
+type Author interface {
+	AccountAge(ctx context.Context) (time.Duration, error)
+	Karma(ctx context.Context) (int, error)
+}
+
+// Match describes one rule that fired.
+type Match struct {
+	Rule         string `json:"rule"`
+	Action       string `json:"action,omitempty"`
+	ActionReason string `json:"action_reason,omitempty"`
+	Reply        string `json:"reply,omitempty"`
+}
+
+// Result combines every rule that fired. Action is the most severe action among them.
+type Result struct {
+	Action  string   `json:"action,omitempty"`
+	Matches []Match  `json:"matches"`
+	Replies []string `json:"replies,omitempty"`
+}
+
+// Reasons describes why the item was acted upon, for the moderation queue.
+func (r Result) Reasons() []string {
+	var reasons []string
+	for _, match := range r.Matches {
+		if match.Action == "" {
+			continue
+		}
+		reason := "automod rule " + match.Rule
+		if match.ActionReason != "" {
+			reason += ": " + match.ActionReason
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons
+}
+
+// Evaluate runs rules against item in order.
+func Evaluate(ctx context.Context, rules []Rule, item Item, author Author) (Result, error) {
+	result := Result{Matches: []Match{}}
+	for _, rule := range rules {
+		fired, err := rule.matches(ctx, item, author)
+		if err != nil {
+			return Result{}, err
+		}
+		if !fired {
+			continue
+		}
+
+		match := Match{Rule: rule.Name, Action: rule.Action, ActionReason: rule.ActionReason}
+		if rule.Reply != "" && !item.Edit {
+			match.Reply = rule.Reply
+			result.Replies = append(result.Replies, rule.Reply)
+		}
+		result.Matches = append(result.Matches, match)
+		if severity(rule.Action) > severity(result.Action) {
+			result.Action = rule.Action
+		}
+	}
+	return result, nil
+}
+
+func (rule Rule) matches(ctx context.Context, item Item, author Author) (bool, error) {
+	if rule.Type != "" && rule.Type != item.Type {
+		return false, nil
+	}
+	if rule.RequireFlair && (item.Type != TypePost || item.Flair != "") {
+		return false, nil
+	}
+	if rule.TitleMatches != "" && (item.Type != TypePost || !matchPattern(rule.titlePattern, rule.TitleMatches, item.Title)) {
+		return false, nil
+	}
+	if rule.BodyMatches != "" && !matchPattern(rule.bodyPattern, rule.BodyMatches, item.Text) {
+		return false, nil
+	}
+
+	if condition := rule.Author; condition != nil {
+		if condition.AccountAgeBelowDays > 0 {
+			age, err := author.AccountAge(ctx)
+			if err != nil {
+				return false, err
+			}
+			if age >= time.Duration(condition.AccountAgeBelowDays)*24*time.Hour {
+				return false, nil
+			}
+		}
+		if condition.KarmaBelow != nil {
+			karma, err := author.Karma(ctx)
+			if err != nil {
+				return false, err
+			}
+			if karma >= *condition.KarmaBelow {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// matchPattern reports whether value matches pattern, using compiled when the rule was prepared by
+// Validate or read from the database. Patterns are validated when saved, so one that fails to compile
+// never matches.
+func matchPattern(compiled *regexp.Regexp, pattern string, value string) bool {
+	if compiled == nil {
+		var err error
+		if compiled, err = regexp.Compile(pattern); err != nil {
+			return false
+		}
+	}
+	return compiled.MatchString(value)
+}
+
+func severity(action string) int {
+	switch action {
+	case ActionRemove:
+		return 2
+	case ActionFilter:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// StaticAuthor supplies author facts directly, for dry runs against a hypothetical author.
+type StaticAuthor struct {
+	AccountAgeDays *int `json:"account_age_days" binding:"omitempty,min=0"`
+	KarmaPoints    *int `json:"karma"`
+}
+
+func (a StaticAuthor) AccountAge(ctx context.Context) (time.Duration, error) {
+	if a.AccountAgeDays == nil {
+		return 0, errors.New("a rule needs the author's account age")
+	}
+	return time.Duration(*a.AccountAgeDays) * 24 * time.Hour, nil
+}
+
+func (a StaticAuthor) Karma(ctx context.Context) (int, error) {
+	if a.KarmaPoints == nil {
+		return 0, errors.New("a rule needs the author's karma")
+	}
+	return *a.KarmaPoints, nil
+}
+
+// UnevaluatedReason is recorded on items held because their community's rules could not be evaluated.
+const UnevaluatedReason = "AutoModerator rules could not be evaluated"
+
+// Status merges spam filter reasons with the rules' result into the item's moderation status and reasons.
+func (r Result) Status(spamReasons []string) (string, []string) {
+	reasons := append(append([]string{}, spamReasons...), r.Reasons()...)
+	switch {
+	case r.Action == ActionRemove:
+		return common.ModStatusRemoved, reasons
+	case r.Action == ActionFilter || len(spamReasons) > 0:
+		return common.ModStatusFiltered, reasons
+	default:
+		return "", nil
+	}
+}
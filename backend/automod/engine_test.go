@@ -0,0 +1,109 @@
+package automod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const sampleRules = `
+rules:
+  - name: no-crypto
+    type: post
+    title_matches: "(?i)crypto"
+    action: remove
+    action_reason: off topic
+  - name: flair-please
+    require_flair: true
+    reply: Please add a flair.
+  - name: new-accounts
+    type: comment
+    author:
+      account_age_below_days: 3
+      karma_below: 10
+    action: filter
+`
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestParseAcceptsYAMLAndJSON(t *testing.T) {
+	rules, err := Parse([]byte(sampleRules))
+	assert.NoError(t, err)
+	assert.Len(t, rules, 3)
+	assert.Equal(t, 10, *rules[2].Author.KarmaBelow)
+
+	rules, err = Parse([]byte(`{"rules": [{"name": "links", "body_matches": "https?://", "action": "filter"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, ActionFilter, rules[0].Action)
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	for name, document := range map[string]string{
+		"unknown key":    `rules: [{name: a, title_match: x, action: remove}]`,
+		"no condition":   `rules: [{name: a, action: remove}]`,
+		"no action":      `rules: [{name: a, body_matches: x}]`,
+		"bad pattern":    `rules: [{name: a, body_matches: "(", action: remove}]`,
+		"duplicate name": `rules: [{name: a, body_matches: x, action: remove}, {name: a, body_matches: y, action: remove}]`,
+		"flair comment":  `rules: [{name: a, type: comment, require_flair: true, action: remove}]`,
+	} {
+		_, err := Parse([]byte(document))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEvaluateCombinesMatchingRules(t *testing.T) {
+	rules, _ := Parse([]byte(sampleRules))
+
+	result, err := Evaluate(context.Background(), rules, Item{Type: TypePost, Title: "Buy CRYPTO now"}, StaticAuthor{})
+	assert.NoError(t, err)
+	assert.Equal(t, ActionRemove, result.Action)
+	assert.Equal(t, []string{"Please add a flair."}, result.Replies)
+	assert.Equal(t, []string{"automod rule no-crypto: off topic"}, result.Reasons())
+
+	result, _ = Evaluate(context.Background(), rules, Item{Type: TypePost, Title: "Crypto", Flair: "News", Edit: true}, StaticAuthor{})
+	assert.Empty(t, result.Replies)
+	assert.Len(t, result.Matches, 1)
+}
+
+func TestEvaluateAuthorConditions(t *testing.T) {
+	rules, _ := Parse([]byte(sampleRules))
+	comment := Item{Type: TypeComment, Text: "hello"}
+
+	result, err := Evaluate(context.Background(), rules, comment, StaticAuthor{AccountAgeDays: intPtr(1), KarmaPoints: intPtr(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, ActionFilter, result.Action)
+
+	result, _ = Evaluate(context.Background(), rules, comment, StaticAuthor{AccountAgeDays: intPtr(1), KarmaPoints: intPtr(50)})
+	assert.Empty(t, result.Action)
+
+	_, err = Evaluate(context.Background(), rules, comment, StaticAuthor{})
+	assert.Error(t, err)
+}
+
+func TestResultStatusMergesSpamReasons(t *testing.T) {
+	status, reasons := Result{}.Status([]string{"duplicate"})
+	assert.Equal(t, common.ModStatusFiltered, status)
+	assert.Equal(t, []string{"duplicate"}, reasons)
+
+	status, reasons = Result{}.Status(nil)
+	assert.Empty(t, status)
+	assert.Nil(t, reasons)
+}
+
+func TestRulePatternsAreCompiledOnce(t *testing.T) {
+	rules, err := Parse([]byte(sampleRules))
+	assert.NoError(t, err)
+	assert.NotNil(t, rules[0].titlePattern, "Validate compiles the patterns")
+
+	data, err := bson.Marshal(rules[0])
+	assert.NoError(t, err)
+	var stored Rule
+	assert.NoError(t, bson.Unmarshal(data, &stored))
+	assert.NotNil(t, stored.titlePattern, "reading a stored rule compiles its patterns")
+	assert.Equal(t, "(?i)crypto", stored.TitleMatches)
+}
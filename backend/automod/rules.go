@@ -0,0 +1,150 @@
+package automod
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ActionRemove = "remove"
+	ActionFilter = "filter"
+)
+
+// MaxRules is how many rules a community may configure.
+const MaxRules = 50
+
+// Rule fires when every condition it sets matches an item, then applies its action and reply.
+type Rule struct {
+	Name string `json:"name" yaml:"name" bson:"name"`
+	// Type limits the rule to "post" or "comment" items; empty matches both.
+	Type         string `json:"type,omitempty" yaml:"type,omitempty" bson:"type,omitempty"`
+	TitleMatches string `json:"title_matches,omitempty" yaml:"title_matches,omitempty" bson:"title_matches,omitempty"`
+	BodyMatches  string `json:"body_matches,omitempty" yaml:"body_matches,omitempty" bson:"body_matches,omitempty"`
+	// RequireFlair fires on posts submitted without a flair.
+	RequireFlair bool             `json:"require_flair,omitempty" yaml:"require_flair,omitempty" bson:"require_flair,omitempty"`
+	Author       *AuthorCondition `json:"author,omitempty" yaml:"author,omitempty" bson:"author,omitempty"`
+	// Action is ActionRemove, ActionFilter or empty to only reply.
+	Action       string `json:"action,omitempty" yaml:"action,omitempty" bson:"action,omitempty"`
+	ActionReason string `json:"action_reason,omitempty" yaml:"action_reason,omitempty" bson:"action_reason,omitempty"`
+	// Reply is posted as a stickied comment by AutoModerator when the rule fires on a new item.
+	Reply string `json:"reply,omitempty" yaml:"reply,omitempty" bson:"reply,omitempty"`
+
+	// titlePattern and bodyPattern are TitleMatches and BodyMatches compiled by Validate or when the
+	// rule is read from the database, so evaluating a rule never compiles a pattern again.
+	titlePattern *regexp.Regexp
+	bodyPattern  *regexp.Regexp
+}
+
+// UnmarshalBSON decodes a stored rule and compiles its patterns.
+func (rule *Rule) UnmarshalBSON(data []byte) error {
+	type storedRule Rule
+	if err := bson.Unmarshal(data, (*storedRule)(rule)); err != nil {
+		return err
+	}
+	rule.compile()
+	return nil
+}
+
+// compile prepares the rule's patterns. Patterns are validated when saved, so one that fails to compile
+// is left nil and never matches.
+func (rule *Rule) compile() {
+	rule.titlePattern, _ = compilePattern(rule.TitleMatches)
+	rule.bodyPattern, _ = compilePattern(rule.BodyMatches)
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// AuthorCondition matches on the item's author. Both thresholds must hold when both are set.
+type AuthorCondition struct {
+	AccountAgeBelowDays int  `json:"account_age_below_days,omitempty" yaml:"account_age_below_days,omitempty" bson:"account_age_below_days,omitempty"`
+	KarmaBelow          *int `json:"karma_below,omitempty" yaml:"karma_below,omitempty" bson:"karma_below,omitempty"`
+}
+
+// RuleSet is the document moderators upload, in YAML or JSON.
+type RuleSet struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Parse reads a rule set written as YAML or JSON, rejecting unknown keys so typos do not silently disable a rule.
+func Parse(data []byte) ([]Rule, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var set RuleSet
+	if err := decoder.Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid rules document: %v", err)
+	}
+	if err := Validate(set.Rules); err != nil {
+		return nil, err
+	}
+	return set.Rules, nil
+}
+
+// Validate checks that every rule has a unique name, at least one condition, something to do and valid patterns,
+// and compiles the patterns of the rules it accepts.
+func Validate(rules []Rule) error {
+	if len(rules) > MaxRules {
+		return fmt.Errorf("at most %d rules are allowed", MaxRules)
+	}
+
+	names := map[string]bool{}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %v", rule.Name, err)
+		}
+		rules[i].compile()
+	}
+	return nil
+}
+
+func (rule Rule) validate() error {
+	switch rule.Type {
+	case "", TypePost, TypeComment:
+	default:
+		return errors.New("type must be post or comment")
+	}
+	switch rule.Action {
+	case "", ActionRemove, ActionFilter:
+	default:
+		return errors.New("action must be remove or filter")
+	}
+	if rule.Action == "" && rule.Reply == "" {
+		return errors.New("needs an action or a reply")
+	}
+	if rule.RequireFlair && rule.Type == TypeComment {
+		return errors.New("require_flair only applies to posts")
+	}
+	if rule.TitleMatches != "" && rule.Type == TypeComment {
+		return errors.New("title_matches only applies to posts")
+	}
+	if rule.Author != nil && rule.Author.AccountAgeBelowDays == 0 && rule.Author.KarmaBelow == nil {
+		return errors.New("author needs account_age_below_days or karma_below")
+	}
+	if rule.TitleMatches == "" && rule.BodyMatches == "" && !rule.RequireFlair && rule.Author == nil {
+		return errors.New("needs at least one condition")
+	}
+	for _, pattern := range []string{rule.TitleMatches, rule.BodyMatches} {
+		if _, err := compilePattern(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+	return nil
+}
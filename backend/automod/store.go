@@ -0,0 +1,78 @@
+package automod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store reads the author facts rules check and posts AutoModerator's replies.
+// It is implemented over the user, post and comment repositories, which import this package.
+type Store interface {
+	// AccountCreated returns when username registered, or common.ErrNotFound.
+	AccountCreated(ctx context.Context, username string) (time.Time, error)
+	// Karma is the score of everything username posted and commented.
+	Karma(ctx context.Context, username string) (int, error)
+	// InsertReply stores text as a comment by Username on the post, replying to parentID when it is set,
+	// and counts it on the post.
+	InsertReply(ctx context.Context, postID primitive.ObjectID, communityID primitive.ObjectID, parentID primitive.ObjectID, text string) error
+}
+
+var store common.Holder[Store]
+
+// SetStore installs the store used by NewAuthor and Reply.
+func SetStore(s Store) {
+	store.Set(s)
+}
+
+func currentStore() Store {
+	return store.Get()
+}
+
+// storedAuthor looks up author facts in the store, at most once each.
+type storedAuthor struct {
+	username string
+	age      *time.Duration
+	karma    *int
+}
+
+// NewAuthor returns the Author facts of a registered user.
+func NewAuthor(username string) Author {
+	return &storedAuthor{username: username}
+}
+
+func (a *storedAuthor) AccountAge(ctx context.Context) (time.Duration, error) {
+	if a.age == nil {
+		created, err := currentStore().AccountCreated(ctx, a.username)
+		if errors.Is(err, common.ErrNotFound) {
+			return 0, fmt.Errorf("user %s not found", a.username)
+		}
+		if err != nil {
+			return 0, err
+		}
+		age := time.Since(created)
+		a.age = &age
+	}
+	return *a.age, nil
+}
+
+// Karma is the score of everything the author posted and commented.
+func (a *storedAuthor) Karma(ctx context.Context) (int, error) {
+	if a.karma == nil {
+		karma, err := currentStore().Karma(ctx, a.username)
+		if err != nil {
+			return 0, err
+		}
+		a.karma = &karma
+	}
+	return *a.karma, nil
+}
+
+// Reply posts text as a stickied AutoModerator comment on a post, or as a reply to a comment when parentID is set.
+func Reply(ctx context.Context, postID primitive.ObjectID, communityID primitive.ObjectID, parentID primitive.ObjectID, text string) error {
+	return currentStore().InsertReply(ctx, postID, communityID, parentID, text)
+}
@@ -0,0 +1,51 @@
+package blocks
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+)
+
+type memoryRepository struct {
+	blocks *memstore.Table[Block]
+}
+
+// NewMemoryRepository keeps blocks in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{blocks: memstore.NewTable[Block]()}
+}
+
+func (r *memoryRepository) IsBlocked(ctx context.Context, blocker string, blocked string) (bool, error) {
+	return r.blocks.Count(between(blocker, blocked)) > 0, nil
+}
+
+func (r *memoryRepository) BlockedUsernames(ctx context.Context, blocker string) ([]string, error) {
+	usernames := []string{}
+	for _, block := range r.blocks.Find(func(block Block) bool { return block.Blocker == blocker }) {
+		usernames = append(usernames, block.Blocked)
+	}
+	return usernames, nil
+}
+
+func (r *memoryRepository) Block(ctx context.Context, block Block) error {
+	err := r.blocks.Insert(block, between(block.Blocker, block.Blocked))
+	if err == common.ErrDuplicate {
+		return nil
+	}
+	return err
+}
+
+func (r *memoryRepository) Unblock(ctx context.Context, blocker string, blocked string) error {
+	r.blocks.Delete(between(blocker, blocked))
+	return nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, blocker string, page common.PageRequest) ([]Block, error) {
+	rows := r.blocks.Find(func(block Block) bool { return block.Blocker == blocker })
+	return memstore.Page(rows, page, true), nil
+}
+
+func between(blocker string, blocked string) func(Block) bool {
+	return func(block Block) bool { return block.Blocker == blocker && block.Blocked == blocked }
+}
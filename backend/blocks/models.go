@@ -0,0 +1,26 @@
+package blocks
+
+import (
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Block records that Blocker no longer wants to hear from Blocked.
+type Block struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Blocker      string             `json:"blocker" bson:"blocker,omitempty"`
+	Blocked      string             `json:"blocked" bson:"blocked,omitempty"`
+	CreationDate time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
+}
+
+func (b Block) GetID() primitive.ObjectID {
+	return b.ID
+}
+
+// BlockListResponse is the data of the caller's block list.
+type BlockListResponse struct {
+	Blocks     []Block           `json:"blocks"`
+	Pagination common.Pagination `json:"pagination"`
+}
@@ -0,0 +1,75 @@
+package blocks
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	blocks *mongo.Collection
+}
+
+// NewMongoRepository stores blocks in the blocks collection of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{blocks: db.Collection("blocks")}
+}
+
+func (r *mongoRepository) IsBlocked(ctx context.Context, blocker string, blocked string) (bool, error) {
+	count, err := r.blocks.CountDocuments(ctx, bson.M{"blocker": blocker, "blocked": blocked})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *mongoRepository) BlockedUsernames(ctx context.Context, blocker string) ([]string, error) {
+	values, err := r.blocks.Distinct(ctx, "blocked", bson.M{"blocker": blocker})
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make([]string, 0, len(values))
+	for _, value := range values {
+		if username, ok := value.(string); ok {
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames, nil
+}
+
+func (r *mongoRepository) Block(ctx context.Context, block Block) error {
+	filter := bson.M{"blocker": block.Blocker, "blocked": block.Blocked}
+	update := bson.M{"$setOnInsert": block}
+	_, err := r.blocks.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoRepository) Unblock(ctx context.Context, blocker string, blocked string) error {
+	_, err := r.blocks.DeleteOne(ctx, bson.M{"blocker": blocker, "blocked": blocked})
+	return err
+}
+
+func (r *mongoRepository) List(ctx context.Context, blocker string, page common.PageRequest) ([]Block, error) {
+	filter := bson.M{"blocker": blocker}
+	if page.HasAfter {
+		filter["_id"] = bson.M{"$lt": page.AfterID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.blocks.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Block
+	err = cursor.All(ctx, &results)
+	return results, err
+}
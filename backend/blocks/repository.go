@@ -0,0 +1,29 @@
+package blocks
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+)
+
+// Repository stores the blocks between users.
+type Repository interface {
+	IsBlocked(ctx context.Context, blocker string, blocked string) (bool, error)
+	BlockedUsernames(ctx context.Context, blocker string) ([]string, error)
+	// Block stores block unless its blocker already blocked the same user.
+	Block(ctx context.Context, block Block) error
+	Unblock(ctx context.Context, blocker string, blocked string) error
+	// List returns the blocks of blocker, most recent first, reading up to page.Limit+1 of them.
+	List(ctx context.Context, blocker string, page common.PageRequest) ([]Block, error)
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+func repo() Repository {
+	return repository.Get()
+}
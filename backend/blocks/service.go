@@ -0,0 +1,113 @@
+package blocks
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IsBlocked reports whether blocker has blocked blocked.
+func IsBlocked(ctx context.Context, blocker string, blocked string) (bool, error) {
+	return repo().IsBlocked(ctx, blocker, blocked)
+}
+
+// BlockedUsernames lists everyone blocker has blocked. Anonymous viewers have blocked no one.
+func BlockedUsernames(ctx context.Context, blocker string) ([]string, error) {
+	if blocker == "" {
+		return nil, nil
+	}
+	return repo().BlockedUsernames(ctx, blocker)
+}
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []func(ctx context.Context, blocker string, blocked string) error
+)
+
+// OnBlock registers fn to run after a user blocks another, so packages that depend on blocks
+// can undo relations between the two users. Call it from an init function.
+func OnBlock(fn func(ctx context.Context, blocker string, blocked string) error) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, fn)
+}
+
+// BlockUser stops the user in the URL from contacting the caller.
+func BlockUser(c *gin.Context) {
+	blocker := c.GetString("username")
+	blocked := c.Param("username")
+	if blocked == blocker {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "You cannot block yourself"))
+		return
+	}
+
+	exists, err := users.Exists(c.Request.Context(), blocked)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to block user"))
+		return
+	}
+	if !exists {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.USER_NOT_FOUND, "User not found"))
+		return
+	}
+
+	block := Block{
+		ID:           primitive.NewObjectID(),
+		Blocker:      blocker,
+		Blocked:      blocked,
+		CreationDate: time.Now(),
+	}
+	if err := repo().Block(c.Request.Context(), block); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error blocking user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to block user"))
+		return
+	}
+
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(c.Request.Context(), blocker, blocked); err != nil {
+			logging.FromContext(c.Request.Context()).Error("error running block hook", "error", err)
+		}
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "User blocked successfully"})
+}
+
+// UnblockUser removes the caller's block on the user in the URL.
+func UnblockUser(c *gin.Context) {
+	if err := repo().Unblock(c.Request.Context(), c.GetString("username"), c.Param("username")); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error unblocking user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to unblock user"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "User unblocked successfully"})
+}
+
+// GetBlocks retrieves a bounded page of the users the caller has blocked, most recent first.
+func GetBlocks(c *gin.Context) {
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	results, err := repo().List(c.Request.Context(), c.GetString("username"), page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding blocks", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve blocks"))
+		return
+	}
+
+	blocks, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, BlockListResponse{Blocks: blocks, Pagination: pagination})
+}
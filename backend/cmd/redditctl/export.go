@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// userExport is everything stored about one user. Documents are exported as stored, except that
+// the account leaves out the password hash.
+type userExport struct {
+	ExportedAt              time.Time `json:"exported_at"`
+	Account                 account   `json:"account"`
+	Profile                 []bson.M  `json:"profile"`
+	Posts                   []bson.M  `json:"posts"`
+	Comments                []bson.M  `json:"comments"`
+	Votes                   []bson.M  `json:"votes"`
+	Saved                   []bson.M  `json:"saved"`
+	Following               []bson.M  `json:"following"`
+	Followers               []bson.M  `json:"followers"`
+	Blocks                  []bson.M  `json:"blocks"`
+	Conversations           []bson.M  `json:"conversations"`
+	Messages                []bson.M  `json:"messages"`
+	Notifications           []bson.M  `json:"notifications"`
+	NotificationPreferences []bson.M  `json:"notification_preferences"`
+	JoinRequests            []bson.M  `json:"community_join_requests"`
+	ModerationActions       []bson.M  `json:"moderation_actions"`
+}
+
+type account struct {
+	ID               string     `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	IsAdmin          bool       `json:"is_admin"`
+	Suspended        bool       `json:"suspended"`
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+}
+
+func runExport(ctx context.Context, db *mongo.Database, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	username := args[0]
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("out", "", "file to write instead of standard output")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		return errUsage
+	}
+
+	user, err := users.Repo().FindByUsername(ctx, username)
+	if errors.Is(err, common.ErrNotFound) {
+		return fmt.Errorf("no user named %q", username)
+	}
+	if err != nil {
+		return err
+	}
+
+	export := userExport{
+		ExportedAt: time.Now().UTC(),
+		Account: account{
+			ID:               user.ID.Hex(),
+			Username:         user.Username,
+			Email:            user.Email,
+			IsAdmin:          user.IsAdmin,
+			Suspended:        user.Suspended,
+			SuspensionReason: user.SuspensionReason,
+		},
+	}
+	if !user.SuspendedUntil.IsZero() {
+		export.Account.SuspendedUntil = &user.SuspendedUntil
+	}
+
+	queries := []struct {
+		dst        *[]bson.M
+		collection string
+		filter     bson.M
+	}{
+		{&export.Profile, "profiles", bson.M{"user_id": user.ID}},
+		{&export.Posts, "posts", bson.M{"username": username}},
+		{&export.Comments, "comments", bson.M{"username": username}},
+		{&export.Votes, "votes", bson.M{"username": username}},
+		{&export.Saved, "saved", bson.M{"username": username}},
+		{&export.Following, "follows", bson.M{"follower": username}},
+		{&export.Followers, "follows", bson.M{"followed": username}},
+		{&export.Blocks, "blocks", bson.M{"blocker": username}},
+		{&export.Conversations, "conversations", bson.M{"participants": username}},
+		{&export.Messages, "messages", bson.M{"$or": bson.A{bson.M{"sender": username}, bson.M{"recipient": username}}}},
+		{&export.Notifications, "notifications", bson.M{"username": username}},
+		{&export.NotificationPreferences, "notification_preferences", bson.M{"username": username}},
+		{&export.JoinRequests, "community_join_requests", bson.M{"username": username}},
+		{&export.ModerationActions, "modlog", bson.M{"moderator": username}},
+	}
+	for _, q := range queries {
+		cursor, err := db.Collection(q.collection).Find(ctx, q.filter)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", q.collection, err)
+		}
+		*q.dst = []bson.M{}
+		if err := cursor.All(ctx, q.dst); err != nil {
+			return fmt.Errorf("reading %s: %w", q.collection, err)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(export)
+}
@@ -0,0 +1,112 @@
+// Command redditctl runs operational chores against the backend's MongoDB database. It reads the same
+// configuration as the server: the environment, .env and the file given with --config.
+//
+//	redditctl [--config file] <command> [arguments]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/store"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, db *mongo.Database, args []string) error
+}
+
+var commands = []command{
+	{"user", "create <username> --email <email> [--admin] | suspend <username> [--for 72h] [--reason text] | unsuspend|promote|demote <username>",
+		"manage accounts; create reads the password from standard input", runUser},
+	{"recount", "", "recompute the vote, comment and crosspost counters of posts and comments", runRecount},
+	{"indexes", "rebuild [--drop]", "create missing indexes, or drop and recreate them all", runIndexes},
+	{"migrate", "up|status", "apply pending migrations, or list every migration and when it was applied", runMigrate},
+	{"purge", "[--older-than 720h] [--dry-run]", "delete posts and comments removed by moderators longer ago than --older-than", runPurge},
+	{"export", "<username> [--out file]", "write everything stored about a user as JSON", runExport},
+}
+
+// errUsage makes main print the command's usage.
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	configPath := flag.String("config", "", "YAML config file; defaults to $CONFIG_FILE")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := findCommand(flag.Arg(0))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "redditctl: unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := configs.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.Storage != configs.StorageMongo {
+		fmt.Fprintln(os.Stderr, "redditctl: STORAGE must be mongo; there is nothing to manage in memory")
+		os.Exit(2)
+	}
+	configs.Use(cfg)
+	level, _ := logging.ParseLevel(cfg.Log.Level)
+	logging.Configure(os.Stderr, cfg.Log.Format, level)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	configs.ConnectDB()
+	db := configs.Database()
+	store.UseMongo(db)
+
+	err = cmd.run(ctx, db, flag.Args()[1:])
+
+	disconnectCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = configs.DisconnectDB(disconnectCtx)
+
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "usage: redditctl %s\n", strings.TrimSpace(cmd.name+" "+cmd.args))
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "redditctl %s: %v\n", cmd.name, err)
+		os.Exit(1)
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "usage: redditctl [--config file] <command> [arguments]")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %s\n    \t%s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.summary)
+	}
+	fmt.Fprintln(out, "\nflags:")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/migrations"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func runRecount(ctx context.Context, db *mongo.Database, args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	if err := migrations.RecountCounters(ctx, db); err != nil {
+		return err
+	}
+	fmt.Println("recounted vote, comment and crosspost counters")
+	return nil
+}
+
+// runIndexes creates missing indexes, or with --drop first drops every index but _id so that changed
+// definitions are recreated. Unique indexes are missing while it runs, so stop traffic before --drop.
+func runIndexes(ctx context.Context, db *mongo.Database, args []string) error {
+	if len(args) == 0 || args[0] != "rebuild" {
+		return errUsage
+	}
+	fs := flag.NewFlagSet("indexes rebuild", flag.ContinueOnError)
+	drop := fs.Bool("drop", false, "drop the existing indexes first")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		return errUsage
+	}
+
+	if *drop {
+		var names []string
+		for name := range configs.Indexes() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := db.Collection(name).Indexes().DropAll(ctx); err != nil {
+				return fmt.Errorf("dropping indexes of %s: %w", name, err)
+			}
+			fmt.Printf("dropped indexes of %s\n", name)
+		}
+	}
+	if err := configs.EnsureIndexes(ctx, db); err != nil {
+		return err
+	}
+	fmt.Println("indexes are in place")
+	return nil
+}
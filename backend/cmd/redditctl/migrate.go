@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/migrations"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func runMigrate(ctx context.Context, db *mongo.Database, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(ctx, db)
+		for _, m := range applied {
+			fmt.Printf("applied %d %s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+
+	case "status":
+		statuses, err := migrations.Statuses(ctx, db)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.Applied() {
+				applied = s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			name := s.Name
+			if s.Unknown {
+				name += " (unknown to this binary)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, name, applied)
+		}
+		return w.Flush()
+	}
+	return errUsage
+}
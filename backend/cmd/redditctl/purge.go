@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/votes"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const purgeBatchSize = 500
+
+// runPurge deletes the posts and comments moderators removed, once they have stayed removed for
+// --older-than, together with their votes, saves and, for posts, their comments. Removed items are
+// kept that long so that removals can be reviewed and reverted.
+func runPurge(ctx context.Context, _ *mongo.Database, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "only purge items removed longer ago than this")
+	dryRun := fs.Bool("dry-run", false, "count what would be purged without deleting anything")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return errUsage
+	}
+	cutoff := time.Now().Add(-*olderThan)
+
+	removedAt, err := modlog.LatestActions(ctx, modlog.ActionRemovePost, modlog.ActionRemoveComment)
+	if err != nil {
+		return err
+	}
+	removedPosts, err := listAll(func(page common.PageRequest) ([]posts.Post, error) {
+		return posts.Repo().List(ctx, posts.PostQuery{ModStatus: common.ModStatusRemoved}, page)
+	})
+	if err != nil {
+		return err
+	}
+	removedComments, err := listAll(func(page common.PageRequest) ([]comments.Comment, error) {
+		return comments.Repo().List(ctx, comments.CommentQuery{ModStatus: common.ModStatusRemoved}, page, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Items removed without a modlog entry, such as those AutoModerator removed as they were posted,
+	// count from their creation.
+	purgeable := func(id primitive.ObjectID, created time.Time) bool {
+		at, ok := removedAt[id]
+		if !ok {
+			at = created
+		}
+		return at.Before(cutoff)
+	}
+	var oldPosts []posts.Post
+	for _, post := range removedPosts {
+		if purgeable(post.ID, post.CreationDate) {
+			oldPosts = append(oldPosts, post)
+		}
+	}
+	var oldComments []comments.Comment
+	for _, comment := range removedComments {
+		if purgeable(comment.ID, comment.CreationDate) {
+			oldComments = append(oldComments, comment)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("would purge %d posts and %d comments removed before %s\n", len(oldPosts), len(oldComments), cutoff.UTC().Format(time.RFC3339))
+		return nil
+	}
+
+	log := purgeLog{names: map[primitive.ObjectID]string{}}
+	// Removed comments go first, so that those under removed posts are only counted once.
+	err = eachBatch(oldComments, func(batch []comments.Comment) error {
+		ids := make([]primitive.ObjectID, len(batch))
+		for i, comment := range batch {
+			ids[i] = comment.ID
+		}
+		if err := purgeComments(ctx, ids); err != nil {
+			return err
+		}
+		for _, comment := range batch {
+			log.record(ctx, comment.Community, modlog.ActionPurgeComment, modlog.TargetComment, comment.ID, comment.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var postComments int
+	err = eachBatch(oldPosts, func(batch []posts.Post) error {
+		n, err := purgePosts(ctx, batch)
+		if err != nil {
+			return err
+		}
+		postComments += n
+		for _, post := range batch {
+			log.record(ctx, post.Community, modlog.ActionPurgePost, modlog.TargetPost, post.ID, post.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("purged %d posts with %d comments under them, and %d comments\n", len(oldPosts), postComments, len(oldComments))
+	return nil
+}
+
+// purgePosts deletes posts with their comments, votes and saves, and keeps crosspost counters and
+// snapshots consistent the way deleting a post does. It returns how many comments it deleted.
+func purgePosts(ctx context.Context, batch []posts.Post) (int, error) {
+	var commentIDs []primitive.ObjectID
+	for _, post := range batch {
+		under, err := listAll(func(page common.PageRequest) ([]comments.Comment, error) {
+			return comments.Repo().List(ctx, comments.CommentQuery{PostID: post.ID}, page, false)
+		})
+		if err != nil {
+			return 0, err
+		}
+		for _, comment := range under {
+			commentIDs = append(commentIDs, comment.ID)
+		}
+	}
+	if err := eachBatch(commentIDs, func(ids []primitive.ObjectID) error { return purgeComments(ctx, ids) }); err != nil {
+		return 0, err
+	}
+
+	postIDs := make([]primitive.ObjectID, len(batch))
+	for i, post := range batch {
+		postIDs[i] = post.ID
+	}
+	if err := deleteTargets(ctx, postIDs); err != nil {
+		return 0, err
+	}
+	for _, post := range batch {
+		if err := posts.Repo().Delete(ctx, post.ID); err != nil && !errors.Is(err, common.ErrNotFound) {
+			return 0, err
+		}
+		if err := posts.DetachCrossposts(ctx, post); err != nil {
+			return 0, err
+		}
+	}
+	return len(commentIDs), nil
+}
+
+func purgeComments(ctx context.Context, commentIDs []primitive.ObjectID) error {
+	if err := deleteTargets(ctx, commentIDs); err != nil {
+		return err
+	}
+	for _, id := range commentIDs {
+		if err := comments.Repo().Delete(ctx, id); err != nil && !errors.Is(err, common.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteTargets deletes the votes and saves of the posts or comments with targetIDs.
+func deleteTargets(ctx context.Context, targetIDs []primitive.ObjectID) error {
+	if err := votes.DeleteTargets(ctx, targetIDs); err != nil {
+		return err
+	}
+	return posts.Repo().DeleteSaved(ctx, targetIDs)
+}
+
+// purgeLog records purged items in the moderation log of their community, looking each community's name up once.
+type purgeLog struct {
+	names map[primitive.ObjectID]string
+}
+
+func (l purgeLog) record(ctx context.Context, communityID primitive.ObjectID, action string, targetType string, targetID primitive.ObjectID, targetUser string) {
+	name, ok := l.names[communityID]
+	if !ok {
+		community, _ := communities.FindByID(ctx, communityID)
+		name, l.names[communityID] = community.Name, community.Name
+	}
+	modlog.Record(ctx, modlog.Entry{
+		CommunityID:   communityID,
+		CommunityName: name,
+		Moderator:     modlog.Operator,
+		Action:        action,
+		TargetType:    targetType,
+		TargetID:      targetID,
+		TargetUser:    targetUser,
+	})
+}
+
+// listAll reads every page of a listing, newest first.
+func listAll[T interface{ GetID() primitive.ObjectID }](list func(page common.PageRequest) ([]T, error)) ([]T, error) {
+	var all []T
+	page := common.PageRequest{Limit: purgeBatchSize}
+	for {
+		results, err := list(page)
+		if err != nil {
+			return nil, err
+		}
+		items, pagination := common.ApplyCursorPage(results, page.Limit)
+		all = append(all, items...)
+		if !pagination.HasMore {
+			return all, nil
+		}
+		page.AfterID, page.HasAfter = items[len(items)-1].GetID(), true
+	}
+}
+
+// eachBatch calls purge with consecutive batches of up to purgeBatchSize items.
+func eachBatch[T any](items []T, purge func(batch []T) error) error {
+	for len(items) > 0 {
+		size := purgeBatchSize
+		if len(items) < size {
+			size = len(items)
+		}
+		if err := purge(items[:size]); err != nil {
+			return err
+		}
+		items = items[size:]
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func runUser(ctx context.Context, _ *mongo.Database, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	action, username, rest := args[0], args[1], args[2:]
+
+	var err error
+	var logged, details string
+	switch action {
+	case "create":
+		err = createUser(ctx, username, rest)
+	case "suspend":
+		details, err = suspendUser(ctx, username, rest)
+		logged = modlog.ActionSuspendUser
+	case "unsuspend":
+		err = noArgs(rest, func() error { return users.Repo().Unsuspend(ctx, username) })
+		logged = modlog.ActionUnsuspendUser
+	case "promote":
+		err = noArgs(rest, func() error { return users.Repo().SetAdmin(ctx, username, true) })
+		logged = modlog.ActionPromoteUser
+	case "demote":
+		err = noArgs(rest, func() error { return users.Repo().SetAdmin(ctx, username, false) })
+		logged = modlog.ActionDemoteUser
+	default:
+		return errUsage
+	}
+	if errors.Is(err, common.ErrNotFound) {
+		return fmt.Errorf("no user named %q", username)
+	}
+	if err != nil {
+		return err
+	}
+	if logged != "" {
+		modlog.Record(ctx, modlog.Entry{Moderator: modlog.Operator, Action: logged, TargetType: modlog.TargetUser, TargetUser: username, Details: details})
+	}
+	fmt.Printf("%sd %s\n", strings.TrimSuffix(action, "e"), username)
+	return nil
+}
+
+func noArgs(args []string, run func() error) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	return run()
+}
+
+// createUser creates an account the way signup does, reading the password from the first line of
+// standard input so that it stays out of the shell history.
+func createUser(ctx context.Context, username string, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := fs.String("email", "", "email address to sign in with (required)")
+	admin := fs.Bool("admin", false, "make the user a site administrator")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 || *email == "" {
+		return errUsage
+	}
+	if strings.EqualFold(username, automod.Username) {
+		return fmt.Errorf("%s is reserved", automod.Username)
+	}
+
+	fmt.Fprint(os.Stderr, "password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return errors.New("the password must not be empty")
+	}
+	hashed, err := users.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user := common.User{ID: primitive.NewObjectID(), Username: username, Email: *email, Password: hashed, IsAdmin: *admin}
+	if err := users.Repo().Create(ctx, user); errors.Is(err, common.ErrDuplicate) {
+		return errors.New("the username or email is taken")
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// suspendUser suspends the account and describes the suspension for the moderation log.
+func suspendUser(ctx context.Context, username string, args []string) (string, error) {
+	fs := flag.NewFlagSet("user suspend", flag.ContinueOnError)
+	duration := fs.Duration("for", 0, "how long the suspension lasts, e.g. 72h; indefinitely when omitted")
+	reason := fs.String("reason", "", "reason shown to the user when they try to sign in")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 || *duration < 0 {
+		return "", errUsage
+	}
+
+	var until time.Time
+	details := "indefinitely"
+	if *duration > 0 {
+		until = time.Now().Add(*duration).UTC()
+		details = "until " + until.Format(time.RFC3339)
+	}
+	if *reason != "" {
+		details += ": " + *reason
+	}
+	return details, users.Repo().Suspend(ctx, username, until, *reason)
+}
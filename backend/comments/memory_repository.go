@@ -0,0 +1,139 @@
+package comments
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRepository struct {
+	comments *memstore.Table[Comment]
+}
+
+// NewMemoryRepository keeps comments in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{comments: memstore.NewTable[Comment]()}
+}
+
+func (r *memoryRepository) Insert(ctx context.Context, comment Comment) error {
+	return r.comments.Insert(comment, nil)
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Comment, error) {
+	return r.comments.Get(id)
+}
+
+func (r *memoryRepository) List(ctx context.Context, query CommentQuery, page common.PageRequest, oldestFirst bool) ([]Comment, error) {
+	rows := r.comments.Find(query.matches)
+	if !query.StickiedFirst {
+		return memstore.Page(rows, page, !oldestFirst), nil
+	}
+
+	var stickied, others []Comment
+	for _, comment := range rows {
+		if comment.Stickied {
+			stickied = append(stickied, comment)
+		} else {
+			others = append(others, comment)
+		}
+	}
+	othersPage := page
+	if page.HasAfter {
+		if cursor, err := r.comments.Get(page.AfterID); err == nil && cursor.Stickied {
+			othersPage.HasAfter = false
+		} else {
+			stickied = nil
+		}
+	}
+
+	results := append(memstore.Page(stickied, page, !oldestFirst), memstore.Page(others, othersPage, !oldestFirst)...)
+	if page.Limit > 0 && int64(len(results)) > page.Limit+1 {
+		results = results[:page.Limit+1]
+	}
+	return results, nil
+}
+
+func (r *memoryRepository) Count(ctx context.Context, query CommentQuery) (int64, error) {
+	return r.comments.Count(query.matches), nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, id primitive.ObjectID, update CommentUpdate) (Comment, error) {
+	return r.comments.UpdateOne(withID(id), func(comment *Comment) {
+		if update.Text != nil {
+			comment.Text = *update.Text
+		}
+		if update.ContentHash != nil {
+			comment.ContentHash = *update.ContentHash
+		}
+		if update.UpdationDate != nil {
+			comment.UpdationDate = *update.UpdationDate
+		}
+		if update.Edited != nil {
+			comment.Edited = *update.Edited
+		}
+		if update.ClearModeration {
+			comment.ModStatus, comment.FilterReasons = "", nil
+		}
+		if update.ModStatus != nil {
+			comment.ModStatus = *update.ModStatus
+		}
+		if update.FilterReasons != nil {
+			comment.FilterReasons = *update.FilterReasons
+		}
+	})
+}
+
+func (r *memoryRepository) AddVotes(ctx context.Context, id primitive.ObjectID, upVotes int, downVotes int) (Comment, error) {
+	return r.comments.UpdateOne(withID(id), func(comment *Comment) {
+		comment.UpVotes += upVotes
+		comment.DownVotes += downVotes
+	})
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if len(r.comments.Delete(withID(id))) == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryRepository) Score(ctx context.Context, username string) (int, error) {
+	score := 0
+	for _, comment := range r.comments.Find(func(comment Comment) bool { return comment.Username == username }) {
+		score += comment.UpVotes - comment.DownVotes
+	}
+	return score, nil
+}
+
+// matches mirrors the filter the Mongo repository builds from the query.
+func (q CommentQuery) matches(comment Comment) bool {
+	if !q.PostID.IsZero() && comment.PostID != q.PostID {
+		return false
+	}
+	if !q.Community.IsZero() && comment.Community != q.Community {
+		return false
+	}
+	if q.Username != "" && comment.Username != q.Username {
+		return false
+	}
+	if q.Visible {
+		if comment.ModStatus != "" {
+			return false
+		}
+	} else if q.ModStatus != "" && comment.ModStatus != q.ModStatus {
+		return false
+	}
+	if q.ContentHash != "" && comment.ContentHash != q.ContentHash {
+		return false
+	}
+	if !q.CreatedSince.IsZero() && comment.CreationDate.Before(q.CreatedSince) {
+		return false
+	}
+	return q.ExcludeID.IsZero() || comment.ID != q.ExcludeID
+}
+
+func withID(id primitive.ObjectID) func(Comment) bool {
+	return func(comment Comment) bool { return comment.ID == id }
+}
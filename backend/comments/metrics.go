@@ -0,0 +1,11 @@
+package comments
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var commentsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "simple_reddit_comments_created_total",
+	Help: "Comments created, by moderation state: visible, filtered or removed.",
+}, []string{"status"})
@@ -3,34 +3,64 @@ package comments
 import (
 	"time"
 
-	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/content"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var CommentsCollection *mongo.Collection = configs.GetCollection("comments")
-
 // Comment stores the discussion item plus denormalized vote counters needed for reads.
 type Comment struct {
 	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	PostID       primitive.ObjectID `json:"post_id" bson:"post_id,omitempty"`
+	Community    primitive.ObjectID `json:"community,omitempty" bson:"community,omitempty"`
+	ParentID     primitive.ObjectID `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
 	Text         string             `json:"text" bson:"text,omitempty"`
+	TextHTML     string             `json:"text_html" bson:"-"`
 	CreationDate time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
 	UpdationDate time.Time          `json:"updation_date" bson:"updation_date,omitempty"`
 	UpVotes      int                `json:"up_votes" bson:"up_votes"`
 	DownVotes    int                `json:"down_votes" bson:"down_votes"`
 	Username     string             `json:"username" bson:"username,omitempty"`
 	Edited       bool               `json:"edited" bson:"edited"`
+	Stickied     bool               `json:"stickied" bson:"stickied,omitempty"`
+	Collapsed    bool               `json:"collapsed" bson:"-"`
+	// ModStatus is empty for visible comments, or one of common.ModStatusFiltered and common.ModStatusRemoved.
+	ModStatus string `json:"mod_status,omitempty" bson:"mod_status,omitempty"`
+	// FilterReasons are only sent in the moderation queue, so authors cannot learn what the filters match.
+	FilterReasons []string `json:"filter_reasons,omitempty" bson:"filter_reasons,omitempty"`
+	ContentHash   string   `json:"-" bson:"content_hash,omitempty"`
 }
 
 func (c Comment) GetID() primitive.ObjectID {
 	return c.ID
 }
 
+// forDisplay prepares a comment for a response: TextHTML is rendered from the stored markdown,
+// the vote counters are fuzzed and the filter reasons are dropped.
+func (c Comment) forDisplay() Comment {
+	c.TextHTML = content.RenderMarkdown(c.Text)
+	c.FilterReasons = nil
+	c.UpVotes, c.DownVotes = common.FuzzVoteCounts(c.ID, c.UpVotes, c.DownVotes)
+	return c
+}
+
 type CreateCommentRequest struct {
-	Text string `json:"text" binding:"required,min=1,max=10000"`
+	Text     string             `json:"text" binding:"required,min=1,max=10000"`
+	ParentID primitive.ObjectID `json:"parent_id"`
 }
 
 type UpdateCommentRequest struct {
 	Text string `json:"text" binding:"required,min=1,max=10000"`
 }
+
+// CommentResponse is the data of responses about one new comment.
+type CommentResponse struct {
+	Message string  `json:"message"`
+	Comment Comment `json:"comment"`
+}
+
+// CommentListResponse is the data of every paginated comment listing.
+type CommentListResponse struct {
+	Comments   []Comment         `json:"comments"`
+	Pagination common.Pagination `json:"pagination"`
+}
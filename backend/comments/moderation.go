@@ -0,0 +1,137 @@
+package comments
+
+import (
+	"net/http"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApproveComment publishes a comment held by the spam filter or restores a removed one.
+func ApproveComment(c *gin.Context) {
+	comment, community, ok := findModeratedComment(c)
+	if !ok {
+		return
+	}
+	if comment.ModStatus == "" {
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Comment already approved"})
+		return
+	}
+
+	if _, err := Repo().Update(c.Request.Context(), comment.ID, CommentUpdate{ClearModeration: true}); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error approving comment", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update comment"))
+		return
+	}
+	recordCommentAction(c, community, comment, modlog.ActionApproveComment)
+
+	comment.ModStatus, comment.FilterReasons = "", nil
+	showComment(c.Request.Context(), comment)
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Comment approved successfully"})
+}
+
+// RemoveComment hides a comment from everyone but its author and the community's moderators.
+func RemoveComment(c *gin.Context) {
+	comment, community, ok := findModeratedComment(c)
+	if !ok {
+		return
+	}
+	if comment.ModStatus == common.ModStatusRemoved {
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Comment already removed"})
+		return
+	}
+
+	removed := common.ModStatusRemoved
+	if _, err := Repo().Update(c.Request.Context(), comment.ID, CommentUpdate{ModStatus: &removed}); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error removing comment", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update comment"))
+		return
+	}
+	recordCommentAction(c, community, comment, modlog.ActionRemoveComment)
+
+	if comment.ModStatus == "" {
+		hideComment(c.Request.Context(), comment)
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Comment removed successfully"})
+}
+
+// GetModQueue lists the comments of a community held by the spam filter, newest first, for its moderators.
+func GetModQueue(c *gin.Context) {
+	community, ok := communities.FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	query := CommentQuery{Community: community.ID, ModStatus: common.ModStatusFiltered}
+	results, err := Repo().List(c.Request.Context(), query, page, false)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding queued comments", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve moderation queue"))
+		return
+	}
+
+	comments, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, CommentListResponse{Comments: comments, Pagination: pagination})
+}
+
+// findModeratedComment loads the comment in the URL and checks that the caller moderates the community of its post.
+func findModeratedComment(c *gin.Context) (Comment, communities.Community, bool) {
+	commentID, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid comment ID"))
+		return Comment{}, communities.Community{}, false
+	}
+
+	comment, err := Repo().FindByID(c.Request.Context(), commentID)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.COMMENT_NOT_FOUND, "Comment not found"))
+		return Comment{}, communities.Community{}, false
+	}
+
+	communityID := comment.Community
+	if communityID.IsZero() {
+		if post, err := posts.Repo().FindByID(c.Request.Context(), comment.PostID); err == nil {
+			communityID = post.Community
+		}
+	}
+
+	community, err := communities.FindByID(c.Request.Context(), communityID)
+	if err != nil || !community.IsModerator(c.GetString("username")) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Only moderators of this community can do that"))
+		return Comment{}, communities.Community{}, false
+	}
+	return comment, community, true
+}
+
+// maxLoggedText bounds how much of a comment is copied into the moderation log.
+const maxLoggedText = 200
+
+func recordCommentAction(c *gin.Context, community communities.Community, comment Comment, action string) {
+	details := []rune(comment.Text)
+	if len(details) > maxLoggedText {
+		details = append(details[:maxLoggedText], '…')
+	}
+	modlog.Record(c.Request.Context(), modlog.Entry{
+		CommunityID:   community.ID,
+		CommunityName: community.Name,
+		Moderator:     c.GetString("username"),
+		Action:        action,
+		TargetType:    modlog.TargetComment,
+		TargetID:      comment.ID,
+		TargetUser:    comment.Username,
+		Details:       string(details),
+	})
+}
@@ -0,0 +1,179 @@
+package comments
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	comments *mongo.Collection
+}
+
+// NewMongoRepository stores comments in the comments collection of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{comments: db.Collection("comments")}
+}
+
+func (r *mongoRepository) Insert(ctx context.Context, comment Comment) error {
+	_, err := r.comments.InsertOne(ctx, comment)
+	return common.MongoError(err)
+}
+
+func (r *mongoRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Comment, error) {
+	var comment Comment
+	err := r.comments.FindOne(ctx, bson.M{"_id": id}).Decode(&comment)
+	return comment, common.MongoError(err)
+}
+
+func (r *mongoRepository) List(ctx context.Context, query CommentQuery, page common.PageRequest, oldestFirst bool) ([]Comment, error) {
+	filter := commentFilter(query)
+	order, cursorOperator := -1, "$lt"
+	if oldestFirst {
+		order, cursorOperator = 1, "$gt"
+	}
+	sort := bson.D{{Key: "_id", Value: order}}
+	if query.StickiedFirst {
+		sort = append(bson.D{{Key: "stickied", Value: -1}}, sort...)
+	}
+	if page.HasAfter {
+		if err := r.addCursorCondition(ctx, filter, query, page.AfterID, cursorOperator); err != nil {
+			return nil, err
+		}
+	}
+
+	findOptions := options.Find().
+		SetSort(sort).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.comments.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Comment
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+// addCursorCondition selects the comments that follow the cursor comment afterID. When stickied comments
+// come first, a stickied cursor is followed by the later stickied comments and then by every other comment.
+func (r *mongoRepository) addCursorCondition(ctx context.Context, filter bson.M, query CommentQuery, afterID primitive.ObjectID, cursorOperator string) error {
+	if !query.StickiedFirst {
+		common.AddCondition(filter, "_id", cursorOperator, afterID)
+		return nil
+	}
+
+	var cursor Comment
+	opts := options.FindOne().SetProjection(bson.M{"stickied": 1})
+	err := r.comments.FindOne(ctx, bson.M{"_id": afterID}, opts).Decode(&cursor)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if cursor.Stickied {
+		filter["$or"] = bson.A{
+			bson.M{"stickied": true, "_id": bson.M{cursorOperator: afterID}},
+			bson.M{"stickied": bson.M{"$ne": true}},
+		}
+		return nil
+	}
+	filter["stickied"] = bson.M{"$ne": true}
+	common.AddCondition(filter, "_id", cursorOperator, afterID)
+	return nil
+}
+
+func (r *mongoRepository) Count(ctx context.Context, query CommentQuery) (int64, error) {
+	return r.comments.CountDocuments(ctx, commentFilter(query))
+}
+
+func (r *mongoRepository) Update(ctx context.Context, id primitive.ObjectID, update CommentUpdate) (Comment, error) {
+	set := bson.M{}
+	if update.Text != nil {
+		set["text"] = *update.Text
+	}
+	if update.ContentHash != nil {
+		set["content_hash"] = *update.ContentHash
+	}
+	if update.UpdationDate != nil {
+		set["updation_date"] = *update.UpdationDate
+	}
+	if update.Edited != nil {
+		set["edited"] = *update.Edited
+	}
+	if update.ModStatus != nil {
+		set["mod_status"] = *update.ModStatus
+	}
+	if update.FilterReasons != nil {
+		set["filter_reasons"] = *update.FilterReasons
+	}
+
+	document := bson.M{}
+	if len(set) > 0 {
+		document["$set"] = set
+	}
+	if update.ClearModeration {
+		document["$unset"] = bson.M{"mod_status": "", "filter_reasons": ""}
+	}
+
+	var comment Comment
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.comments.FindOneAndUpdate(ctx, bson.M{"_id": id}, document, opts).Decode(&comment)
+	return comment, common.MongoError(err)
+}
+
+func (r *mongoRepository) AddVotes(ctx context.Context, id primitive.ObjectID, upVotes int, downVotes int) (Comment, error) {
+	var comment Comment
+	inc := bson.M{"up_votes": upVotes, "down_votes": downVotes}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.comments.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$inc": inc}, opts).Decode(&comment)
+	return comment, common.MongoError(err)
+}
+
+func (r *mongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.comments.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoRepository) Score(ctx context.Context, username string) (int, error) {
+	return common.SumScore(ctx, r.comments, username)
+}
+
+func commentFilter(query CommentQuery) bson.M {
+	filter := bson.M{}
+	if !query.PostID.IsZero() {
+		filter["post_id"] = query.PostID
+	}
+	if !query.Community.IsZero() {
+		filter["community"] = query.Community
+	}
+	if query.Username != "" {
+		filter["username"] = query.Username
+	}
+	if query.Visible {
+		filter["mod_status"] = bson.M{"$exists": false}
+	} else if query.ModStatus != "" {
+		filter["mod_status"] = query.ModStatus
+	}
+	if query.ContentHash != "" {
+		filter["content_hash"] = query.ContentHash
+	}
+	if !query.CreatedSince.IsZero() {
+		filter["creation_date"] = bson.M{"$gte": query.CreatedSince}
+	}
+	if !query.ExcludeID.IsZero() {
+		common.AddCondition(filter, "_id", "$ne", query.ExcludeID)
+	}
+	return filter
+}
@@ -0,0 +1,68 @@
+package comments
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommentQuery selects comments. Zero fields match every comment.
+type CommentQuery struct {
+	PostID    primitive.ObjectID
+	Community primitive.ObjectID
+	Username  string
+	// Visible selects comments no moderation state hides. ModStatus selects comments in exactly that state.
+	Visible   bool
+	ModStatus string
+	// ContentHash, CreatedSince and ExcludeID serve the spam filters' lookups of recent similar comments.
+	ContentHash  string
+	CreatedSince time.Time
+	ExcludeID    primitive.ObjectID
+	// StickiedFirst lists stickied comments, such as AutoModerator's replies, ahead of the rest, as a post's
+	// thread does. It orders the results and selects nothing.
+	StickiedFirst bool
+}
+
+// CommentUpdate changes only the fields that are set. ClearModeration approves the comment, dropping its
+// moderation state and filter reasons; it is applied before ModStatus and FilterReasons.
+type CommentUpdate struct {
+	Text            *string
+	ContentHash     *string
+	UpdationDate    *time.Time
+	Edited          *bool
+	ClearModeration bool
+	ModStatus       *string
+	FilterReasons   *[]string
+}
+
+// Repository stores comments. Lookups that find nothing return common.ErrNotFound.
+type Repository interface {
+	Insert(ctx context.Context, comment Comment) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (Comment, error)
+	// List returns the matching comments, reading up to page.Limit+1 of them: oldest first when
+	// oldestFirst is set, as threads are read, and newest first otherwise.
+	List(ctx context.Context, query CommentQuery, page common.PageRequest, oldestFirst bool) ([]Comment, error)
+	Count(ctx context.Context, query CommentQuery) (int64, error)
+	// Update applies update to the comment with id and returns the updated comment.
+	Update(ctx context.Context, id primitive.ObjectID, update CommentUpdate) (Comment, error)
+	// AddVotes adds to the vote counters of the comment with id and returns the updated comment.
+	AddVotes(ctx context.Context, id primitive.ObjectID, upVotes int, downVotes int) (Comment, error)
+	// Delete removes the comment with id, or returns common.ErrNotFound.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// Score sums the up votes minus the down votes of every comment written by username.
+	Score(ctx context.Context, username string) (int, error)
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+// Repo returns the repository installed with SetRepository, for packages that need to read or count comments.
+func Repo() Repository {
+	return repository.Get()
+}
@@ -1,154 +1,368 @@
 package comments
 
 import (
-	"log"
+	"context"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/blocks"
 	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/events"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/notifications"
 	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/spam"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // CreateComment creates a new comment.
 func CreateComment(c *gin.Context) {
 	postID, err := primitive.ObjectIDFromHex(c.Param("postId"))
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid post ID"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid post ID"))
 		return
 	}
 
 	var req CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Error binding JSON: %v", err)
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": "Invalid request body"})
+		common.RespondWithError(c, common.BindError(err))
 		return
 	}
 
+	post, ok := posts.FindAccessiblePost(c, postID, communities.AccessWrite)
+	if !ok {
+		return
+	}
+	if post.Locked {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.POST_LOCKED, "This post is locked and no longer accepts comments"))
+		return
+	}
+
+	var parent Comment
+	if !req.ParentID.IsZero() {
+		parent, err = Repo().FindByID(c.Request.Context(), req.ParentID)
+		if err != nil || parent.PostID != postID {
+			common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.COMMENT_NOT_FOUND, "Parent comment not found"))
+			return
+		}
+	}
+
+	username := c.GetString("username")
+	for _, author := range []string{post.Username, parent.Username} {
+		if author == "" {
+			continue
+		}
+		blocked, err := blocks.IsBlocked(c.Request.Context(), author, username)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("error checking blocks", "error", err)
+			common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to create comment"))
+			return
+		}
+		if blocked {
+			common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.USER_BLOCKED, "You cannot reply to this user"))
+			return
+		}
+	}
+
 	now := time.Now()
 	comment := Comment{
 		ID:           primitive.NewObjectID(),
 		PostID:       postID,
+		Community:    post.Community,
+		ParentID:     req.ParentID,
 		Text:         req.Text,
 		CreationDate: now,
 		UpdationDate: now,
 		Edited:       false,
 		UpVotes:      0,
 		DownVotes:    0,
-		Username:     c.GetString("username"),
+		Username:     username,
+		ContentHash:  spam.ContentHash("", req.Text),
 	}
+	var replies []string
+	comment.ModStatus, comment.FilterReasons, replies = screen(c.Request.Context(), comment, false)
 
-	_, err = CommentsCollection.InsertOne(c.Request.Context(), comment)
-	if err != nil {
-		log.Printf("Error creating comment: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to create comment"})
+	if err := Repo().Insert(c.Request.Context(), comment); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error creating comment", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to create comment"))
+		return
+	}
+	commentsCreated.WithLabelValues(common.ModStatusLabel(comment.ModStatus)).Inc()
+
+	for _, reply := range replies {
+		if err := automod.Reply(c.Request.Context(), postID, post.Community, comment.ID, reply); err != nil {
+			logging.FromContext(c.Request.Context()).Error("error posting automod reply", "error", err)
+		}
+	}
+	switch comment.ModStatus {
+	case common.ModStatusFiltered:
+		common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, CommentResponse{Message: "Comment submitted for moderator review", Comment: comment.forDisplay()})
+		return
+	case common.ModStatusRemoved:
+		recordAutomodRemoval(c.Request.Context(), comment)
+		common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, CommentResponse{Message: "Comment was removed by AutoModerator", Comment: comment.forDisplay()})
 		return
 	}
 
-	_, _ = posts.PostCollection.UpdateOne(c.Request.Context(), bson.M{"_id": postID}, bson.M{"$inc": bson.M{"comments_count": 1}})
+	_, _ = posts.Repo().AddCounters(c.Request.Context(), postID, posts.Counters{Comments: 1})
 
-	common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, gin.H{"message": "Comment created successfully", "comment": comment})
+	notifyReplies(c.Request.Context(), comment, post, parent)
+	comment = comment.forDisplay()
+	events.PublishCommentEvent(postID, events.CommentCreated, comment.Username, comment)
+
+	common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, CommentResponse{Message: "Comment created successfully", Comment: comment})
 }
 
 // GetCommentsByPostId retrieves a bounded page of comments for a post.
 func GetCommentsByPostId(c *gin.Context) {
 	postID, err := primitive.ObjectIDFromHex(c.Param("postId"))
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid post ID"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid post ID"))
 		return
 	}
 
 	page, err := common.ParsePageRequest(c)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": err.Error()})
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
 		return
 	}
 
-	filter := bson.M{"post_id": postID}
-	if page.HasAfter {
-		filter["_id"] = bson.M{"$gt": page.AfterID}
+	if _, ok := posts.FindAccessiblePost(c, postID, communities.AccessRead); !ok {
+		return
 	}
 
-	findOptions := options.Find().
-		SetSort(bson.D{{Key: "_id", Value: 1}}).
-		SetLimit(page.Limit + 1)
-
-	cursor, err := CommentsCollection.Find(c.Request.Context(), filter, findOptions)
+	results, err := Repo().List(c.Request.Context(), CommentQuery{PostID: postID, Visible: true, StickiedFirst: true}, page, true)
 	if err != nil {
-		log.Printf("Error finding comments: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to retrieve comments"})
+		logging.FromContext(c.Request.Context()).Error("error finding comments", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve comments"))
 		return
 	}
-	defer cursor.Close(c.Request.Context())
 
-	var results []Comment
-	if err = cursor.All(c.Request.Context(), &results); err != nil {
-		log.Printf("Error decoding comments: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to retrieve comments"})
+	comments, pagination := common.ApplyCursorPage(results, page.Limit)
+	if err := collapseBlocked(c.Request.Context(), c.GetString("username"), comments); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error loading blocked users", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve comments"))
 		return
 	}
+	for i := range comments {
+		comments[i] = comments[i].forDisplay()
+	}
 
-	comments, pagination := common.ApplyCursorPage(results, page.Limit)
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"comments": comments, "pagination": pagination})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, CommentListResponse{Comments: comments, Pagination: pagination})
 }
 
 // UpdateComment updates a comment.
 func UpdateComment(c *gin.Context) {
 	commentID, err := primitive.ObjectIDFromHex(c.Param("commentId"))
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid comment ID"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid comment ID"))
 		return
 	}
 
 	var req UpdateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Error binding JSON: %v", err)
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": "Invalid request body"})
+		common.RespondWithError(c, common.BindError(err))
 		return
 	}
 
-	filter := bson.M{"_id": commentID, "username": c.GetString("username")}
-	update := bson.M{"$set": bson.M{"text": req.Text, "updation_date": time.Now(), "edited": true}}
+	existing, ok := findOwnComment(c, commentID)
+	if !ok {
+		return
+	}
+	if _, ok := posts.FindAccessiblePost(c, existing.PostID, communities.AccessWrite); !ok {
+		return
+	}
 
-	result, err := CommentsCollection.UpdateOne(c.Request.Context(), filter, update)
+	now, edited, hash := time.Now(), true, spam.ContentHash("", req.Text)
+	update := CommentUpdate{Text: &req.Text, UpdationDate: &now, Edited: &edited, ContentHash: &hash}
+	// Edits can send a comment to the moderation queue or remove it, but never bring it back; only moderators approve.
+	if existing.ModStatus == "" {
+		existing.Text, existing.UpdationDate = req.Text, now
+		if status, reasons, _ := screen(c.Request.Context(), existing, true); status != "" {
+			update.ModStatus, update.FilterReasons = &status, &reasons
+		}
+	}
+
+	updated, err := Repo().Update(c.Request.Context(), commentID, update)
+	if errors.Is(err, common.ErrNotFound) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Comment not found or not owned by user"))
+		return
+	}
 	if err != nil {
-		log.Printf("Error updating comment: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to update comment"})
+		logging.FromContext(c.Request.Context()).Error("error updating comment", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update comment"))
 		return
 	}
-	if result.MatchedCount == 0 {
-		common.RespondWithJSON(c, http.StatusForbidden, common.FORBIDDEN, gin.H{"error": "Comment not found or not owned by user"})
+
+	if existing.ModStatus == "" && updated.ModStatus != "" {
+		hideComment(c.Request.Context(), updated)
+		message := "Comment updated and submitted for moderator review"
+		if updated.ModStatus == common.ModStatusRemoved {
+			recordAutomodRemoval(c.Request.Context(), updated)
+			message = "Comment updated and removed by AutoModerator"
+		}
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: message})
 		return
 	}
+	if updated.ModStatus == "" {
+		events.PublishCommentEvent(updated.PostID, events.CommentUpdated, updated.Username, updated.forDisplay())
+	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Comment updated successfully"})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Comment updated successfully"})
 }
 
 // DeleteComment deletes a comment.
 func DeleteComment(c *gin.Context) {
 	commentID, err := primitive.ObjectIDFromHex(c.Param("commentId"))
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid comment ID"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid comment ID"))
 		return
 	}
 
-	var existing Comment
-	if err := CommentsCollection.FindOne(c.Request.Context(), bson.M{"_id": commentID, "username": c.GetString("username")}).Decode(&existing); err != nil {
-		common.RespondWithJSON(c, http.StatusForbidden, common.FORBIDDEN, gin.H{"error": "Comment not found or not owned by user"})
+	existing, ok := findOwnComment(c, commentID)
+	if !ok {
+		return
+	}
+	if _, ok := posts.FindAccessiblePost(c, existing.PostID, communities.AccessRead); !ok {
 		return
 	}
 
-	_, err = CommentsCollection.DeleteOne(c.Request.Context(), bson.M{"_id": commentID, "username": c.GetString("username")})
-	if err != nil {
-		log.Printf("Error deleting comment: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to delete comment"})
+	if err := Repo().Delete(c.Request.Context(), commentID); err != nil && !errors.Is(err, common.ErrNotFound) {
+		logging.FromContext(c.Request.Context()).Error("error deleting comment", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to delete comment"))
 		return
 	}
 
-	_, _ = posts.PostCollection.UpdateOne(c.Request.Context(), bson.M{"_id": existing.PostID}, bson.M{"$inc": bson.M{"comments_count": -1}})
+	if existing.ModStatus == "" {
+		hideComment(c.Request.Context(), existing)
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Comment deleted successfully"})
+}
+
+// findOwnComment loads the comment with commentID if the caller wrote it, and responds with an error otherwise.
+func findOwnComment(c *gin.Context, commentID primitive.ObjectID) (Comment, bool) {
+	comment, err := Repo().FindByID(c.Request.Context(), commentID)
+	if err == nil && comment.Username == c.GetString("username") {
+		return comment, true
+	}
+	if err != nil && !errors.Is(err, common.ErrNotFound) {
+		logging.FromContext(c.Request.Context()).Error("error finding comment", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve comment"))
+		return Comment{}, false
+	}
+	common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Comment not found or not owned by user"))
+	return Comment{}, false
+}
+
+// collapseBlocked hides the text of comments written by users the viewer has blocked.
+// The comments stay in the page so replies keep their context and pagination is unchanged.
+func collapseBlocked(ctx context.Context, viewer string, comments []Comment) error {
+	if viewer == "" || len(comments) == 0 {
+		return nil
+	}
+
+	blocked, err := blocks.BlockedUsernames(ctx, viewer)
+	if err != nil {
+		return err
+	}
+
+	blockedSet := make(map[string]bool, len(blocked))
+	for _, username := range blocked {
+		blockedSet[username] = true
+	}
+	for i := range comments {
+		if blockedSet[comments[i].Username] {
+			comments[i].Collapsed = true
+			comments[i].Text = ""
+		}
+	}
+	return nil
+}
+
+// notifyReplies tells the parent comment's author (or the post author for top-level comments) about the reply,
+// then notifies anyone mentioned in the comment text.
+func notifyReplies(ctx context.Context, comment Comment, post posts.Post, parent Comment) {
+	recipient := post.Username
+	notificationType := notifications.TypePostReply
+	if !parent.ID.IsZero() {
+		recipient = parent.Username
+		notificationType = notifications.TypeCommentReply
+	}
+
+	notifications.Notify(ctx, notifications.Notification{
+		Username:  recipient,
+		Type:      notificationType,
+		Actor:     comment.Username,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+	})
+	notifications.NotifyMentions(ctx, comment.Username, comment.Text, comment.PostID, comment.ID, recipient)
+}
+
+func recordAutomodRemoval(ctx context.Context, comment Comment) {
+	community, _ := communities.FindByID(ctx, comment.Community)
+	modlog.Record(ctx, modlog.Entry{
+		CommunityID:   comment.Community,
+		CommunityName: community.Name,
+		Moderator:     automod.Username,
+		Action:        modlog.ActionRemoveComment,
+		TargetType:    modlog.TargetComment,
+		TargetID:      comment.ID,
+		TargetUser:    comment.Username,
+		Details:       strings.Join(comment.FilterReasons, "; "),
+	})
+}
+
+// screen runs the spam filter chain and AutoModerator rules of the post's community over a comment.
+// It returns the comment's moderation status, why it was held or removed, and the replies AutoModerator should post.
+// Moderators' comments are never screened.
+func screen(ctx context.Context, comment Comment, edit bool) (string, []string, []string) {
+	community, err := communities.FindByID(ctx, comment.Community)
+	if err != nil && !errors.Is(err, common.ErrNotFound) {
+		logging.FromContext(ctx).Error("error finding community", "error", err)
+	}
+	if community.IsModerator(comment.Username) {
+		return "", nil, nil
+	}
+
+	spamReasons := spam.Check(ctx, spam.Item{
+		Kind:      spam.KindComment,
+		ID:        comment.ID,
+		Community: comment.Community,
+		Settings:  community.SpamFilter,
+		Username:  comment.Username,
+		Edit:      edit,
+		Text:      comment.Text,
+		Now:       comment.UpdationDate,
+	})
+
+	item := automod.Item{Type: automod.TypeComment, Text: comment.Text, Edit: edit}
+	result, err := automod.Evaluate(ctx, community.AutomodRules, item, automod.NewAuthor(comment.Username))
+	if err != nil {
+		logging.FromContext(ctx).Error("error evaluating automod rules", "error", err)
+		// Fail closed: a comment the rules could not check waits for a moderator instead of being published.
+		spamReasons = append(spamReasons, automod.UnevaluatedReason)
+	}
+
+	status, reasons := result.Status(spamReasons)
+	return status, reasons, result.Replies
+}
+
+// showComment counts a comment that became visible on its post and tells live viewers about it.
+func showComment(ctx context.Context, comment Comment) {
+	_, _ = posts.Repo().AddCounters(ctx, comment.PostID, posts.Counters{Comments: 1})
+	events.PublishCommentEvent(comment.PostID, events.CommentCreated, comment.Username, comment.forDisplay())
+}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Comment deleted successfully"})
+// hideComment stops counting a comment that is no longer visible on its post and tells live viewers to drop it.
+func hideComment(ctx context.Context, comment Comment) {
+	_, _ = posts.Repo().AddCounters(ctx, comment.PostID, posts.Counters{Comments: -1})
+	events.PublishPostEvent(comment.PostID, events.CommentDeleted, gin.H{"id": comment.ID})
 }
@@ -0,0 +1,157 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// APIError is an error response: the HTTP status and code of the envelope, a human-readable
+// description and, for invalid request bodies, what is wrong with each field.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+// FieldError describes one invalid field of a request body, named as it appears in the JSON.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func NewAPIError(status int, code string, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (%d): %s", e.Code, e.Status, e.Message)
+}
+
+// InvalidField reports a request body field that bound cleanly but failed one of the handler's own checks.
+func InvalidField(field string, message string) *APIError {
+	err := NewAPIError(http.StatusBadRequest, INVALID_REQUEST_BODY, field+" "+message)
+	err.Fields = []FieldError{{Field: field, Message: message}}
+	return err
+}
+
+// ErrorData is the data of every error envelope.
+type ErrorData struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// RespondWithError writes err in the common envelope and aborts the remaining handlers.
+func RespondWithError(c *gin.Context, err *APIError) {
+	RespondWithJSON(c, err.Status, err.Code, ErrorData{Error: err.Message, Fields: err.Fields})
+	c.Abort()
+}
+
+func init() {
+	// Report validation failures with the JSON field names clients send rather than Go field names.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// BindError turns an error from ShouldBindJSON into an INVALID_REQUEST_BODY response listing
+// every invalid field.
+func BindError(err error) *APIError {
+	apiErr := NewAPIError(http.StatusBadRequest, INVALID_REQUEST_BODY, "Invalid request body")
+
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+	var syntaxError *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrors):
+		for _, fieldErr := range validationErrors {
+			apiErr.Fields = append(apiErr.Fields, FieldError{Field: fieldPath(fieldErr), Message: validationMessage(fieldErr)})
+		}
+	case errors.As(err, &typeError):
+		apiErr.Fields = []FieldError{{Field: typeError.Field, Message: "must be " + jsonTypeName(typeError.Type)}}
+	case errors.As(err, &syntaxError), errors.Is(err, io.ErrUnexpectedEOF):
+		apiErr.Message = "Request body is not valid JSON"
+	case errors.Is(err, io.EOF):
+		apiErr.Message = "Request body is empty"
+	}
+	return apiErr
+}
+
+// fieldPath drops the request struct's name from the validator namespace, e.g.
+// "CreatePostRequest.title" becomes "title" and "UpdateCommunityRequest.rules[0].name" becomes "rules[0].name".
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	kind := fieldErr.Kind()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fieldErr.Param() + sizeUnit(kind)
+	case "max":
+		return "must be at most " + fieldErr.Param() + sizeUnit(kind)
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fieldErr.Param()), ", ")
+	case "email":
+		return "must be a valid email address"
+	case "hexcolor":
+		return "must be a hex color such as #ff4500"
+	default:
+		return "failed the " + fieldErr.Tag() + " check"
+	}
+}
+
+func sizeUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	default:
+		return "a " + t.String()
+	}
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type sampleTag struct {
+	Name string `json:"name" binding:"required,max=5"`
+}
+
+type sampleRequest struct {
+	Title string      `json:"title" binding:"required,min=3"`
+	Kind  string      `json:"kind" binding:"omitempty,oneof=link text"`
+	Tags  []sampleTag `json:"tags" binding:"max=2,dive"`
+	Score int         `json:"score"`
+}
+
+func bind(body string) error {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	var req sampleRequest
+	return c.ShouldBindJSON(&req)
+}
+
+func TestBindErrorReportsFieldsByJSONName(t *testing.T) {
+	err := BindError(bind(`{"title": "ab", "kind": "video", "tags": [{"name": "toolong"}]}`))
+
+	assert.Equal(t, http.StatusBadRequest, err.Status)
+	assert.Equal(t, INVALID_REQUEST_BODY, err.Code)
+	assert.Equal(t, []FieldError{
+		{Field: "title", Message: "must be at least 3 characters"},
+		{Field: "kind", Message: "must be one of: link, text"},
+		{Field: "tags[0].name", Message: "must be at most 5 characters"},
+	}, err.Fields)
+}
+
+func TestBindErrorReportsTypeAndSyntaxErrors(t *testing.T) {
+	err := BindError(bind(`{"title": "abc", "score": "high"}`))
+	assert.Equal(t, []FieldError{{Field: "score", Message: "must be an integer"}}, err.Fields)
+
+	err = BindError(bind(`{"title": `))
+	assert.Empty(t, err.Fields)
+	assert.Equal(t, "Request body is not valid JSON", err.Message)
+
+	err = BindError(bind(``))
+	assert.Equal(t, "Request body is empty", err.Message)
+}
+
+func TestRespondWithErrorWritesEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	RespondWithError(c, InvalidField("icon_url", "must be an http(s) URL"))
+
+	var body Envelope[ErrorData]
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusBadRequest, body.Status)
+	assert.Equal(t, INVALID_REQUEST_BODY, body.Code)
+	assert.Equal(t, "Invalid request body", body.Message)
+	assert.Equal(t, "icon_url must be an http(s) URL", body.Data.Error)
+	assert.Equal(t, []FieldError{{Field: "icon_url", Message: "must be an http(s) URL"}}, body.Data.Fields)
+}
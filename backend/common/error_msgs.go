@@ -1,18 +1,33 @@
 package common
 
 const (
-	MONGO_DB_ERROR             = "MONGO_DB_ERROR"
-	INVALID_REQUEST_BODY       = "INVALID_REQUEST_BODY"
-	INVALID_PARAM              = "INVALID_PARAM"
-	UNAUTHORIZED               = "UNAUTHORIZED"
-	EMAIL_ALREADY_EXISTS       = "EMAIL_ALREADY_EXISTS"
-	USERNAME_ALREADY_EXISTS    = "USERNAME_ALREADY_EXISTS"
-	USER_NOT_FOUND             = "USER_NOT_FOUND"
-	INCORRECT_PASSWORD         = "INCORRECT_PASSWORD"
-	COMMUNITY_NOT_FOUND        = "COMMUNITY_NOT_FOUND"
-	COMMUNITY_ALREADY_EXISTS   = "COMMUNITY_ALREADY_EXISTS"
-	POST_NOT_FOUND             = "POST_NOT_FOUND"
-	COMMENT_NOT_FOUND          = "COMMENT_NOT_FOUND"
-	INVALID_CREDENTIALS        = "INVALID_CREDENTIALS"
-	FORBIDDEN                  = "FORBIDDEN"
+	MONGO_DB_ERROR           = "MONGO_DB_ERROR"
+	INVALID_REQUEST_BODY     = "INVALID_REQUEST_BODY"
+	INVALID_PARAM            = "INVALID_PARAM"
+	UNAUTHORIZED             = "UNAUTHORIZED"
+	EMAIL_ALREADY_EXISTS     = "EMAIL_ALREADY_EXISTS"
+	USERNAME_ALREADY_EXISTS  = "USERNAME_ALREADY_EXISTS"
+	USER_NOT_FOUND           = "USER_NOT_FOUND"
+	INCORRECT_PASSWORD       = "INCORRECT_PASSWORD"
+	COMMUNITY_NOT_FOUND      = "COMMUNITY_NOT_FOUND"
+	COMMUNITY_ALREADY_EXISTS = "COMMUNITY_ALREADY_EXISTS"
+	POST_NOT_FOUND           = "POST_NOT_FOUND"
+	COMMENT_NOT_FOUND        = "COMMENT_NOT_FOUND"
+	INVALID_CREDENTIALS      = "INVALID_CREDENTIALS"
+	FORBIDDEN                = "FORBIDDEN"
+	NOTIFICATION_NOT_FOUND   = "NOTIFICATION_NOT_FOUND"
+	CONVERSATION_NOT_FOUND   = "CONVERSATION_NOT_FOUND"
+	USER_BLOCKED             = "USER_BLOCKED"
+	INVALID_FLAIR            = "INVALID_FLAIR"
+	JOIN_REQUEST_NOT_FOUND   = "JOIN_REQUEST_NOT_FOUND"
+	COMMUNITY_ACCESS_DENIED  = "COMMUNITY_ACCESS_DENIED"
+	PIN_LIMIT_REACHED        = "PIN_LIMIT_REACHED"
+	POST_LOCKED              = "POST_LOCKED"
+	FOLLOW_LIMIT_REACHED     = "FOLLOW_LIMIT_REACHED"
+	TOO_MANY_REQUESTS        = "TOO_MANY_REQUESTS"
+	ROUTE_NOT_FOUND          = "ROUTE_NOT_FOUND"
+	INTERNAL_ERROR           = "INTERNAL_ERROR"
+	SERVICE_UNAVAILABLE      = "SERVICE_UNAVAILABLE"
+	ACCOUNT_SUSPENDED        = "ACCOUNT_SUSPENDED"
+	VOTE_NOT_FOUND           = "VOTE_NOT_FOUND"
 )
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fuzzWindow is how long the displayed counts of a target keep the same noise.
+const fuzzWindow = 10 * time.Minute
+
+// FuzzVoteCounts adds up to about 5% of the votes cast to each counter, drawn separately, so neither the
+// counts nor the score (up - down) are exact. The noise is derived from the target and the current
+// fuzzWindow rather than drawn on every read, so refetching a post or following its live updates shows
+// the same noise and cannot average it away.
+func FuzzVoteCounts(targetID primitive.ObjectID, up int, down int) (int, int) {
+	return fuzzVoteCounts(targetID, up, down, time.Now())
+}
+
+func fuzzVoteCounts(targetID primitive.ObjectID, up int, down int, now time.Time) (int, int) {
+	total := up + down
+	if total <= 0 {
+		return up, down
+	}
+	rng := rand.New(rand.NewSource(fuzzSeed(targetID, now)))
+	bound := total/20 + 2
+	return up + rng.Intn(bound), down + rng.Intn(bound)
+}
+
+func fuzzSeed(targetID primitive.ObjectID, now time.Time) int64 {
+	h := fnv.New64a()
+	h.Write(targetID[:])
+	var window [8]byte
+	binary.BigEndian.PutUint64(window[:], uint64(now.Unix()/int64(fuzzWindow/time.Second)))
+	h.Write(window[:])
+	return int64(h.Sum64())
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFuzzVoteCountsIsStableWithinAWindow(t *testing.T) {
+	id := primitive.NewObjectID()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	up, down := fuzzVoteCounts(id, 400, 100, now)
+	for i := 0; i < 20; i++ {
+		again, againDown := fuzzVoteCounts(id, 400, 100, now.Add(time.Duration(i)*time.Second))
+		assert.Equal(t, [2]int{up, down}, [2]int{again, againDown}, "repeated reads must not re-roll the noise")
+	}
+	assert.InDelta(t, 400, up, 26)
+	assert.InDelta(t, 100, down, 26)
+	assert.GreaterOrEqual(t, up, 400)
+	assert.GreaterOrEqual(t, down, 100)
+
+	upZero, downZero := fuzzVoteCounts(id, 0, 0, now)
+	assert.Equal(t, [2]int{0, 0}, [2]int{upZero, downZero})
+}
+
+func TestFuzzSeedChangesWithTargetAndWindow(t *testing.T) {
+	id := primitive.NewObjectID()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, fuzzSeed(id, now), fuzzSeed(id, now.Add(fuzzWindow-time.Second)))
+	assert.NotEqual(t, fuzzSeed(id, now), fuzzSeed(id, now.Add(fuzzWindow)))
+	assert.NotEqual(t, fuzzSeed(id, now), fuzzSeed(primitive.NewObjectID(), now))
+}
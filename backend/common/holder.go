@@ -0,0 +1,31 @@
+package common
+
+import "sync"
+
+// Holder keeps a dependency a package looks up at call time, such as its repository, store or broker.
+// main and the tests install the implementation with Set during startup, before serving requests or
+// running a test, so packages never depend on how or where data is stored. Get and Set are safe for
+// concurrent use.
+type Holder[T any] struct {
+	mu    sync.RWMutex
+	value T
+}
+
+// NewHolder returns a holder that starts out with value, for dependencies that have a usable default.
+func NewHolder[T any](value T) *Holder[T] {
+	return &Holder[T]{value: value}
+}
+
+// Set replaces the held value.
+func (h *Holder[T]) Set(value T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.value = value
+}
+
+// Get returns the held value, or the zero value before the first Set.
+func (h *Holder[T]) Get() T {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.value
+}
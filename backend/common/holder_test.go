@@ -0,0 +1,36 @@
+package common
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHolderStartsWithItsDefault(t *testing.T) {
+	var empty Holder[*int]
+	assert.Nil(t, empty.Get())
+
+	holder := NewHolder("memory")
+	assert.Equal(t, "memory", holder.Get())
+	holder.Set("mongo")
+	assert.Equal(t, "mongo", holder.Get())
+}
+
+func TestHolderIsSafeForConcurrentUse(t *testing.T) {
+	holder := NewHolder(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			holder.Set(i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			holder.Get()
+		}()
+	}
+	wg.Wait()
+	assert.GreaterOrEqual(t, holder.Get(), 0)
+}
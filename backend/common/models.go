@@ -1,6 +1,10 @@
 package common
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // User struct represents a user in the database
 type User struct {
@@ -8,4 +12,34 @@ type User struct {
 	Username string             `bson:"username,omitempty"`
 	Email    string             `bson:"email,omitempty"`
 	Password string             `bson:"password,omitempty"`
+	IsAdmin  bool               `json:"-" bson:"is_admin,omitempty"`
+	// Suspended accounts cannot sign in or use authenticated routes until SuspendedUntil, or indefinitely when it is zero.
+	Suspended        bool      `json:"-" bson:"suspended,omitempty"`
+	SuspendedUntil   time.Time `json:"-" bson:"suspended_until,omitempty"`
+	SuspensionReason string    `json:"-" bson:"suspension_reason,omitempty"`
+}
+
+// IsSuspended reports whether the account is suspended at now.
+func (u User) IsSuspended(now time.Time) bool {
+	return u.Suspended && (u.SuspendedUntil.IsZero() || now.Before(u.SuspendedUntil))
+}
+
+// Moderation states of posts and comments. Visible items have no state.
+const (
+	// ModStatusFiltered items were held by the spam filter and wait in the moderation queue.
+	ModStatusFiltered = "filtered"
+	// ModStatusRemoved items were removed by a moderator.
+	ModStatusRemoved = "removed"
+)
+
+// ModStatusLabel names a moderation state for metrics, calling the empty state of visible items "visible".
+func ModStatusLabel(status string) string {
+	if status == "" {
+		return "visible"
+	}
+	return status
+}
+
+func (u User) GetID() primitive.ObjectID {
+	return u.ID
 }
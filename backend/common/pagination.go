@@ -45,7 +45,15 @@ func ParsePageRequest(c *gin.Context) (PageRequest, error) {
 	return page, nil
 }
 
-func ApplyCursorPage[T interface{ GetID() primitive.ObjectID }](items []T, limit int64) ([]T, gin.H) {
+// Pagination is returned next to every cursor-paginated list. Pass NextCursor as the after
+// parameter to fetch the following page.
+type Pagination struct {
+	Limit      int64  `json:"limit"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func ApplyCursorPage[T interface{ GetID() primitive.ObjectID }](items []T, limit int64) ([]T, Pagination) {
 	hasMore := int64(len(items)) > limit
 	if hasMore {
 		items = items[:limit]
@@ -56,9 +64,9 @@ func ApplyCursorPage[T interface{ GetID() primitive.ObjectID }](items []T, limit
 		nextCursor = items[len(items)-1].GetID().Hex()
 	}
 
-	return items, gin.H{
-		"limit":       limit,
-		"has_more":    hasMore,
-		"next_cursor": nextCursor,
+	return items, Pagination{
+		Limit:      limit,
+		HasMore:    hasMore,
+		NextCursor: nextCursor,
 	}
 }
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Errors returned by every repository implementation, so handlers never depend on the storage driver.
+var (
+	// ErrNotFound is returned when no stored document matches.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is returned when a write would break a uniqueness constraint.
+	ErrDuplicate = errors.New("duplicate key")
+)
+
+// MongoError translates the driver errors repositories report into ErrNotFound and ErrDuplicate.
+// Other errors are returned unchanged.
+func MongoError(err error) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return ErrDuplicate
+	default:
+		return err
+	}
+}
+
+// AddCondition adds an operator condition on field to a Mongo filter, keeping the conditions already set on it.
+func AddCondition(filter bson.M, field string, operator string, value interface{}) {
+	if conditions, ok := filter[field].(bson.M); ok {
+		conditions[operator] = value
+		return
+	}
+	filter[field] = bson.M{operator: value}
+}
+
+// SumScore adds up the up votes minus the down votes of every document username wrote in a posts or comments collection.
+func SumScore(ctx context.Context, collection *mongo.Collection, username string) (int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"username": username}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "score": bson.M{"$sum": bson.M{"$subtract": bson.A{"$up_votes", "$down_votes"}}}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var totals []struct {
+		Score int `bson:"score"`
+	}
+	if err := cursor.All(ctx, &totals); err != nil || len(totals) == 0 {
+		return 0, err
+	}
+	return totals[0].Score, nil
+}
@@ -8,8 +8,28 @@ type APIMessage struct {
 	Code    string `json:"code"`
 }
 
+// MessageResponse is the data of responses that only confirm what was done.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// UpdatedResponse is the data of responses that changed several documents at once.
+type UpdatedResponse struct {
+	Message string `json:"message"`
+	Updated int64  `json:"updated"`
+}
+
+// Envelope is the body of every JSON response. Data is the handler's payload on success and
+// ErrorData on failure.
+type Envelope[T any] struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+	Data    T      `json:"data"`
+}
+
 // RespondWithJSON is a helper function to send a JSON response
-func RespondWithJSON(c *gin.Context, httpStatus int, code string, payload interface{}) {
+func RespondWithJSON[T any](c *gin.Context, httpStatus int, code string, payload T) {
 	var message string
 	if msg, ok := SuccessMessages[code]; ok {
 		message = msg.Message
@@ -17,10 +37,10 @@ func RespondWithJSON(c *gin.Context, httpStatus int, code string, payload interf
 		message = err.Message
 	}
 
-	c.JSON(httpStatus, gin.H{
-		"status":  httpStatus,
-		"message": message,
-		"code":    code,
-		"data":    payload,
+	c.JSON(httpStatus, Envelope[T]{
+		Status:  httpStatus,
+		Message: message,
+		Code:    code,
+		Data:    payload,
 	})
 }
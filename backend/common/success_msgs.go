@@ -15,16 +15,31 @@ var SuccessMessages = map[string]APIMessage{
 
 // ErrorMessages maps error codes to their messages
 var ErrorMessages = map[string]APIMessage{
-	MONGO_DB_ERROR:             {Message: "Database error", Code: MONGO_DB_ERROR},
-	INVALID_REQUEST_BODY:       {Message: "Invalid request body", Code: INVALID_REQUEST_BODY},
-	INVALID_PARAM:              {Message: "Invalid URL parameter", Code: INVALID_PARAM},
-	UNAUTHORIZED:               {Message: "Unauthorized access", Code: UNAUTHORIZED},
-	EMAIL_ALREADY_EXISTS:       {Message: "Email already exists", Code: EMAIL_ALREADY_EXISTS},
-	USERNAME_ALREADY_EXISTS:   {Message: "Username already exists", Code: USERNAME_ALREADY_EXISTS},
-	USER_NOT_FOUND:             {Message: "User not found", Code: USER_NOT_FOUND},
-	INCORRECT_PASSWORD:         {Message: "Incorrect password", Code: INCORRECT_PASSWORD},
-	COMMUNITY_NOT_FOUND:        {Message: "Community not found", Code: COMMUNITY_NOT_FOUND},
+	MONGO_DB_ERROR:           {Message: "Database error", Code: MONGO_DB_ERROR},
+	INVALID_REQUEST_BODY:     {Message: "Invalid request body", Code: INVALID_REQUEST_BODY},
+	INVALID_PARAM:            {Message: "Invalid URL parameter", Code: INVALID_PARAM},
+	UNAUTHORIZED:             {Message: "Unauthorized access", Code: UNAUTHORIZED},
+	EMAIL_ALREADY_EXISTS:     {Message: "Email already exists", Code: EMAIL_ALREADY_EXISTS},
+	USERNAME_ALREADY_EXISTS:  {Message: "Username already exists", Code: USERNAME_ALREADY_EXISTS},
+	USER_NOT_FOUND:           {Message: "User not found", Code: USER_NOT_FOUND},
+	INCORRECT_PASSWORD:       {Message: "Incorrect password", Code: INCORRECT_PASSWORD},
+	COMMUNITY_NOT_FOUND:      {Message: "Community not found", Code: COMMUNITY_NOT_FOUND},
 	COMMUNITY_ALREADY_EXISTS: {Message: "Community with this name already exists", Code: COMMUNITY_ALREADY_EXISTS},
-	POST_NOT_FOUND:             {Message: "Post not found", Code: POST_NOT_FOUND},
-	COMMENT_NOT_FOUND:          {Message: "Comment not found", Code: COMMENT_NOT_FOUND},
+	POST_NOT_FOUND:           {Message: "Post not found", Code: POST_NOT_FOUND},
+	COMMENT_NOT_FOUND:        {Message: "Comment not found", Code: COMMENT_NOT_FOUND},
+	NOTIFICATION_NOT_FOUND:   {Message: "Notification not found", Code: NOTIFICATION_NOT_FOUND},
+	CONVERSATION_NOT_FOUND:   {Message: "Conversation not found", Code: CONVERSATION_NOT_FOUND},
+	USER_BLOCKED:             {Message: "You have been blocked by this user", Code: USER_BLOCKED},
+	INVALID_FLAIR:            {Message: "Flair is not available in this community", Code: INVALID_FLAIR},
+	JOIN_REQUEST_NOT_FOUND:   {Message: "Join request not found", Code: JOIN_REQUEST_NOT_FOUND},
+	COMMUNITY_ACCESS_DENIED:  {Message: "You do not have access to this community", Code: COMMUNITY_ACCESS_DENIED},
+	PIN_LIMIT_REACHED:        {Message: "This community already has the maximum number of pinned posts", Code: PIN_LIMIT_REACHED},
+	POST_LOCKED:              {Message: "This post is locked", Code: POST_LOCKED},
+	FOLLOW_LIMIT_REACHED:     {Message: "You are following too many users", Code: FOLLOW_LIMIT_REACHED},
+	TOO_MANY_REQUESTS:        {Message: "Too many requests", Code: TOO_MANY_REQUESTS},
+	ROUTE_NOT_FOUND:          {Message: "Route not found", Code: ROUTE_NOT_FOUND},
+	INTERNAL_ERROR:           {Message: "Internal server error", Code: INTERNAL_ERROR},
+	SERVICE_UNAVAILABLE:      {Message: "Service unavailable", Code: SERVICE_UNAVAILABLE},
+	ACCOUNT_SUSPENDED:        {Message: "This account is suspended", Code: ACCOUNT_SUSPENDED},
+	VOTE_NOT_FOUND:           {Message: "Vote not found", Code: VOTE_NOT_FOUND},
 }
@@ -0,0 +1,100 @@
+package communities
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/gin-gonic/gin"
+)
+
+// maxRulesDocument bounds the size of an uploaded rules document.
+const maxRulesDocument = 256 << 10
+
+// GetAutomodRules returns the community's AutoModerator rules to its moderators.
+func GetAutomodRules(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	rules := community.AutomodRules
+	if rules == nil {
+		rules = []automod.Rule{}
+	}
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, AutomodRulesResponse{Rules: rules})
+}
+
+// UpdateAutomodRules replaces the community's AutoModerator rules with a YAML or JSON document of the form {rules: [...]}.
+func UpdateAutomodRules(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRulesDocument+1))
+	if err != nil || len(body) > maxRulesDocument {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_REQUEST_BODY, "Rules document is too large"))
+		return
+	}
+
+	rules, err := automod.Parse(body)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_REQUEST_BODY, err.Error()))
+		return
+	}
+
+	update := CommunityUpdate{AutomodRules: &rules, UpdationDate: time.Now()}
+	if _, err := repo().Update(c.Request.Context(), community.ID, update); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error updating automod rules", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update rules"))
+		return
+	}
+	recordCommunityAction(c, community, modlog.ActionEditAutomodRules, "", fmt.Sprintf("%d rules", len(rules)))
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, AutomodRulesResponse{Message: "Rules updated successfully", Rules: rules})
+}
+
+// DryRunAutomod shows which rules would fire for a sample item, without changing anything.
+func DryRunAutomod(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	var req AutomodDryRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+
+	rules := community.AutomodRules
+	if req.Rules != nil {
+		if err := automod.Validate(*req.Rules); err != nil {
+			common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_REQUEST_BODY, err.Error()))
+			return
+		}
+		rules = *req.Rules
+	}
+
+	var author automod.Author = automod.StaticAuthor{}
+	if req.Author != nil {
+		author = *req.Author
+	} else if req.Username != "" {
+		author = automod.NewAuthor(req.Username)
+	}
+
+	result, err := automod.Evaluate(c.Request.Context(), rules, req.Item, author)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_REQUEST_BODY, err.Error()))
+		return
+	}
+
+	status, reasons := result.Status(nil)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, AutomodDryRunResponse{Result: result, ModStatus: status, Reasons: reasons})
+}
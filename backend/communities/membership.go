@@ -0,0 +1,168 @@
+package communities
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RequestToJoin asks the moderators of a restricted or private community to approve the caller.
+func RequestToJoin(c *gin.Context) {
+	community, err := FindByName(c.Request.Context(), c.Param("communityName"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.COMMUNITY_NOT_FOUND, "Community not found"))
+		return
+	}
+
+	var req JoinCommunityRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	if community.Allows(username, AccessWrite) {
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "You can already post in this community"})
+		return
+	}
+
+	request := JoinRequest{
+		ID:           primitive.NewObjectID(),
+		CommunityID:  community.ID,
+		Username:     username,
+		Message:      req.Message,
+		CreationDate: time.Now(),
+	}
+	if err := repo().SaveJoinRequest(c.Request.Context(), request); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error saving join request", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to request to join"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusAccepted, common.SUCCESS, common.MessageResponse{Message: "Join request sent to the moderators"})
+}
+
+// GetJoinRequests retrieves a bounded page of pending join requests, oldest first.
+func GetJoinRequests(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	results, err := repo().ListJoinRequests(c.Request.Context(), community.ID, page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding join requests", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve join requests"))
+		return
+	}
+
+	requests, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, JoinRequestListResponse{JoinRequests: requests, Pagination: pagination})
+}
+
+// ApproveUser lets a user into the community, either answering their join request or as a direct invite.
+func ApproveUser(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	username := c.Param("username")
+	exists, err := users.Exists(c.Request.Context(), username)
+	if err != nil || !exists {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.USER_NOT_FOUND, "User not found"))
+		return
+	}
+
+	if err := repo().AddApprovedUser(c.Request.Context(), community.ID, username); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error approving user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to approve user"))
+		return
+	}
+	_ = repo().DeleteJoinRequest(c.Request.Context(), community.ID, username)
+	recordCommunityAction(c, community, modlog.ActionApproveUser, username, "")
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "User approved successfully"})
+}
+
+// DenyJoinRequest discards a pending join request.
+func DenyJoinRequest(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	err := repo().DeleteJoinRequest(c.Request.Context(), community.ID, c.Param("username"))
+	if errors.Is(err, common.ErrNotFound) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.JOIN_REQUEST_NOT_FOUND, "Join request not found"))
+		return
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error deleting join request", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to deny join request"))
+		return
+	}
+	recordCommunityAction(c, community, modlog.ActionDenyJoinRequest, c.Param("username"), "")
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Join request denied"})
+}
+
+// GetApprovedUsers lists the users approved into the community. Only moderators may see the roster.
+func GetApprovedUsers(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	approved := community.ApprovedUsers
+	if approved == nil {
+		approved = []string{}
+	}
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, ApprovedUsersResponse{ApprovedUsers: approved})
+}
+
+// RemoveApprovedUser revokes a user's approval.
+func RemoveApprovedUser(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	if err := repo().RemoveApprovedUser(c.Request.Context(), community.ID, c.Param("username")); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error removing approved user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to remove approved user"))
+		return
+	}
+	recordCommunityAction(c, community, modlog.ActionRemoveApprovedUser, c.Param("username"), "")
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "User removed from approved users"})
+}
+
+// FindModeratedCommunity loads the community named in the URL and checks that the caller moderates it.
+// When it returns false the error response has already been written.
+func FindModeratedCommunity(c *gin.Context) (Community, bool) {
+	community, err := FindByName(c.Request.Context(), c.Param("communityName"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.COMMUNITY_NOT_FOUND, "Community not found"))
+		return Community{}, false
+	}
+	if !community.IsModerator(c.GetString("username")) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Only moderators can manage this community"))
+		return Community{}, false
+	}
+	return community, true
+}
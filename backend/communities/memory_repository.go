@@ -0,0 +1,144 @@
+package communities
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRepository struct {
+	communities  *memstore.Table[Community]
+	joinRequests *memstore.Table[JoinRequest]
+}
+
+// NewMemoryRepository keeps communities in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		communities:  memstore.NewTable[Community](),
+		joinRequests: memstore.NewTable[JoinRequest](),
+	}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, community Community) error {
+	return r.communities.Insert(community, named(community.Name))
+}
+
+func (r *memoryRepository) List(ctx context.Context) ([]Community, error) {
+	return r.communities.Find(nil), nil
+}
+
+func (r *memoryRepository) FindByName(ctx context.Context, name string) (Community, error) {
+	return r.communities.First(named(name))
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Community, error) {
+	return r.communities.Get(id)
+}
+
+func (r *memoryRepository) HiddenIDs(ctx context.Context, viewer string) ([]primitive.ObjectID, error) {
+	ids := []primitive.ObjectID{}
+	for _, community := range r.communities.Find(func(community Community) bool {
+		return community.Type == TypePrivate && (viewer == "" || !community.IsModerator(viewer) && !community.IsApproved(viewer))
+	}) {
+		ids = append(ids, community.ID)
+	}
+	return ids, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, id primitive.ObjectID, update CommunityUpdate) (Community, error) {
+	return r.communities.UpdateOne(withID(id), func(community *Community) {
+		community.UpdationDate = update.UpdationDate
+		if update.Description != nil {
+			community.Description = *update.Description
+		}
+		if update.Rules != nil {
+			community.Rules = *update.Rules
+		}
+		if update.Type != nil {
+			community.Type = *update.Type
+		}
+		if update.Sidebar != nil {
+			community.Sidebar = *update.Sidebar
+		}
+		if update.BannerURL != nil {
+			community.BannerURL = *update.BannerURL
+		}
+		if update.IconURL != nil {
+			community.IconURL = *update.IconURL
+		}
+		if update.Flairs != nil {
+			community.Flairs = *update.Flairs
+		}
+		if update.SpamFilter != nil {
+			community.SpamFilter = *update.SpamFilter
+		}
+		if update.AutomodRules != nil {
+			community.AutomodRules = *update.AutomodRules
+		}
+	})
+}
+
+func (r *memoryRepository) AddApprovedUser(ctx context.Context, id primitive.ObjectID, username string) error {
+	r.communities.Update(withID(id), func(community *Community) {
+		if !community.IsApproved(username) {
+			community.ApprovedUsers = append(append([]string{}, community.ApprovedUsers...), username)
+		}
+		community.UpdationDate = time.Now()
+	})
+	return nil
+}
+
+func (r *memoryRepository) RemoveApprovedUser(ctx context.Context, id primitive.ObjectID, username string) error {
+	r.communities.Update(withID(id), func(community *Community) {
+		approved := []string{}
+		for _, user := range community.ApprovedUsers {
+			if user != username {
+				approved = append(approved, user)
+			}
+		}
+		community.ApprovedUsers = approved
+		community.UpdationDate = time.Now()
+	})
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.communities.Delete(withID(id))
+	return nil
+}
+
+func (r *memoryRepository) SaveJoinRequest(ctx context.Context, request JoinRequest) error {
+	r.joinRequests.Upsert(requestOf(request.CommunityID, request.Username), func() JoinRequest { return request }, func(stored *JoinRequest) {
+		stored.Message = request.Message
+	})
+	return nil
+}
+
+func (r *memoryRepository) ListJoinRequests(ctx context.Context, communityID primitive.ObjectID, page common.PageRequest) ([]JoinRequest, error) {
+	rows := r.joinRequests.Find(func(request JoinRequest) bool { return request.CommunityID == communityID })
+	return memstore.Page(rows, page, false), nil
+}
+
+func (r *memoryRepository) DeleteJoinRequest(ctx context.Context, communityID primitive.ObjectID, username string) error {
+	if len(r.joinRequests.Delete(requestOf(communityID, username))) == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func named(name string) func(Community) bool {
+	return func(community Community) bool { return community.Name == name }
+}
+
+func withID(id primitive.ObjectID) func(Community) bool {
+	return func(community Community) bool { return community.ID == id }
+}
+
+func requestOf(communityID primitive.ObjectID, username string) func(JoinRequest) bool {
+	return func(request JoinRequest) bool {
+		return request.CommunityID == communityID && request.Username == username
+	}
+}
@@ -3,21 +3,204 @@ package communities
 import (
 	"time"
 
-	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/spam"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var CommunityCollection *mongo.Collection = configs.GetCollection("communities")
+const (
+	TypePublic     = "public"
+	TypeRestricted = "restricted"
+	TypePrivate    = "private"
+)
+
+// Access is what a user wants to do with a community's content.
+type Access int
+
+const (
+	AccessRead Access = iota
+	AccessWrite
+)
 
 // Community struct
 type Community struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty"`
-	Name         string             `bson:"name,omitempty"`
-	Description  string             `bson:"description,omitempty"`
-	CreationDate time.Time          `bson:"creation_date,omitempty"`
-	UpdationDate time.Time          `bson:"updation_date,omitempty"`
-	MembersCount int                `bson:"members_count,omitempty"`
-	PostsCount   int                `bson:"posts_count,omitempty"`
-	Creator      primitive.ObjectID `bson:"creator,omitempty"`
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name         string             `json:"name" bson:"name,omitempty"`
+	Description  string             `json:"description" bson:"description,omitempty"`
+	CreationDate time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
+	UpdationDate time.Time          `json:"updation_date" bson:"updation_date,omitempty"`
+	MembersCount int                `json:"members_count" bson:"members_count,omitempty"`
+	PostsCount   int                `json:"posts_count" bson:"posts_count,omitempty"`
+	Creator      primitive.ObjectID `json:"creator" bson:"creator,omitempty"`
+	Moderators   []string           `json:"moderators" bson:"moderators,omitempty"`
+	Rules        []Rule             `json:"rules" bson:"rules,omitempty"`
+	Sidebar      string             `json:"sidebar" bson:"sidebar,omitempty"`
+	BannerURL    string             `json:"banner_url" bson:"banner_url,omitempty"`
+	IconURL      string             `json:"icon_url" bson:"icon_url,omitempty"`
+	Flairs       []Flair            `json:"flairs" bson:"flairs,omitempty"`
+	// Type is one of TypePublic, TypeRestricted or TypePrivate. Communities created before types existed have none and are public.
+	Type string `json:"type" bson:"type,omitempty"`
+	// ApprovedUsers is the member roster of restricted and private communities; only moderators may list it.
+	ApprovedUsers []string `json:"-" bson:"approved_users,omitempty"`
+	// SpamFilter is only shown to moderators, so spammers cannot read what to avoid.
+	SpamFilter spam.Settings `json:"-" bson:"spam_filter,omitempty"`
+	// AutomodRules run on every new or edited post and comment. Like SpamFilter they are only shown to moderators.
+	AutomodRules []automod.Rule `json:"-" bson:"automod_rules,omitempty"`
+}
+
+func (c Community) GetID() primitive.ObjectID {
+	return c.ID
+}
+
+// IsModerator reports whether username may change the community's settings. The creator is the first moderator.
+func (c Community) IsModerator(username string) bool {
+	for _, moderator := range c.Moderators {
+		if moderator == username {
+			return true
+		}
+	}
+	return false
+}
+
+// IsApproved reports whether username was let into a restricted or private community.
+func (c Community) IsApproved(username string) bool {
+	for _, approved := range c.ApprovedUsers {
+		if approved == username {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublic reports whether anyone may read and post in the community.
+func (c Community) IsPublic() bool {
+	return c.Type == "" || c.Type == TypePublic
+}
+
+// Allows reports whether username (empty for anonymous readers) has the given access.
+// Anyone may read public and restricted communities; only moderators and approved users may read private ones
+// or post, comment and vote outside public ones.
+func (c Community) Allows(username string, access Access) bool {
+	if c.IsPublic() || (c.Type == TypeRestricted && access == AccessRead) {
+		return access == AccessRead || username != ""
+	}
+	return username != "" && (c.IsModerator(username) || c.IsApproved(username))
+}
+
+// HasFlair reports whether text is one of the community's configured post flairs.
+func (c Community) HasFlair(text string) bool {
+	for _, flair := range c.Flairs {
+		if flair.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule is one entry of a community's numbered rule list. Number is assigned from the rule's position.
+type Rule struct {
+	Number      int    `json:"number" bson:"number"`
+	Title       string `json:"title" bson:"title" binding:"required,min=1,max=100"`
+	Description string `json:"description" bson:"description,omitempty" binding:"max=500"`
+}
+
+// Flair is a label moderators allow authors to attach to posts.
+type Flair struct {
+	Text  string `json:"text" bson:"text" binding:"required,min=1,max=64"`
+	Color string `json:"color" bson:"color,omitempty" binding:"omitempty,hexcolor"`
+}
+
+// JoinRequest asks the moderators of a restricted or private community to approve Username.
+type JoinRequest struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	CommunityID  primitive.ObjectID `json:"community_id" bson:"community_id,omitempty"`
+	Username     string             `json:"username" bson:"username,omitempty"`
+	Message      string             `json:"message" bson:"message,omitempty"`
+	CreationDate time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
+}
+
+func (r JoinRequest) GetID() primitive.ObjectID {
+	return r.ID
+}
+
+type JoinCommunityRequest struct {
+	Message string `json:"message" binding:"max=500"`
+}
+
+// CreateCommunityRequest holds what a user may choose when creating a community. Type defaults to public.
+type CreateCommunityRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description string  `json:"description" binding:"max=500"`
+	Rules       []Rule  `json:"rules" binding:"max=15,dive"`
+	Sidebar     string  `json:"sidebar" binding:"max=10000"`
+	BannerURL   string  `json:"banner_url" binding:"max=2048"`
+	IconURL     string  `json:"icon_url" binding:"max=2048"`
+	Flairs      []Flair `json:"flairs" binding:"max=30,dive"`
+	Type        string  `json:"type" binding:"omitempty,oneof=public restricted private"`
+}
+
+// UpdateCommunityRequest changes only the fields that are present; an empty list or string clears the field.
+type UpdateCommunityRequest struct {
+	Description *string        `json:"description" binding:"omitempty,max=500"`
+	Rules       *[]Rule        `json:"rules" binding:"omitempty,max=15,dive"`
+	Sidebar     *string        `json:"sidebar" binding:"omitempty,max=10000"`
+	BannerURL   *string        `json:"banner_url" binding:"omitempty,max=2048"`
+	IconURL     *string        `json:"icon_url" binding:"omitempty,max=2048"`
+	Flairs      *[]Flair       `json:"flairs" binding:"omitempty,max=30,dive"`
+	Type        *string        `json:"type" binding:"omitempty,oneof=public restricted private"`
+	SpamFilter  *spam.Settings `json:"spam_filter"`
+}
+
+// AutomodDryRunRequest evaluates Rules, or the community's saved rules when Rules is absent, against a sample item.
+// Author facts come from Author when given, otherwise from the registered user Username.
+type AutomodDryRunRequest struct {
+	Rules    *[]automod.Rule       `json:"rules"`
+	Item     automod.Item          `json:"item" binding:"required"`
+	Username string                `json:"username"`
+	Author   *automod.StaticAuthor `json:"author"`
+}
+
+// CommunityResponse is the data of responses about one community. Message is only set when the
+// community was just created or updated, and SpamFilter only for its moderators.
+type CommunityResponse struct {
+	Message    string         `json:"message,omitempty"`
+	Community  Community      `json:"community"`
+	SpamFilter *spam.Settings `json:"spam_filter,omitempty"`
+}
+
+// CommunityListResponse is the data of the community listing.
+type CommunityListResponse struct {
+	Communities []Community `json:"communities"`
+}
+
+// JoinRequestListResponse is the data of a community's pending join requests.
+type JoinRequestListResponse struct {
+	JoinRequests []JoinRequest     `json:"join_requests"`
+	Pagination   common.Pagination `json:"pagination"`
+}
+
+// ApprovedUsersResponse is the data of a community's roster of approved users.
+type ApprovedUsersResponse struct {
+	ApprovedUsers []string `json:"approved_users"`
+}
+
+// ModlogResponse is the data of a moderation log listing.
+type ModlogResponse struct {
+	Entries    []modlog.Entry    `json:"entries"`
+	Pagination common.Pagination `json:"pagination"`
+}
+
+// AutomodRulesResponse is the data of a community's AutoModerator rules. Message is only set when they were just replaced.
+type AutomodRulesResponse struct {
+	Message string         `json:"message,omitempty"`
+	Rules   []automod.Rule `json:"rules"`
+}
+
+// AutomodDryRunResponse is the data of a dry run: the rules that fired and the state the item would get.
+type AutomodDryRunResponse struct {
+	Result    automod.Result `json:"result"`
+	ModStatus string         `json:"mod_status"`
+	Reasons   []string       `json:"reasons"`
 }
@@ -0,0 +1,67 @@
+package communities
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/gin-gonic/gin"
+)
+
+// GetModlog retrieves a bounded page of the community's moderation log, newest first, for its moderators.
+// It can be narrowed with ?moderator=, ?action= and an RFC 3339 ?from= / ?to= range.
+func GetModlog(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+	listModlog(c, modlog.Query{CommunityID: community.ID})
+}
+
+// GetAdminModlog retrieves the moderation log of the community named by ?community= for administrators.
+// It matches entries by name, so the log of a deleted community, including its delete_community entry,
+// stays readable; entries of an earlier community with the same name are told apart by community_id.
+// It takes the same filters as GetModlog.
+func GetAdminModlog(c *gin.Context) {
+	name := c.Query("community")
+	if name == "" {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "community is required"))
+		return
+	}
+	listModlog(c, modlog.Query{CommunityName: name})
+}
+
+func listModlog(c *gin.Context, query modlog.Query) {
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	query.Moderator = c.Query("moderator")
+	query.Action = c.Query("action")
+	for param, bound := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, param+" must be an RFC 3339 timestamp"))
+			return
+		}
+		*bound = date
+	}
+
+	results, err := modlog.List(c.Request.Context(), query, page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding modlog entries", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve moderation log"))
+		return
+	}
+
+	entries, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, ModlogResponse{Entries: entries, Pagination: pagination})
+}
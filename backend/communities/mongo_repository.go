@@ -0,0 +1,177 @@
+package communities
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	communities  *mongo.Collection
+	joinRequests *mongo.Collection
+}
+
+// NewMongoRepository stores communities in the communities and community_join_requests collections of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{
+		communities:  db.Collection("communities"),
+		joinRequests: db.Collection("community_join_requests"),
+	}
+}
+
+func (r *mongoRepository) Create(ctx context.Context, community Community) error {
+	_, err := r.communities.InsertOne(ctx, community)
+	return common.MongoError(err)
+}
+
+func (r *mongoRepository) List(ctx context.Context) ([]Community, error) {
+	cursor, err := r.communities.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var communities []Community
+	err = cursor.All(ctx, &communities)
+	return communities, err
+}
+
+func (r *mongoRepository) FindByName(ctx context.Context, name string) (Community, error) {
+	return r.findOne(ctx, bson.M{"name": name})
+}
+
+func (r *mongoRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Community, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoRepository) HiddenIDs(ctx context.Context, viewer string) ([]primitive.ObjectID, error) {
+	filter := bson.M{"type": TypePrivate}
+	if viewer != "" {
+		filter["moderators"] = bson.M{"$ne": viewer}
+		filter["approved_users"] = bson.M{"$ne": viewer}
+	}
+
+	values, err := r.communities.Distinct(ctx, "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *mongoRepository) Update(ctx context.Context, id primitive.ObjectID, update CommunityUpdate) (Community, error) {
+	set := bson.M{"updation_date": update.UpdationDate}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.Rules != nil {
+		set["rules"] = *update.Rules
+	}
+	if update.Type != nil {
+		set["type"] = *update.Type
+	}
+	if update.Sidebar != nil {
+		set["sidebar"] = *update.Sidebar
+	}
+	if update.BannerURL != nil {
+		set["banner_url"] = *update.BannerURL
+	}
+	if update.IconURL != nil {
+		set["icon_url"] = *update.IconURL
+	}
+	if update.Flairs != nil {
+		set["flairs"] = *update.Flairs
+	}
+	if update.SpamFilter != nil {
+		set["spam_filter"] = *update.SpamFilter
+	}
+	if update.AutomodRules != nil {
+		set["automod_rules"] = *update.AutomodRules
+	}
+
+	var updated Community
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.communities.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set}, opts).Decode(&updated)
+	return updated, common.MongoError(err)
+}
+
+func (r *mongoRepository) AddApprovedUser(ctx context.Context, id primitive.ObjectID, username string) error {
+	update := bson.M{"$addToSet": bson.M{"approved_users": username}, "$set": bson.M{"updation_date": time.Now()}}
+	_, err := r.communities.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+func (r *mongoRepository) RemoveApprovedUser(ctx context.Context, id primitive.ObjectID, username string) error {
+	update := bson.M{"$pull": bson.M{"approved_users": username}, "$set": bson.M{"updation_date": time.Now()}}
+	_, err := r.communities.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+func (r *mongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.communities.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *mongoRepository) SaveJoinRequest(ctx context.Context, request JoinRequest) error {
+	filter := bson.M{"community_id": request.CommunityID, "username": request.Username}
+	update := bson.M{
+		"$set": bson.M{"message": request.Message},
+		"$setOnInsert": bson.M{
+			"_id":           request.ID,
+			"community_id":  request.CommunityID,
+			"username":      request.Username,
+			"creation_date": request.CreationDate,
+		},
+	}
+	_, err := r.joinRequests.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoRepository) ListJoinRequests(ctx context.Context, communityID primitive.ObjectID, page common.PageRequest) ([]JoinRequest, error) {
+	filter := bson.M{"community_id": communityID}
+	if page.HasAfter {
+		filter["_id"] = bson.M{"$gt": page.AfterID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.joinRequests.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []JoinRequest
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) DeleteJoinRequest(ctx context.Context, communityID primitive.ObjectID, username string) error {
+	result, err := r.joinRequests.DeleteOne(ctx, bson.M{"community_id": communityID, "username": username})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoRepository) findOne(ctx context.Context, filter bson.M) (Community, error) {
+	var community Community
+	err := r.communities.FindOne(ctx, filter).Decode(&community)
+	return community, common.MongoError(err)
+}
@@ -0,0 +1,82 @@
+package communities
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/spam"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommunityUpdate changes only the fields that are set. UpdationDate is always stored.
+type CommunityUpdate struct {
+	Description  *string
+	Rules        *[]Rule
+	Type         *string
+	Sidebar      *string
+	BannerURL    *string
+	IconURL      *string
+	Flairs       *[]Flair
+	SpamFilter   *spam.Settings
+	AutomodRules *[]automod.Rule
+	UpdationDate time.Time
+}
+
+// Fields names the stored fields the update changes, sorted, for the moderation log.
+func (u CommunityUpdate) Fields() []string {
+	fields := []string{}
+	for name, set := range map[string]bool{
+		"description":   u.Description != nil,
+		"rules":         u.Rules != nil,
+		"type":          u.Type != nil,
+		"sidebar":       u.Sidebar != nil,
+		"banner_url":    u.BannerURL != nil,
+		"icon_url":      u.IconURL != nil,
+		"flairs":        u.Flairs != nil,
+		"spam_filter":   u.SpamFilter != nil,
+		"automod_rules": u.AutomodRules != nil,
+	} {
+		if set {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Repository stores communities and their join requests. Lookups that find nothing return common.ErrNotFound.
+type Repository interface {
+	// Create stores a new community, or returns common.ErrDuplicate when its name is taken.
+	Create(ctx context.Context, community Community) error
+	List(ctx context.Context) ([]Community, error)
+	FindByName(ctx context.Context, name string) (Community, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (Community, error)
+	// HiddenIDs lists the private communities viewer (empty for anonymous readers) is neither a moderator nor an approved user of.
+	HiddenIDs(ctx context.Context, viewer string) ([]primitive.ObjectID, error)
+	// Update applies update and returns the updated community.
+	Update(ctx context.Context, id primitive.ObjectID, update CommunityUpdate) (Community, error)
+	AddApprovedUser(ctx context.Context, id primitive.ObjectID, username string) error
+	RemoveApprovedUser(ctx context.Context, id primitive.ObjectID, username string) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// SaveJoinRequest stores request, or replaces the message of the user's pending request to the same community.
+	SaveJoinRequest(ctx context.Context, request JoinRequest) error
+	// ListJoinRequests returns the pending requests of a community, oldest first, reading up to page.Limit+1 of them.
+	ListJoinRequests(ctx context.Context, communityID primitive.ObjectID, page common.PageRequest) ([]JoinRequest, error)
+	// DeleteJoinRequest removes the pending request of username, or returns common.ErrNotFound.
+	DeleteJoinRequest(ctx context.Context, communityID primitive.ObjectID, username string) error
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+func repo() Repository {
+	return repository.Get()
+}
@@ -2,70 +2,262 @@ package communities
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/users"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func CreateCommunity(c *gin.Context) {
-	var community Community
-	if err := c.ShouldBindJSON(&community); err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": err.Error()})
+	var req CreateCommunityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+	if apiErr := validateImagesAndFlairs(req.BannerURL, req.IconURL, req.Flairs); apiErr != nil {
+		common.RespondWithError(c, apiErr)
 		return
 	}
 
 	// Check if community with the same name already exists
-	count, err := CommunityCollection.CountDocuments(context.TODO(), bson.M{"name": community.Name})
-	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Error checking for existing community"})
+	_, err := repo().FindByName(c.Request.Context(), req.Name)
+	if err == nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusConflict, common.COMMUNITY_ALREADY_EXISTS, "Community with this name already exists"))
+		return
+	}
+	if !errors.Is(err, common.ErrNotFound) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Error checking for existing community"))
 		return
 	}
-	if count > 0 {
-		common.RespondWithJSON(c, http.StatusConflict, common.COMMUNITY_ALREADY_EXISTS, gin.H{"error": "Community with this name already exists"})
+
+	creator, err := users.Repo().FindByUsername(c.Request.Context(), c.GetString("username"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.USER_NOT_FOUND, "User not found"))
 		return
 	}
 
-	community.ID = primitive.NewObjectID()
-	community.CreationDate = time.Now()
-	community.UpdationDate = time.Now()
+	community := Community{
+		ID:           primitive.NewObjectID(),
+		Name:         req.Name,
+		Description:  req.Description,
+		CreationDate: time.Now(),
+		UpdationDate: time.Now(),
+		Creator:      creator.ID,
+		Moderators:   []string{creator.Username},
+		Rules:        numberRules(req.Rules),
+		Sidebar:      req.Sidebar,
+		BannerURL:    req.BannerURL,
+		IconURL:      req.IconURL,
+		Flairs:       req.Flairs,
+		Type:         req.Type,
+	}
+	if community.Type == "" {
+		community.Type = TypePublic
+	}
 
-	_, err = CommunityCollection.InsertOne(context.TODO(), community)
+	err = repo().Create(c.Request.Context(), community)
+	if errors.Is(err, common.ErrDuplicate) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusConflict, common.COMMUNITY_ALREADY_EXISTS, "Community with this name already exists"))
+		return
+	}
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to create community"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to create community"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, gin.H{"message": "Community created successfully", "community": community})
+	common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, CommunityResponse{Message: "Community created successfully", Community: community})
 }
 
 func GetAllCommunities(c *gin.Context) {
-	var communities []Community
-	cursor, err := CommunityCollection.Find(context.TODO(), bson.M{})
+	communities, err := repo().List(c.Request.Context())
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to retrieve communities"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve communities"))
 		return
 	}
 
-	if err = cursor.All(context.TODO(), &communities); err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to decode communities"})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, CommunityListResponse{Communities: communities})
+}
+
+// FindByName loads a community by its unique name.
+func FindByName(ctx context.Context, name string) (Community, error) {
+	return repo().FindByName(ctx, name)
+}
+
+// FindByID loads a community by its ID.
+func FindByID(ctx context.Context, id primitive.ObjectID) (Community, error) {
+	return repo().FindByID(ctx, id)
+}
+
+// HiddenCommunityIDs lists the private communities viewer cannot read, so listings can exclude them in the query.
+func HiddenCommunityIDs(ctx context.Context, viewer string) ([]primitive.ObjectID, error) {
+	return repo().HiddenIDs(ctx, viewer)
+}
+
+func GetCommunityByName(c *gin.Context) {
+	community, err := FindByName(c.Request.Context(), c.Param("communityName"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.COMMUNITY_NOT_FOUND, "Community not found"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"communities": communities})
+	response := CommunityResponse{Community: community}
+	if community.IsModerator(c.GetString("username")) {
+		response.SpamFilter = &community.SpamFilter
+	}
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, response)
 }
 
-func DeleteCommunityByName(c *gin.Context) {
-	communityName := c.Param("communityName")
+// UpdateCommunity lets moderators change the description, rules, sidebar, images, post flairs and spam filter.
+func UpdateCommunity(c *gin.Context) {
+	community, ok := FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateCommunityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+
+	update := CommunityUpdate{
+		Description:  req.Description,
+		Type:         req.Type,
+		Sidebar:      req.Sidebar,
+		BannerURL:    req.BannerURL,
+		IconURL:      req.IconURL,
+		UpdationDate: time.Now(),
+	}
+	if req.Rules != nil {
+		rules := numberRules(*req.Rules)
+		update.Rules = &rules
+	}
+	var bannerURL, iconURL string
+	var flairs []Flair
+	if req.BannerURL != nil {
+		bannerURL = *req.BannerURL
+	}
+	if req.IconURL != nil {
+		iconURL = *req.IconURL
+	}
+	if req.Flairs != nil {
+		flairs = *req.Flairs
+		update.Flairs = req.Flairs
+	}
+	if apiErr := validateImagesAndFlairs(bannerURL, iconURL, flairs); apiErr != nil {
+		common.RespondWithError(c, apiErr)
+		return
+	}
+	if req.SpamFilter != nil {
+		for i, pattern := range req.SpamFilter.BannedPatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				common.RespondWithError(c, common.InvalidField(fmt.Sprintf("spam_filter.banned_patterns[%d]", i), "is not a valid regular expression"))
+				return
+			}
+		}
+		for i, domain := range req.SpamFilter.BlockedDomains {
+			req.SpamFilter.BlockedDomains[i] = strings.ToLower(strings.TrimSpace(domain))
+		}
+		req.SpamFilter.Compile()
+		update.SpamFilter = req.SpamFilter
+	}
+
+	updated, err := repo().Update(c.Request.Context(), community.ID, update)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update community"))
+		return
+	}
+
+	recordCommunityAction(c, community, modlog.ActionEditSettings, "", "changed "+strings.Join(update.Fields(), ", "))
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, CommunityResponse{Message: "Community updated successfully", Community: updated, SpamFilter: &updated.SpamFilter})
+}
+
+// validateImagesAndFlairs checks what binding tags cannot: that images are http(s) URLs and that flairs are distinct.
+func validateImagesAndFlairs(bannerURL string, iconURL string, flairs []Flair) *common.APIError {
+	for field, value := range map[string]string{"banner_url": bannerURL, "icon_url": iconURL} {
+		if value != "" && !isHTTPURL(value) {
+			return common.InvalidField(field, "must be an http(s) URL")
+		}
+	}
+	seen := map[string]bool{}
+	for i, flair := range flairs {
+		if seen[flair.Text] {
+			return common.InvalidField(fmt.Sprintf("flairs[%d].text", i), "duplicates another flair")
+		}
+		seen[flair.Text] = true
+	}
+	return nil
+}
+
+func isHTTPURL(value string) bool {
+	parsed, err := url.ParseRequestURI(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func numberRules(rules []Rule) []Rule {
+	for i := range rules {
+		rules[i].Number = i + 1
+	}
+	return rules
+}
 
-	_, err := CommunityCollection.DeleteOne(context.TODO(), bson.M{"name": communityName})
+// DeleteCommunityByName deletes a community. Only its moderators and site administrators may do so.
+func DeleteCommunityByName(c *gin.Context) {
+	community, err := FindByName(c.Request.Context(), c.Param("communityName"))
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to delete community"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.COMMUNITY_NOT_FOUND, "Community not found"))
+		return
+	}
+
+	username := c.GetString("username")
+	isModerator := community.IsModerator(username)
+	admin := !isModerator && users.IsAdmin(c.Request.Context(), username)
+	if !isModerator && !admin {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Only moderators can delete this community"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Community deleted successfully"})
+	if err := repo().Delete(c.Request.Context(), community.ID); err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to delete community"))
+		return
+	}
+
+	modlog.Record(c.Request.Context(), modlog.Entry{
+		CommunityID:   community.ID,
+		CommunityName: community.Name,
+		Moderator:     username,
+		Admin:         admin,
+		Action:        modlog.ActionDeleteCommunity,
+		TargetType:    modlog.TargetCommunity,
+		TargetID:      community.ID,
+	})
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Community deleted successfully"})
+}
+
+// recordCommunityAction writes a moderation log entry for an action a moderator took on the community or one of its users.
+func recordCommunityAction(c *gin.Context, community Community, action string, targetUser string, details string) {
+	entry := modlog.Entry{
+		CommunityID:   community.ID,
+		CommunityName: community.Name,
+		Moderator:     c.GetString("username"),
+		Action:        action,
+		TargetType:    modlog.TargetCommunity,
+		TargetID:      community.ID,
+		Details:       details,
+	}
+	if targetUser != "" {
+		entry.TargetType, entry.TargetID, entry.TargetUser = modlog.TargetUser, primitive.NilObjectID, targetUser
+	}
+	modlog.Record(c.Request.Context(), entry)
 }
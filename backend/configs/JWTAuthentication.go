@@ -7,10 +7,11 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// devJWTSecret signs tokens in development when SECRET_KEY is unset. Validation refuses it in production.
 const devJWTSecret = "dev-only-change-me"
 
 func signingKey() []byte {
-	secret := SecretKey()
+	secret := Current().SecretKey
 	if secret == "" {
 		secret = devJWTSecret
 	}
@@ -0,0 +1,407 @@
+package configs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+
+	StorageMongo  = "mongo"
+	StorageMemory = "memory"
+)
+
+// Config is every setting the server reads at startup.
+type Config struct {
+	// Environment is development or production. Production refuses unsafe fallbacks such as the dev JWT secret.
+	Environment    string   `yaml:"environment"`
+	Port           string   `yaml:"port"`
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// TrustedProxies are the addresses or CIDR ranges of the load balancers in front of the server. Only
+	// their X-Forwarded-For headers are believed; with none, the client address is the TCP peer.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	SecretKey      string   `yaml:"secret_key"`
+	// Storage is mongo, or memory to keep everything in process memory and lose it on exit.
+	Storage string      `yaml:"storage"`
+	Mongo   MongoConfig `yaml:"mongo"`
+	Log     LogConfig   `yaml:"log"`
+	// RateLimit bounds the requests one client can make per window.
+	RateLimit    RateLimitConfig `yaml:"rate_limit"`
+	MaxBodyBytes int64           `yaml:"max_body_bytes"`
+	// SpamBlockedDomains are link domains the spam filter holds in every community.
+	SpamBlockedDomains []string      `yaml:"spam_blocked_domains"`
+	Tracing            TracingConfig `yaml:"tracing"`
+	// MetricsAddr is the host:port of the listener that serves /metrics to Prometheus, kept apart from the
+	// public API port. Empty or none disables it.
+	MetricsAddr string `yaml:"metrics_addr"`
+	// ShutdownDrainDelay is how long the server keeps serving after /readyz starts failing on shutdown,
+	// giving load balancers time to stop routing new requests to it before the listener closes.
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay"`
+}
+
+type MongoConfig struct {
+	URI      string `yaml:"uri"`
+	Database string `yaml:"database"`
+	// MigrateOnStart applies pending migrations when the server starts. Without it, run redditctl migrate up.
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+}
+
+type LogConfig struct {
+	// Format is text for key=value lines or json for one JSON object per line.
+	Format string `yaml:"format"`
+	Level  string `yaml:"level"`
+}
+
+type RateLimitConfig struct {
+	Requests int           `yaml:"requests"`
+	Window   time.Duration `yaml:"window"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp.
+	Exporter string `yaml:"exporter"`
+	// OTLPEndpoint is the OTLP/gRPC collector. An http URL sends spans without TLS.
+	OTLPEndpoint string            `yaml:"otlp_endpoint"`
+	OTLPHeaders  map[string]string `yaml:"otlp_headers"`
+	ServiceName  string            `yaml:"service_name"`
+	// SampleRatio is the share of new traces that are recorded, from 0 to 1. Requests that carry a
+	// caller's trace follow the caller's sampling decision instead.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Defaults is the configuration of a local development server.
+func Defaults() *Config {
+	return &Config{
+		Environment:        EnvDevelopment,
+		Port:               "8080",
+		AllowedOrigins:     []string{"http://localhost:4200"},
+		Storage:            StorageMongo,
+		Mongo:              MongoConfig{URI: "mongodb://localhost:27017", Database: "simple-reddit", MigrateOnStart: true},
+		Log:                LogConfig{Format: logging.FormatText, Level: "info"},
+		RateLimit:          RateLimitConfig{Requests: 120, Window: time.Minute},
+		MaxBodyBytes:       1 << 20,
+		MetricsAddr:        "localhost:9090",
+		ShutdownDrainDelay: 5 * time.Second,
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "http://localhost:4317",
+			ServiceName:  "simple-reddit-backend",
+			SampleRatio:  1,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, then the YAML file at path (or CONFIG_FILE when path
+// is empty) if there is one, then a .env file in the working directory, then the environment, each
+// overriding the last. Variables already set in the environment win over .env.
+//
+// The result is validated. On validation errors the loaded Config is returned along with the error,
+// so that it can still be printed.
+func Load(path string) (*Config, error) {
+	cfg := Defaults()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return cfg, fmt.Errorf("error loading .env file: %w", err)
+	}
+
+	problems := cfg.applyEnv()
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides c with every variable that is set and not empty, returning the ones that could not be parsed.
+func (c *Config) applyEnv() []string {
+	var problems []string
+	lookup := func(name string) (string, bool) {
+		value := strings.TrimSpace(os.Getenv(name))
+		return value, value != ""
+	}
+	str := func(name string, dst *string) {
+		if value, ok := lookup(name); ok {
+			*dst = value
+		}
+	}
+	list := func(name string, dst *[]string) {
+		if value, ok := lookup(name); ok {
+			*dst = splitList(value)
+		}
+	}
+
+	str("APP_ENV", &c.Environment)
+	str("PORT", &c.Port)
+	list("ALLOWED_ORIGINS", &c.AllowedOrigins)
+	list("TRUSTED_PROXIES", &c.TrustedProxies)
+	str("SECRET_KEY", &c.SecretKey)
+	str("STORAGE", &c.Storage)
+	str("MONGOURI", &c.Mongo.URI)
+	str("MONGO_DATABASE", &c.Mongo.Database)
+	str("LOG_FORMAT", &c.Log.Format)
+	str("LOG_LEVEL", &c.Log.Level)
+	list("SPAM_BLOCKED_DOMAINS", &c.SpamBlockedDomains)
+	str("OTEL_TRACES_EXPORTER", &c.Tracing.Exporter)
+	str("OTEL_EXPORTER_OTLP_ENDPOINT", &c.Tracing.OTLPEndpoint)
+	str("OTEL_SERVICE_NAME", &c.Tracing.ServiceName)
+	str("METRICS_ADDR", &c.MetricsAddr)
+
+	if value, ok := lookup("RATE_LIMIT_REQUESTS"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("RATE_LIMIT_REQUESTS: %q is not a number", value))
+		}
+		c.RateLimit.Requests = n
+	}
+	if value, ok := lookup("RATE_LIMIT_WINDOW"); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("RATE_LIMIT_WINDOW: %q is not a duration such as 1m", value))
+		}
+		c.RateLimit.Window = d
+	}
+	if value, ok := lookup("SHUTDOWN_DRAIN_DELAY"); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("SHUTDOWN_DRAIN_DELAY: %q is not a duration such as 5s", value))
+		}
+		c.ShutdownDrainDelay = d
+	}
+	if value, ok := lookup("MIGRATE_ON_START"); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("MIGRATE_ON_START: %q is not true or false", value))
+		}
+		c.Mongo.MigrateOnStart = b
+	}
+	if value, ok := lookup("MAX_BODY_BYTES"); ok {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("MAX_BODY_BYTES: %q is not a number", value))
+		}
+		c.MaxBodyBytes = n
+	}
+	if value, ok := lookup("OTEL_TRACES_SAMPLER_ARG"); ok {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("OTEL_TRACES_SAMPLER_ARG: %q is not a number", value))
+		}
+		c.Tracing.SampleRatio = ratio
+	}
+	if value, ok := lookup("OTEL_EXPORTER_OTLP_HEADERS"); ok {
+		c.Tracing.OTLPHeaders = map[string]string{}
+		for _, pair := range splitList(value) {
+			key, value, ok := strings.Cut(pair, "=")
+			if key = strings.TrimSpace(key); !ok || key == "" {
+				problems = append(problems, fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS: %q is not key=value", pair))
+				continue
+			}
+			c.Tracing.OTLPHeaders[key] = strings.TrimSpace(value)
+		}
+	}
+	return problems
+}
+
+// validate normalizes case-insensitive values and lists everything wrong with c.
+func (c *Config) validate() []string {
+	var problems []string
+	c.Environment = strings.ToLower(c.Environment)
+	c.Storage = strings.ToLower(c.Storage)
+	c.Log.Format = strings.ToLower(c.Log.Format)
+	c.Tracing.Exporter = strings.ToLower(c.Tracing.Exporter)
+	if c.Tracing.Exporter == "console" {
+		c.Tracing.Exporter = "stdout"
+	}
+
+	if c.Environment != EnvDevelopment && c.Environment != EnvProduction {
+		problems = append(problems, fmt.Sprintf("environment: %q is neither %s nor %s", c.Environment, EnvDevelopment, EnvProduction))
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("port: %q is not a TCP port", c.Port))
+	}
+	if len(c.AllowedOrigins) == 0 {
+		problems = append(problems, "allowed_origins: at least one origin is required")
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problems = append(problems, fmt.Sprintf("trusted_proxies: %q is neither an IP address nor a CIDR range", proxy))
+		}
+	}
+
+	switch c.Storage {
+	case StorageMongo:
+		if !strings.HasPrefix(c.Mongo.URI, "mongodb://") && !strings.HasPrefix(c.Mongo.URI, "mongodb+srv://") {
+			problems = append(problems, "mongo.uri: must start with mongodb:// or mongodb+srv://")
+		}
+		if c.Mongo.Database == "" {
+			problems = append(problems, "mongo.database: is required")
+		}
+	case StorageMemory:
+	default:
+		problems = append(problems, fmt.Sprintf("storage: %q is neither %s nor %s", c.Storage, StorageMongo, StorageMemory))
+	}
+
+	if c.Log.Format != logging.FormatText && c.Log.Format != logging.FormatJSON {
+		problems = append(problems, fmt.Sprintf("log.format: %q is neither text nor json", c.Log.Format))
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		problems = append(problems, "log.level: "+err.Error())
+	}
+	if c.RateLimit.Requests < 1 || c.RateLimit.Window <= 0 {
+		problems = append(problems, "rate_limit: requests and window must be positive")
+	}
+	if c.MaxBodyBytes < 1 {
+		problems = append(problems, "max_body_bytes: must be positive")
+	}
+	if c.ShutdownDrainDelay < 0 {
+		problems = append(problems, "shutdown_drain_delay: must not be negative")
+	}
+	if strings.EqualFold(c.MetricsAddr, "none") {
+		c.MetricsAddr = ""
+	}
+	if c.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsAddr); err != nil {
+			problems = append(problems, fmt.Sprintf("metrics_addr: %q is not host:port", c.MetricsAddr))
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if u, err := url.Parse(c.Tracing.OTLPEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("tracing.otlp_endpoint: %q is not an http(s) URL", c.Tracing.OTLPEndpoint))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter: %q is not none, stdout or otlp", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("tracing.sample_ratio: %v is not between 0 and 1", c.Tracing.SampleRatio))
+	}
+
+	if c.Environment == EnvProduction {
+		switch {
+		case c.SecretKey == "" || c.SecretKey == devJWTSecret:
+			problems = append(problems, "secret_key: production needs SECRET_KEY; the development fallback would let anyone forge tokens")
+		case len(c.SecretKey) < 32:
+			problems = append(problems, "secret_key: must be at least 32 characters in production")
+		}
+		if c.Storage == StorageMemory {
+			problems = append(problems, "storage: memory loses all data on restart and is not allowed in production")
+		}
+	}
+	return problems
+}
+
+const redacted = "[redacted]"
+
+// Redacted returns a copy of c that is safe to print: the JWT secret, the MongoDB password and the
+// OTLP header values are replaced.
+func (c *Config) Redacted() *Config {
+	out := *c
+	if out.SecretKey != "" {
+		out.SecretKey = redacted
+	}
+	out.Mongo.URI = redactPassword(out.Mongo.URI)
+	if len(out.Tracing.OTLPHeaders) > 0 {
+		out.Tracing.OTLPHeaders = make(map[string]string, len(c.Tracing.OTLPHeaders))
+		for key := range c.Tracing.OTLPHeaders {
+			out.Tracing.OTLPHeaders[key] = redacted
+		}
+	}
+	return &out
+}
+
+// YAML formats c in the config file format.
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+var (
+	currentMu sync.Mutex
+	current   *Config
+)
+
+// Use makes cfg the configuration returned by Current.
+func Use(cfg *Config) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = cfg
+}
+
+// Current returns the configuration set with Use. Without one, such as in tests, it is loaded from the
+// environment on first use, ignoring validation errors.
+func Current() *Config {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current == nil {
+		current, _ = Load("")
+	}
+	return current
+}
+
+// redactPassword hides the password of a connection string. It does not use url.Parse, which rejects
+// the comma-separated host lists MongoDB URIs allow.
+func redactPassword(uri string) string {
+	scheme := strings.Index(uri, "://")
+	if scheme < 0 {
+		return uri
+	}
+	rest := uri[scheme+3:]
+	if slash := strings.IndexAny(rest, "/?"); slash >= 0 {
+		rest = rest[:slash]
+	}
+	at := strings.LastIndex(rest, "@")
+	colon := strings.Index(rest, ":")
+	if at < 0 || colon < 0 || colon > at {
+		return uri
+	}
+	start := scheme + 3 + colon + 1
+	return uri[:start] + redacted + uri[scheme+3+at:]
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -2,13 +2,15 @@ package configs
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/ganesh96/simple-reddit/backend/logging"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 // DB is the mongo client instance
@@ -18,7 +20,7 @@ var once sync.Once
 // ConnectDB connects to the MongoDB database. It uses sync.Once to ensure it only runs once.
 func ConnectDB() {
 	once.Do(func() {
-		client, err := mongo.NewClient(options.Client().ApplyURI(EnvMongoURI()))
+		client, err := mongo.NewClient(options.Client().ApplyURI(Current().Mongo.URI).SetMonitor(commandMonitor()))
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -35,14 +37,29 @@ func ConnectDB() {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println("Connected to MongoDB")
+		logging.Default().Info("connected to MongoDB")
 		DB = client
 	})
 }
 
-// GetCollection returns a collection from the database
-func GetCollection(collectionName string) *mongo.Collection {
-	ConnectDB() // Ensures the DB is connected before returning the collection
-	collection := DB.Database("simple-reddit").Collection(collectionName)
-	return collection
+// Database returns the configured database, connecting first if needed.
+func Database() *mongo.Database {
+	ConnectDB()
+	return DB.Database(Current().Mongo.Database)
+}
+
+// PingDB checks that the primary answers, for readiness probes.
+func PingDB(ctx context.Context) error {
+	if DB == nil {
+		return errors.New("not connected")
+	}
+	return DB.Ping(ctx, readpref.Primary())
+}
+
+// DisconnectDB closes the connection pool once the server has stopped using it.
+func DisconnectDB(ctx context.Context) error {
+	if DB == nil {
+		return nil
+	}
+	return DB.Disconnect(ctx)
 }
@@ -2,32 +2,40 @@ package configs
 
 import (
 	"context"
-	"log"
-	"time"
+	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func EnsureIndexes() {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	indexes := map[string][]mongo.IndexModel{
+// Indexes lists, by collection, the indexes every collection relies on besides _id.
+func Indexes() map[string][]mongo.IndexModel {
+	return map[string][]mongo.IndexModel{
 		"posts": {
 			{Keys: bson.D{{Key: "community", Value: 1}, {Key: "_id", Value: -1}}},
 			{Keys: bson.D{{Key: "username", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "community", Value: 1}, {Key: "pinned", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "crosspost_parent.id", Value: 1}}, Options: options.Index().SetSparse(true)},
+			{Keys: bson.D{{Key: "content_hash", Value: 1}, {Key: "creation_date", Value: -1}}},
+			{Keys: bson.D{{Key: "community", Value: 1}, {Key: "mod_status", Value: 1}, {Key: "_id", Value: -1}}},
 		},
 		"comments": {
 			{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "stickied", Value: -1}, {Key: "_id", Value: 1}}},
 			{Keys: bson.D{{Key: "username", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "content_hash", Value: 1}, {Key: "creation_date", Value: -1}}},
+			{Keys: bson.D{{Key: "community", Value: 1}, {Key: "mod_status", Value: 1}, {Key: "_id", Value: -1}}},
 		},
 		"votes": {
 			{
 				Keys:    bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "username", Value: 1}},
 				Options: options.Index().SetUnique(true),
 			},
+			{Keys: bson.D{{Key: "username", Value: 1}, {Key: "updation_date", Value: -1}}},
+			{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "updation_date", Value: -1}}},
+			{Keys: bson.D{{Key: "suspicious", Value: 1}}, Options: options.Index().SetSparse(true)},
+			{Keys: bson.D{{Key: "under_review", Value: 1}}, Options: options.Index().SetSparse(true)},
 			{Keys: bson.D{{Key: "username", Value: 1}, {Key: "target_type", Value: 1}}},
 		},
 		"saved": {
@@ -36,15 +44,66 @@ func EnsureIndexes() {
 				Options: options.Index().SetUnique(true),
 			},
 		},
+		"follows": {
+			{
+				Keys:    bson.D{{Key: "follower", Value: 1}, {Key: "followed", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "follower", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "followed", Value: 1}, {Key: "_id", Value: -1}}},
+		},
+		"modlog": {
+			{Keys: bson.D{{Key: "community_id", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "community_id", Value: 1}, {Key: "moderator", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "community_id", Value: 1}, {Key: "action", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "community_name", Value: 1}, {Key: "_id", Value: -1}}},
+		},
+		"notifications": {
+			{Keys: bson.D{{Key: "username", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "username", Value: 1}, {Key: "read", Value: 1}}},
+		},
+		"notification_preferences": {
+			{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		"blocks": {
+			{
+				Keys:    bson.D{{Key: "blocker", Value: 1}, {Key: "blocked", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "blocker", Value: 1}, {Key: "_id", Value: -1}}},
+		},
+		"communities": {
+			{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "type", Value: 1}}},
+		},
+		"community_join_requests": {
+			{
+				Keys:    bson.D{{Key: "community_id", Value: 1}, {Key: "username", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		"conversations": {
+			{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "participants", Value: 1}, {Key: "last_message_id", Value: -1}}},
+		},
+		"messages": {
+			{Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "recipient", Value: 1}, {Key: "read_at", Value: 1}}},
+		},
 		"users": {
 			{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
 			{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
 		},
 	}
+}
 
-	for collectionName, models := range indexes {
-		if _, err := GetCollection(collectionName).Indexes().CreateMany(ctx, models); err != nil {
-			log.Printf("failed to create indexes for %s: %v", collectionName, err)
+// EnsureIndexes creates the Indexes in db. Creating an index that already exists is a no-op, so it is
+// safe to run again; migrations call it when Indexes changes.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	for collectionName, models := range Indexes() {
+		if _, err := db.Collection(collectionName).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("failed to create indexes for %s: %w", collectionName, err)
 		}
 	}
+	return nil
 }
@@ -0,0 +1,69 @@
+package configs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ganesh96/simple-reddit/backend/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var mongoCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "mongodb_command_duration_seconds",
+	Help:    "Time taken by MongoDB commands, by command name and outcome.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"command", "outcome"})
+
+// commandMonitor records the duration of every command the driver sends, and traces each one as a
+// child of the span in the context it was issued with.
+func commandMonitor() *event.CommandMonitor {
+	var spans sync.Map // event RequestID -> trace.Span
+
+	endSpan := func(requestID int64, failure string) {
+		value, ok := spans.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if failure != "" {
+			span.SetStatus(codes.Error, failure)
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			name := e.CommandName + " " + e.DatabaseName
+			collection, hasCollection := e.Command.Lookup(e.CommandName).StringValueOK()
+			if hasCollection {
+				name += "." + collection
+			}
+			_, span := tracing.Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+			if !span.IsRecording() {
+				return
+			}
+			span.SetAttributes(
+				attribute.String("db.system", "mongodb"),
+				attribute.String("db.name", e.DatabaseName),
+				attribute.String("db.operation", e.CommandName),
+			)
+			if hasCollection {
+				span.SetAttributes(attribute.String("db.mongodb.collection", collection))
+			}
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			mongoCommandDuration.WithLabelValues(e.CommandName, "success").Observe(e.Duration.Seconds())
+			endSpan(e.RequestID, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			mongoCommandDuration.WithLabelValues(e.CommandName, "failure").Observe(e.Duration.Seconds())
+			endSpan(e.RequestID, e.Failure)
+		},
+	}
+}
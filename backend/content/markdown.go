@@ -0,0 +1,190 @@
+// Package content turns user-written markdown into HTML that is safe to embed in a page.
+//
+// Only a small subset is supported: paragraphs, headings, block quotes, lists, fenced code blocks,
+// code spans, bold, italics, strikethrough, links and bare URLs, plus r/community and u/user references.
+// Every character of the source is HTML-escaped and the only tags in the output are the ones generated
+// here, so no raw HTML from users can reach readers.
+package content
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxQuoteDepth stops deeply nested block quotes from recursing without bound.
+const maxQuoteDepth = 8
+
+var (
+	fencePattern       = regexp.MustCompile("^\\s*```")
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	quotePattern       = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	bulletPattern      = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedPattern     = regexp.MustCompile(`^\s*\d{1,9}[.)]\s+(.*)$`)
+	codeSpanPattern    = regexp.MustCompile("`([^`\n]+)`")
+	linkPattern        = regexp.MustCompile(`\[([^\[\]\n]+)\]\(([^()\s]+)\)`)
+	bareURLPattern     = regexp.MustCompile(`https?://[^\s<>"'\x00]+`)
+	referencePattern   = regexp.MustCompile(`(^|[^\w/])([ru])/([A-Za-z0-9_-]+)`)
+	strongPattern      = regexp.MustCompile(`\*\*(\S(?:[^\n]*?\S)?)\*\*`)
+	strikePattern      = regexp.MustCompile(`~~(\S(?:[^\n]*?\S)?)~~`)
+	emphasisPattern    = regexp.MustCompile(`\*(\S(?:[^*\n]*?\S)?)\*`)
+	underscorePattern  = regexp.MustCompile(`(^|[^\w])_(\S(?:[^_\n]*?\S)?)_($|[^\w])`)
+	placeholderPattern = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// RenderMarkdown converts markdown source into sanitized HTML.
+func RenderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\x00", "")
+	return renderBlocks(strings.Split(source, "\n"), 0)
+}
+
+func renderBlocks(lines []string, depth int) string {
+	var out strings.Builder
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + renderInline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+
+		case fencePattern.MatchString(line):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !fencePattern.MatchString(lines[i]); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+
+		case headingPattern.MatchString(line):
+			flush()
+			match := headingPattern.FindStringSubmatch(line)
+			level := strconv.Itoa(len(match[1]))
+			out.WriteString("<h" + level + ">" + renderInline(match[2]) + "</h" + level + ">\n")
+
+		case quotePattern.MatchString(line) && depth < maxQuoteDepth:
+			flush()
+			var quoted []string
+			for ; i < len(lines) && quotePattern.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quotePattern.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			out.WriteString("<blockquote>\n" + renderBlocks(quoted, depth+1) + "</blockquote>\n")
+
+		case bulletPattern.MatchString(line):
+			flush()
+			i = renderList(&out, lines, i, bulletPattern, "ul")
+
+		case orderedPattern.MatchString(line):
+			flush()
+			i = renderList(&out, lines, i, orderedPattern, "ol")
+
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+
+	return out.String()
+}
+
+// renderList writes the list starting at lines[start] and returns the index of its last line.
+func renderList(out *strings.Builder, lines []string, start int, item *regexp.Regexp, tag string) int {
+	out.WriteString("<" + tag + ">\n")
+	i := start
+	for ; i < len(lines) && item.MatchString(lines[i]); i++ {
+		out.WriteString("<li>" + renderInline(item.FindStringSubmatch(lines[i])[1]) + "</li>\n")
+	}
+	out.WriteString("</" + tag + ">\n")
+	return i - 1
+}
+
+// renderInline escapes a run of text and applies inline formatting. Fragments that must not be
+// reformatted (code, links, references) are swapped for placeholders before escaping and restored at the end.
+func renderInline(text string) string {
+	var fragments []string
+	hold := func(fragment string) string {
+		fragments = append(fragments, fragment)
+		return "\x00" + strconv.Itoa(len(fragments)-1) + "\x00"
+	}
+
+	text = codeSpanPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return hold("<code>" + html.EscapeString(match[1:len(match)-1]) + "</code>")
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := linkPattern.FindStringSubmatch(match)
+		href, ok := safeHref(parts[2])
+		if !ok {
+			return match
+		}
+		return hold(anchor(href, applyEmphasis(html.EscapeString(parts[1]))))
+	})
+	text = bareURLPattern.ReplaceAllStringFunc(text, func(match string) string {
+		trimmed := strings.TrimRight(match, ".,;:!?)")
+		href, ok := safeHref(trimmed)
+		if !ok {
+			return match
+		}
+		return hold(anchor(href, html.EscapeString(trimmed))) + match[len(trimmed):]
+	})
+	text = referencePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := referencePattern.FindStringSubmatch(match)
+		reference := parts[2] + "/" + parts[3]
+		return parts[1] + hold(anchor("/"+reference, html.EscapeString(reference)))
+	})
+
+	text = applyEmphasis(html.EscapeString(text))
+
+	// A link label may itself hold a code span placeholder, so restore until none are left.
+	for placeholderPattern.MatchString(text) {
+		text = placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+			index, _ := strconv.Atoi(match[1 : len(match)-1])
+			return fragments[index]
+		})
+	}
+	return text
+}
+
+// applyEmphasis formats already-escaped text.
+func applyEmphasis(escaped string) string {
+	escaped = strongPattern.ReplaceAllString(escaped, "<strong>$1</strong>")
+	escaped = strikePattern.ReplaceAllString(escaped, "<del>$1</del>")
+	escaped = emphasisPattern.ReplaceAllString(escaped, "<em>$1</em>")
+	return underscorePattern.ReplaceAllString(escaped, "$1<em>$2</em>$3")
+}
+
+// safeHref accepts absolute http(s) and mailto links and returns them escaped for an attribute.
+func safeHref(raw string) (string, bool) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		if parsed.Host == "" {
+			return "", false
+		}
+	case "mailto":
+	default:
+		return "", false
+	}
+	return html.EscapeString(parsed.String()), true
+}
+
+func anchor(href string, label string) string {
+	if strings.HasPrefix(href, "/") {
+		return `<a href="` + href + `">` + label + `</a>`
+	}
+	return `<a href="` + href + `" rel="nofollow noopener noreferrer">` + label + `</a>`
+}
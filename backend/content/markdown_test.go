@@ -0,0 +1,60 @@
+package content
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMarkdownEscapesRawHTML(t *testing.T) {
+	out := RenderMarkdown(`<script>alert("x")</script> <img src=x onerror=alert(1)>`)
+
+	assert.NotContains(t, out, "<script")
+	assert.NotContains(t, out, "<img")
+	assert.Contains(t, out, "&lt;script&gt;")
+}
+
+func TestRenderMarkdownInlineFormatting(t *testing.T) {
+	out := RenderMarkdown("**bold** *italic* _also_ ~~gone~~ `a <b> *c*`")
+
+	assert.Equal(t, "<p><strong>bold</strong> <em>italic</em> <em>also</em> <del>gone</del> <code>a &lt;b&gt; *c*</code></p>\n", out)
+}
+
+func TestRenderMarkdownLinks(t *testing.T) {
+	out := RenderMarkdown("[docs](https://example.com/a?b=1&c=2) and https://go.dev/x_y_z.")
+
+	assert.Contains(t, out, `<a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer">docs</a>`)
+	assert.Contains(t, out, `<a href="https://go.dev/x_y_z" rel="nofollow noopener noreferrer">https://go.dev/x_y_z</a>.`)
+}
+
+func TestRenderMarkdownRejectsUnsafeLinks(t *testing.T) {
+	out := RenderMarkdown("[click](javascript:alert(1)) [x](data:text/html,hi)")
+
+	assert.NotContains(t, out, "href")
+}
+
+func TestRenderMarkdownReferences(t *testing.T) {
+	out := RenderMarkdown("ask r/golang or u/gopher_1, not https://reddit.com/r/other")
+
+	assert.Contains(t, out, `<a href="/r/golang">r/golang</a>`)
+	assert.Contains(t, out, `<a href="/u/gopher_1">u/gopher_1</a>`)
+	assert.NotContains(t, out, `href="/r/other"`)
+}
+
+func TestRenderMarkdownBlocks(t *testing.T) {
+	source := "# Title\n\n> quoted **text**\n\n- one\n- two\n\n1. first\n\n```\n<b>code</b>\n```\nafter"
+
+	assert.Equal(t, "<h1>Title</h1>\n"+
+		"<blockquote>\n<p>quoted <strong>text</strong></p>\n</blockquote>\n"+
+		"<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n"+
+		"<ol>\n<li>first</li>\n</ol>\n"+
+		"<pre><code>&lt;b&gt;code&lt;/b&gt;</code></pre>\n"+
+		"<p>after</p>\n", RenderMarkdown(source))
+}
+
+func TestRenderMarkdownLimitsQuoteDepth(t *testing.T) {
+	out := RenderMarkdown("> > > > > > > > > > > deep")
+
+	assert.Equal(t, maxQuoteDepth*2, strings.Count(out, "blockquote>"))
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"sync"
+)
+
+// subscriberBuffer bounds how far a slow stream may fall behind before events are dropped for it.
+const subscriberBuffer = 32
+
+// Broker fans events out to everyone subscribed to a topic.
+// MemoryBroker only reaches subscribers in this process; a distributed broker
+// (Redis pub/sub, NATS, ...) can implement the same interface and be installed with SetBroker.
+type Broker interface {
+	Publish(topic string, event Event)
+	Subscribe(topic string) (<-chan Event, func())
+}
+
+// MemoryBroker is an in-process Broker. Publishing never blocks: a subscriber whose buffer is full misses the event.
+type MemoryBroker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscribers: map[string]map[chan Event]struct{}{}}
+}
+
+func (b *MemoryBroker) Publish(topic string, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[topic] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of events for topic and a function that must be called to release it.
+func (b *MemoryBroker) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = map[chan Event]struct{}{}
+	}
+	b.subscribers[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[topic], ch)
+			if len(b.subscribers[topic]) == 0 {
+				delete(b.subscribers, topic)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBrokerDeliversToTopicSubscribers(t *testing.T) {
+	broker := NewMemoryBroker()
+	first, unsubscribeFirst := broker.Subscribe("posts/a")
+	defer unsubscribeFirst()
+	other, unsubscribeOther := broker.Subscribe("posts/b")
+	defer unsubscribeOther()
+
+	broker.Publish("posts/a", Event{Type: CommentCreated})
+
+	assert.Equal(t, CommentCreated, (<-first).Type)
+	assert.Len(t, other, 0)
+}
+
+func TestMemoryBrokerUnsubscribeClosesChannel(t *testing.T) {
+	broker := NewMemoryBroker()
+	stream, unsubscribe := broker.Subscribe("posts/a")
+
+	unsubscribe()
+	unsubscribe()
+	broker.Publish("posts/a", Event{Type: CommentDeleted})
+
+	_, ok := <-stream
+	assert.False(t, ok)
+	assert.Empty(t, broker.subscribers)
+}
+
+func TestMemoryBrokerDropsEventsForSlowSubscribers(t *testing.T) {
+	broker := NewMemoryBroker()
+	stream, unsubscribe := broker.Subscribe("posts/a")
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		broker.Publish("posts/a", Event{Type: VotesChanged})
+	}
+
+	assert.Len(t, stream, subscriberBuffer)
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CommentCreated = "comment_created"
+	CommentUpdated = "comment_updated"
+	CommentDeleted = "comment_deleted"
+	VotesChanged   = "votes_changed"
+)
+
+// Event is a change to a post or to one of its comments, pushed to readers of that post's stream.
+type Event struct {
+	Type   string             `json:"type"`
+	PostID primitive.ObjectID `json:"post_id"`
+	// Author wrote the comment a comment event is about, so streams can leave out authors their reader blocked.
+	Author string      `json:"author,omitempty"`
+	Data   interface{} `json:"data"`
+	Time   time.Time   `json:"time"`
+}
+
+var broker = common.NewHolder[Broker](NewMemoryBroker())
+
+// SetBroker installs the broker used by Publish and Subscribe. Without one, events stay in this process.
+func SetBroker(b Broker) {
+	broker.Set(b)
+}
+
+func currentBroker() Broker {
+	return broker.Get()
+}
+
+// PublishPostEvent notifies every stream of postID about a change.
+func PublishPostEvent(postID primitive.ObjectID, eventType string, data interface{}) {
+	currentBroker().Publish(postTopic(postID), Event{
+		Type:   eventType,
+		PostID: postID,
+		Data:   data,
+		Time:   time.Now(),
+	})
+}
+
+// PublishCommentEvent notifies every stream of postID about a change to a comment written by author.
+func PublishCommentEvent(postID primitive.ObjectID, eventType string, author string, data interface{}) {
+	currentBroker().Publish(postTopic(postID), Event{
+		Type:   eventType,
+		PostID: postID,
+		Author: author,
+		Data:   data,
+		Time:   time.Now(),
+	})
+}
+
+// SubscribePost streams the events of postID until the returned function is called.
+func SubscribePost(postID primitive.ObjectID) (<-chan Event, func()) {
+	return currentBroker().Subscribe(postTopic(postID))
+}
+
+func postTopic(postID primitive.ObjectID) string {
+	return "posts/" + postID.Hex()
+}
@@ -0,0 +1,53 @@
+package follows
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+)
+
+type memoryRepository struct {
+	follows *memstore.Table[Follow]
+}
+
+// NewMemoryRepository keeps follows in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{follows: memstore.NewTable[Follow]()}
+}
+
+func (r *memoryRepository) FollowedUsernames(ctx context.Context, follower string) ([]string, error) {
+	usernames := []string{}
+	for _, follow := range r.follows.Find(matching(Filter{Follower: follower})) {
+		usernames = append(usernames, follow.Followed)
+	}
+	return usernames, nil
+}
+
+func (r *memoryRepository) Count(ctx context.Context, filter Filter) (int64, error) {
+	return r.follows.Count(matching(filter)), nil
+}
+
+func (r *memoryRepository) Follow(ctx context.Context, follow Follow) (bool, error) {
+	err := r.follows.Insert(follow, matching(Filter{Follower: follow.Follower, Followed: follow.Followed}))
+	if err == common.ErrDuplicate {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, filter Filter) error {
+	r.follows.Delete(matching(filter))
+	return nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, filter Filter, page common.PageRequest) ([]Follow, error) {
+	return memstore.Page(r.follows.Find(matching(filter)), page, true), nil
+}
+
+func matching(filter Filter) func(Follow) bool {
+	return func(follow Follow) bool {
+		return (filter.Follower == "" || follow.Follower == filter.Follower) &&
+			(filter.Followed == "" || follow.Followed == filter.Followed)
+	}
+}
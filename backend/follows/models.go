@@ -0,0 +1,36 @@
+package follows
+
+import (
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxFollowing bounds how many users one account may follow, which also bounds the following feed query:
+// MongoDB only merges an $in over an index in sort order for up to 200 values.
+const MaxFollowing = 200
+
+// Follow records that Follower wants Followed's posts in their following feed.
+type Follow struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Follower     string             `json:"follower" bson:"follower,omitempty"`
+	Followed     string             `json:"followed" bson:"followed,omitempty"`
+	CreationDate time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
+}
+
+func (f Follow) GetID() primitive.ObjectID {
+	return f.ID
+}
+
+// FollowersResponse is the data of a user's followers listing.
+type FollowersResponse struct {
+	Followers  []Follow          `json:"followers"`
+	Pagination common.Pagination `json:"pagination"`
+}
+
+// FollowingResponse is the data of the listing of users a user follows.
+type FollowingResponse struct {
+	Following  []Follow          `json:"following"`
+	Pagination common.Pagination `json:"pagination"`
+}
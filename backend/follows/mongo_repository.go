@@ -0,0 +1,85 @@
+package follows
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	follows *mongo.Collection
+}
+
+// NewMongoRepository stores follows in the follows collection of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{follows: db.Collection("follows")}
+}
+
+func (r *mongoRepository) FollowedUsernames(ctx context.Context, follower string) ([]string, error) {
+	values, err := r.follows.Distinct(ctx, "followed", bson.M{"follower": follower})
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make([]string, 0, len(values))
+	for _, value := range values {
+		if username, ok := value.(string); ok {
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames, nil
+}
+
+func (r *mongoRepository) Count(ctx context.Context, filter Filter) (int64, error) {
+	return r.follows.CountDocuments(ctx, filterDocument(filter))
+}
+
+func (r *mongoRepository) Follow(ctx context.Context, follow Follow) (bool, error) {
+	filter := bson.M{"follower": follow.Follower, "followed": follow.Followed}
+	update := bson.M{"$setOnInsert": follow}
+	result, err := r.follows.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+func (r *mongoRepository) Delete(ctx context.Context, filter Filter) error {
+	_, err := r.follows.DeleteMany(ctx, filterDocument(filter))
+	return err
+}
+
+func (r *mongoRepository) List(ctx context.Context, filter Filter, page common.PageRequest) ([]Follow, error) {
+	document := filterDocument(filter)
+	if page.HasAfter {
+		document["_id"] = bson.M{"$lt": page.AfterID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.follows.Find(ctx, document, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Follow
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func filterDocument(filter Filter) bson.M {
+	document := bson.M{}
+	if filter.Follower != "" {
+		document["follower"] = filter.Follower
+	}
+	if filter.Followed != "" {
+		document["followed"] = filter.Followed
+	}
+	return document
+}
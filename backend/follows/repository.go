@@ -0,0 +1,35 @@
+package follows
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+)
+
+// Filter selects follows. Empty fields match every follow.
+type Filter struct {
+	Follower string
+	Followed string
+}
+
+// Repository stores who follows whom.
+type Repository interface {
+	FollowedUsernames(ctx context.Context, follower string) ([]string, error)
+	Count(ctx context.Context, filter Filter) (int64, error)
+	// Follow stores follow unless its follower already follows the same user, and reports whether it was stored.
+	Follow(ctx context.Context, follow Follow) (bool, error)
+	Delete(ctx context.Context, filter Filter) error
+	// List returns the matching follows, most recent first, reading up to page.Limit+1 of them.
+	List(ctx context.Context, filter Filter, page common.PageRequest) ([]Follow, error)
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+func repo() Repository {
+	return repository.Get()
+}
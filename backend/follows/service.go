@@ -0,0 +1,161 @@
+package follows
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/blocks"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func init() {
+	// Blocking ends follows in both directions, so neither user keeps the other in their following feed.
+	blocks.OnBlock(func(ctx context.Context, blocker string, blocked string) error {
+		if err := repo().Delete(ctx, Filter{Follower: blocker, Followed: blocked}); err != nil {
+			return err
+		}
+		return repo().Delete(ctx, Filter{Follower: blocked, Followed: blocker})
+	})
+}
+
+// FollowedUsernames lists everyone follower follows.
+func FollowedUsernames(ctx context.Context, follower string) ([]string, error) {
+	return repo().FollowedUsernames(ctx, follower)
+}
+
+// Counts returns how many followers username has and how many users they follow.
+func Counts(ctx context.Context, username string) (int64, int64, error) {
+	followers, err := repo().Count(ctx, Filter{Followed: username})
+	if err != nil {
+		return 0, 0, err
+	}
+	following, err := repo().Count(ctx, Filter{Follower: username})
+	if err != nil {
+		return 0, 0, err
+	}
+	return followers, following, nil
+}
+
+// FollowUser adds the user in the URL to the caller's following feed.
+func FollowUser(c *gin.Context) {
+	follower := c.GetString("username")
+	followed := c.Param("username")
+	if followed == follower {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "You cannot follow yourself"))
+		return
+	}
+
+	exists, err := users.Exists(c.Request.Context(), followed)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to follow user"))
+		return
+	}
+	if !exists {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.USER_NOT_FOUND, "User not found"))
+		return
+	}
+
+	blocked, err := blocks.IsBlocked(c.Request.Context(), followed, follower)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error checking blocks", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to follow user"))
+		return
+	}
+	if blocked {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.USER_BLOCKED, "You cannot follow this user"))
+		return
+	}
+
+	following, err := repo().Count(c.Request.Context(), Filter{Follower: follower})
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error counting follows", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to follow user"))
+		return
+	}
+	if following >= MaxFollowing {
+		common.RespondWithError(c, common.NewAPIError(http.StatusConflict, common.FOLLOW_LIMIT_REACHED, "Unfollow someone first"))
+		return
+	}
+
+	follow := Follow{
+		ID:           primitive.NewObjectID(),
+		Follower:     follower,
+		Followed:     followed,
+		CreationDate: time.Now(),
+	}
+	created, err := repo().Follow(c.Request.Context(), follow)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error following user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to follow user"))
+		return
+	}
+	if created {
+		// Concurrent follows can all pass the count above, so count again and take this one back if
+		// the cap was exceeded.
+		following, err := repo().Count(c.Request.Context(), Filter{Follower: follower})
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("error counting follows", "error", err)
+			common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to follow user"))
+			return
+		}
+		if following > MaxFollowing {
+			if err := repo().Delete(c.Request.Context(), Filter{Follower: follower, Followed: followed}); err != nil {
+				logging.FromContext(c.Request.Context()).Error("error undoing follow", "error", err)
+			}
+			common.RespondWithError(c, common.NewAPIError(http.StatusConflict, common.FOLLOW_LIMIT_REACHED, "Unfollow someone first"))
+			return
+		}
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "User followed successfully"})
+}
+
+// UnfollowUser removes the user in the URL from the caller's following feed.
+func UnfollowUser(c *gin.Context) {
+	filter := Filter{Follower: c.GetString("username"), Followed: c.Param("username")}
+	if err := repo().Delete(c.Request.Context(), filter); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error unfollowing user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to unfollow user"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "User unfollowed successfully"})
+}
+
+// GetFollowers retrieves a bounded page of the users following the user in the URL, most recent first.
+func GetFollowers(c *gin.Context) {
+	listFollows(c, Filter{Followed: c.Param("username")}, "followers", func(follows []Follow, pagination common.Pagination) FollowersResponse {
+		return FollowersResponse{Followers: follows, Pagination: pagination}
+	})
+}
+
+// GetFollowing retrieves a bounded page of the users the user in the URL follows, most recent first.
+func GetFollowing(c *gin.Context) {
+	listFollows(c, Filter{Follower: c.Param("username")}, "following", func(follows []Follow, pagination common.Pagination) FollowingResponse {
+		return FollowingResponse{Following: follows, Pagination: pagination}
+	})
+}
+
+func listFollows[T any](c *gin.Context, filter Filter, key string, response func([]Follow, common.Pagination) T) {
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	results, err := repo().List(c.Request.Context(), filter, page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding follows", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve "+key))
+		return
+	}
+
+	follows, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, response(follows, pagination))
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting_down"
+)
+
+// checkTimeout bounds each readiness check, so a hung dependency fails the probe instead of stalling it.
+const checkTimeout = 2 * time.Second
+
+// Check reports whether one dependency the API needs can be used.
+type Check func(ctx context.Context) error
+
+// Report is the data of both probes. Checks is only set by Readyz.
+type Report struct {
+	Status        string                 `json:"status"`
+	UptimeSeconds int64                  `json:"uptime_seconds"`
+	Checks        map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+var (
+	started = time.Now()
+
+	checks       = common.NewHolder(map[string]Check{})
+	shuttingDown = make(chan struct{})
+	shutdownOnce sync.Once
+)
+
+// SetChecks installs the checks run by Readyz.
+func SetChecks(c map[string]Check) {
+	checks.Set(c)
+}
+
+// ShutDown makes Readyz fail, so load balancers stop sending new requests while in-flight ones drain,
+// and closes ShuttingDown so long-lived streams end. It is safe to call more than once.
+func ShutDown() {
+	shutdownOnce.Do(func() { close(shuttingDown) })
+}
+
+// ShuttingDown is closed once the server starts shutting down.
+func ShuttingDown() <-chan struct{} {
+	return shuttingDown
+}
+
+// Healthz reports that the process is up and serving requests. It never checks dependencies,
+// so a database outage does not get healthy processes restarted.
+func Healthz(c *gin.Context) {
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, Report{Status: StatusOK, UptimeSeconds: uptime()})
+}
+
+// Readyz runs every readiness check and answers 503 with the failing ones when the API should not get traffic.
+func Readyz(c *gin.Context) {
+	report := Report{Status: StatusOK, UptimeSeconds: uptime(), Checks: map[string]CheckResult{}}
+
+	select {
+	case <-shuttingDown:
+		report.Status = StatusShuttingDown
+		common.RespondWithJSON(c, http.StatusServiceUnavailable, common.SERVICE_UNAVAILABLE, report)
+		return
+	default:
+	}
+
+	for name, check := range currentChecks() {
+		result := run(c.Request.Context(), check)
+		if result.Status != StatusOK {
+			report.Status = StatusFailing
+		}
+		report.Checks[name] = result
+	}
+
+	if report.Status != StatusOK {
+		common.RespondWithJSON(c, http.StatusServiceUnavailable, common.SERVICE_UNAVAILABLE, report)
+		return
+	}
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, report)
+}
+
+func run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{Status: StatusOK, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func currentChecks() map[string]Check {
+	return checks.Get()
+}
+
+func uptime() int64 {
+	return int64(time.Since(started).Seconds())
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probe(t *testing.T, path string) (int, common.Envelope[Report]) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/healthz", Healthz)
+	router.GET("/readyz", Readyz)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var envelope common.Envelope[Report]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	return w.Code, envelope
+}
+
+func TestReadyzReportsFailingChecks(t *testing.T) {
+	SetChecks(map[string]Check{
+		"mongo":   func(ctx context.Context) error { return nil },
+		"indexes": func(ctx context.Context) error { return errors.New("indexes have not been ensured yet") },
+	})
+	defer SetChecks(map[string]Check{})
+
+	status, envelope := probe(t, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, common.SERVICE_UNAVAILABLE, envelope.Code)
+	assert.Equal(t, StatusFailing, envelope.Data.Status)
+	assert.Equal(t, StatusOK, envelope.Data.Checks["mongo"].Status)
+	assert.Equal(t, "indexes have not been ensured yet", envelope.Data.Checks["indexes"].Error)
+
+	status, envelope = probe(t, "/healthz")
+	assert.Equal(t, http.StatusOK, status, "liveness does not depend on the database")
+	assert.Equal(t, StatusOK, envelope.Data.Status)
+}
+
+func TestReadyzTimesOutHungChecks(t *testing.T) {
+	SetChecks(map[string]Check{
+		"mongo": func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	defer SetChecks(map[string]Check{})
+
+	status, envelope := probe(t, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), envelope.Data.Checks["mongo"].Error)
+}
+
+// TestShutDownFailsReadiness runs last in this file because shutting down cannot be undone.
+func TestShutDownFailsReadiness(t *testing.T) {
+	status, _ := probe(t, "/readyz")
+	require.Equal(t, http.StatusOK, status)
+
+	ShutDown()
+	ShutDown()
+
+	status, envelope := probe(t, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, StatusShuttingDown, envelope.Data.Status)
+	select {
+	case <-ShuttingDown():
+	default:
+		t.Fatal("ShuttingDown was not closed")
+	}
+
+	status, _ = probe(t, "/healthz")
+	assert.Equal(t, http.StatusOK, status)
+}
@@ -0,0 +1,214 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+// ParseLevel reads a LOG_LEVEL value. An empty value is info.
+func ParseLevel(value string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", value)
+}
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// sink is where every logger derived from one Configure call writes.
+type sink struct {
+	mu    sync.Mutex
+	w     io.Writer
+	json  bool
+	level Level
+}
+
+// Logger writes one line per entry: a message plus key-value pairs, either as logfmt-style text or as a JSON object.
+// Loggers are cheap to derive with With and safe for concurrent use.
+type Logger struct {
+	sink   *sink
+	fields []interface{}
+}
+
+var defaultLogger = common.NewHolder(New(os.Stderr, FormatText, LevelInfo))
+
+func New(w io.Writer, format string, level Level) *Logger {
+	return &Logger{sink: &sink{w: w, json: format == FormatJSON, level: level}}
+}
+
+// Configure replaces the default logger. Lines written through the standard library's log package, by
+// dependencies or startup code, become info entries of the same logger so they share its format.
+func Configure(w io.Writer, format string, level Level) {
+	logger := New(w, format, level)
+	defaultLogger.Set(logger)
+	log.SetFlags(0)
+	log.SetOutput(stdlibWriter{logger: logger})
+}
+
+// stdlibWriter turns each line the standard library's log package writes into an entry.
+type stdlibWriter struct {
+	logger *Logger
+}
+
+func (w stdlibWriter) Write(p []byte) (int, error) {
+	w.logger.Info(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// Default returns the logger used outside requests and by requests that carry none.
+func Default() *Logger {
+	return defaultLogger.Get()
+}
+
+// With returns a logger that adds the given key-value pairs to every entry.
+func (l *Logger) With(keyValues ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(keyValues))
+	fields = append(append(fields, l.fields...), keyValues...)
+	return &Logger{sink: l.sink, fields: fields}
+}
+
+func (l *Logger) Debug(msg string, keyValues ...interface{}) { l.log(LevelDebug, msg, keyValues) }
+func (l *Logger) Info(msg string, keyValues ...interface{})  { l.log(LevelInfo, msg, keyValues) }
+func (l *Logger) Warn(msg string, keyValues ...interface{})  { l.log(LevelWarn, msg, keyValues) }
+func (l *Logger) Error(msg string, keyValues ...interface{}) { l.log(LevelError, msg, keyValues) }
+
+func (l *Logger) log(level Level, msg string, keyValues []interface{}) {
+	if level < l.sink.level {
+		return
+	}
+
+	pairs := append(append([]interface{}{}, l.fields...), keyValues...)
+	if len(pairs)%2 != 0 {
+		pairs = append(pairs, "(missing)")
+	}
+
+	var line []byte
+	if l.sink.json {
+		line = formatJSON(time.Now(), level, msg, pairs)
+	} else {
+		line = formatText(time.Now(), level, msg, pairs)
+	}
+
+	l.sink.mu.Lock()
+	defer l.sink.mu.Unlock()
+	_, _ = l.sink.w.Write(line)
+}
+
+func formatText(now time.Time, level Level, msg string, pairs []interface{}) []byte {
+	var b strings.Builder
+	b.WriteString("time=")
+	b.WriteString(now.UTC().Format(time.RFC3339Nano))
+	b.WriteString(" level=")
+	b.WriteString(level.String())
+	b.WriteString(" msg=")
+	b.WriteString(quoteIfNeeded(msg))
+	for i := 0; i < len(pairs); i += 2 {
+		b.WriteByte(' ')
+		b.WriteString(fmt.Sprint(pairs[i]))
+		b.WriteByte('=')
+		b.WriteString(quoteIfNeeded(fmt.Sprint(plain(pairs[i+1]))))
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+func quoteIfNeeded(value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"\t\r\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func formatJSON(now time.Time, level Level, msg string, pairs []interface{}) []byte {
+	var b strings.Builder
+	b.WriteString(`{"time":`)
+	writeJSON(&b, now.UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	writeJSON(&b, level.String())
+	b.WriteString(`,"msg":`)
+	writeJSON(&b, msg)
+	for i := 0; i < len(pairs); i += 2 {
+		b.WriteByte(',')
+		writeJSON(&b, fmt.Sprint(pairs[i]))
+		b.WriteByte(':')
+		writeJSON(&b, plain(pairs[i+1]))
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+func writeJSON(b *strings.Builder, value interface{}) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		encoded, _ = json.Marshal(fmt.Sprint(value))
+	}
+	b.Write(encoded)
+}
+
+// plain turns values that do not encode usefully, such as errors and durations, into strings.
+func plain(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	}
+	return value
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of the request ctx belongs to, or the default logger.
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return logger
+	}
+	return Default()
+}
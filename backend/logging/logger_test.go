@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFormat(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, FormatJSON, LevelInfo).With("request_id", "abc")
+
+	logger.Error("error finding posts", "error", errors.New("connection refused"), "status", 500)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "error finding posts", entry["msg"])
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.Equal(t, "connection refused", entry["error"])
+	assert.Equal(t, float64(500), entry["status"])
+	assert.NotEmpty(t, entry["time"])
+}
+
+func TestTextFormatQuotesValues(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, FormatText, LevelInfo)
+
+	logger.Info("request", "route", "/posts/:postId", "error", "not found", "odd")
+
+	assert.Regexp(t, `^time=\S+ level=info msg=request route=/posts/:postId error="not found" odd=\(missing\)\n$`, out.String())
+}
+
+func TestLevelFiltersEntries(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, FormatText, LevelWarn)
+
+	logger.Debug("hidden")
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	assert.Contains(t, out.String(), "msg=shown")
+	assert.NotContains(t, out.String(), "hidden")
+
+	_, err := ParseLevel("loud")
+	assert.Error(t, err)
+	level, err := ParseLevel("WARNING")
+	require.NoError(t, err)
+	assert.Equal(t, LevelWarn, level)
+}
+
+func TestWithDoesNotShareFields(t *testing.T) {
+	var out bytes.Buffer
+	base := New(&out, FormatText, LevelInfo).With("a", 1)
+	first := base.With("b", 2)
+	second := base.With("c", 3)
+
+	first.Info("first")
+	second.Info("second")
+
+	assert.Contains(t, out.String(), "msg=first a=1 b=2\n")
+	assert.Contains(t, out.String(), "msg=second a=1 c=3\n")
+}
+
+func TestFromContextFallsBackToDefault(t *testing.T) {
+	assert.Same(t, Default(), FromContext(context.Background()))
+
+	logger := Default().With("request_id", "abc")
+	assert.Same(t, logger, FromContext(NewContext(context.Background(), logger)))
+}
+
+func TestConfigureRedirectsStandardLog(t *testing.T) {
+	var out bytes.Buffer
+	Configure(&out, FormatJSON, LevelInfo)
+	defer Configure(os.Stderr, FormatText, LevelInfo)
+
+	log.Printf("connection pool %s", "ready")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "connection pool ready", entry["msg"])
+}
@@ -1,56 +1,195 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"runtime/debug"
+	"syscall"
 	"time"
 
+	"github.com/ganesh96/simple-reddit/backend/common"
 	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/health"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/metrics"
 	"github.com/ganesh96/simple-reddit/backend/middleware"
+	"github.com/ganesh96/simple-reddit/backend/migrations"
 	"github.com/ganesh96/simple-reddit/backend/routes"
+	"github.com/ganesh96/simple-reddit/backend/store"
+	"github.com/ganesh96/simple-reddit/backend/tracing"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
+// shutdownTimeout is how long in-flight requests get to finish after SIGINT or SIGTERM.
+const shutdownTimeout = 20 * time.Second
+
 func main() {
-	router := gin.Default()
+	configPath := flag.String("config", "", "YAML config file; defaults to $CONFIG_FILE")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	flag.Parse()
+
+	cfg, err := configs.Load(*configPath)
+	if *printConfig {
+		out, yamlErr := cfg.Redacted().YAML()
+		if yamlErr != nil {
+			log.Fatal(yamlErr)
+		}
+		os.Stdout.Write(out)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *printConfig {
+		return
+	}
+	configs.Use(cfg)
+
+	level, _ := logging.ParseLevel(cfg.Log.Level)
+	logging.Configure(os.Stderr, cfg.Log.Format, level)
+	logger := logging.Default()
+	if cfg.SecretKey == "" {
+		logger.Warn("SECRET_KEY is not set: tokens are signed with the development secret")
+	}
+
+	exporter, err := spanExporter(cfg.Tracing)
+	if err != nil {
+		logger.Error("error configuring tracing", "error", err)
+		os.Exit(1)
+	}
+	if exporter != nil {
+		tracing.Configure(exporter, tracing.Options{ServiceName: cfg.Tracing.ServiceName, SampleRatio: cfg.Tracing.SampleRatio})
+	}
+
+	router := gin.New()
+	// gin otherwise believes X-Forwarded-For from any peer, letting clients choose the address that rate
+	// limiting and vote brigading checks see.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Error("invalid trusted proxies", "error", err)
+		os.Exit(2)
+	}
+	router.Use(middleware.RequestID(), middleware.Tracing(), middleware.AccessLog(), middleware.Metrics())
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		logging.FromContext(c.Request.Context()).Error("panic", "recovered", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.INTERNAL_ERROR, "Internal server error"))
+	}))
 	router.Use(middleware.SecurityHeaders())
-	router.Use(middleware.BodySizeLimit(1 << 20))
-	router.Use(middleware.RateLimit(120, time.Minute))
+	router.Use(middleware.BodySizeLimit(cfg.MaxBodyBytes))
+	router.Use(middleware.RateLimit(cfg.RateLimit.Requests, cfg.RateLimit.Window))
 
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     allowedOrigins(),
+		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.RequestIDHeader, "traceparent", "tracestate"},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader, "traceparent"},
 		AllowCredentials: true,
 	}))
 
-	configs.ConnectDB()
-	configs.EnsureIndexes()
+	if cfg.Storage == configs.StorageMemory {
+		logger.Warn("STORAGE=memory: data is kept in memory and lost on exit")
+		store.UseMemory()
+	} else {
+		configs.ConnectDB()
+		db := configs.Database()
+		if cfg.Mongo.MigrateOnStart {
+			migrate(db)
+		}
+		store.UseMongo(db)
+		health.SetChecks(map[string]health.Check{
+			"mongo":      configs.PingDB,
+			"migrations": migrations.Ready(db),
+		})
+	}
 
 	routes.SetupRoutes(router)
 
-	log.Fatal(router.Run(":" + port()))
-}
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 2)
+	go func() {
+		logger.Info("listening", "addr", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		metricsServer = metrics.NewServer(cfg.MetricsAddr)
+		go func() {
+			logger.Info("serving metrics", "addr", metricsServer.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- err
+			}
+		}()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err := <-serveErr:
+		logger.Error("server error", "error", err)
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+	stop()
 
-func port() string {
-	if value := os.Getenv("PORT"); value != "" {
-		return value
+	logger.Info("shutting down: draining in-flight requests")
+	health.ShutDown()
+	// Keep serving while load balancers notice the failing readiness check and stop sending new requests.
+	time.Sleep(cfg.ShutdownDrainDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("error draining requests", "error", err)
 	}
-	return "8080"
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server error", "error", err)
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("error stopping metrics server", "error", err)
+		}
+	}
+	if err := configs.DisconnectDB(shutdownCtx); err != nil {
+		logger.Error("error disconnecting from MongoDB", "error", err)
+	}
+	if err := tracing.Shutdown(shutdownCtx); err != nil {
+		logger.Error("error flushing spans", "error", err)
+	}
+	logger.Info("shutdown complete")
 }
 
-func allowedOrigins() []string {
-	value := os.Getenv("ALLOWED_ORIGINS")
-	if value == "" {
-		return []string{"http://localhost:4200"}
+// spanExporter returns the exporter selected by cfg, or nil when tracing is off.
+func spanExporter(cfg configs.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		return tracing.NewOTLPExporter(context.Background(), cfg.OTLPEndpoint, cfg.OTLPHeaders)
+	case "stdout":
+		return tracing.NewStdoutExporter(os.Stdout)
 	}
+	return nil, nil
+}
+
+// migrate applies pending migrations. Failures are logged rather than fatal: readiness reports
+// the pending migrations until they are applied, by a later start or by redditctl migrate up.
+func migrate(db *mongo.Database) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
 
-	origins := strings.Split(value, ",")
-	for i := range origins {
-		origins[i] = strings.TrimSpace(origins[i])
+	if _, err := migrations.Up(ctx, db); err != nil {
+		logging.Default().Error("error applying migrations", "error", err)
 	}
-	return origins
 }
@@ -0,0 +1,213 @@
+// Package memstore holds the building blocks of the in-memory repositories used to run the API without MongoDB.
+package memstore
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Record is anything stored in a Table. IDs order records by creation, like MongoDB object IDs.
+type Record interface {
+	GetID() primitive.ObjectID
+}
+
+// Table is a concurrency-safe set of records keyed by ID. Records are stored and returned by value,
+// so callers never share state with the table.
+type Table[T Record] struct {
+	mu   sync.RWMutex
+	rows map[primitive.ObjectID]T
+}
+
+func NewTable[T Record]() *Table[T] {
+	return &Table[T]{rows: map[primitive.ObjectID]T{}}
+}
+
+// Insert stores row. It fails with common.ErrDuplicate when a record with the same ID exists,
+// or when conflicts is set and matches an existing record.
+func (t *Table[T]) Insert(row T, conflicts func(T) bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rows[row.GetID()]; ok {
+		return common.ErrDuplicate
+	}
+	if conflicts != nil {
+		for _, existing := range t.rows {
+			if conflicts(existing) {
+				return common.ErrDuplicate
+			}
+		}
+	}
+	t.rows[row.GetID()] = row
+	return nil
+}
+
+// Get returns the record with id, or common.ErrNotFound.
+func (t *Table[T]) Get(id primitive.ObjectID) (T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	row, ok := t.rows[id]
+	if !ok {
+		return row, common.ErrNotFound
+	}
+	return row, nil
+}
+
+// Find returns the records matching match, oldest first. A nil match returns every record.
+func (t *Table[T]) Find(match func(T) bool) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows := []T{}
+	for _, row := range t.rows {
+		if match == nil || match(row) {
+			rows = append(rows, row)
+		}
+	}
+	sortByID(rows)
+	return rows
+}
+
+// First returns the oldest record matching match, or common.ErrNotFound.
+func (t *Table[T]) First(match func(T) bool) (T, error) {
+	rows := t.Find(match)
+	if len(rows) == 0 {
+		var zero T
+		return zero, common.ErrNotFound
+	}
+	return rows[0], nil
+}
+
+func (t *Table[T]) Count(match func(T) bool) int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var count int64
+	for _, row := range t.rows {
+		if match == nil || match(row) {
+			count++
+		}
+	}
+	return count
+}
+
+// Update applies change to every record matching match and returns the updated records, oldest first.
+func (t *Table[T]) Update(match func(T) bool, change func(*T)) []T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	updated := []T{}
+	for id, row := range t.rows {
+		if match(row) {
+			change(&row)
+			t.rows[id] = row
+			updated = append(updated, row)
+		}
+	}
+	sortByID(updated)
+	return updated
+}
+
+// UpdateOne applies change to the oldest record matching match and returns it, or common.ErrNotFound.
+func (t *Table[T]) UpdateOne(match func(T) bool, change func(*T)) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	row, err := t.oldest(match)
+	if err != nil {
+		return row, err
+	}
+	change(&row)
+	t.rows[row.GetID()] = row
+	return row, nil
+}
+
+// Upsert applies change to the oldest record matching match, or stores the record returned by create
+// when none does. It returns the stored record and whether it was created.
+func (t *Table[T]) Upsert(match func(T) bool, create func() T, change func(*T)) (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	row, err := t.oldest(match)
+	created := err != nil
+	if created {
+		row = create()
+	}
+	change(&row)
+	t.rows[row.GetID()] = row
+	return row, created
+}
+
+// Delete removes every record matching match and returns them, oldest first.
+func (t *Table[T]) Delete(match func(T) bool) []T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	deleted := []T{}
+	for id, row := range t.rows {
+		if match(row) {
+			delete(t.rows, id)
+			deleted = append(deleted, row)
+		}
+	}
+	sortByID(deleted)
+	return deleted
+}
+
+func (t *Table[T]) oldest(match func(T) bool) (T, error) {
+	var found T
+	ok := false
+	for _, row := range t.rows {
+		if match(row) && (!ok || before(row.GetID(), found.GetID())) {
+			found, ok = row, true
+		}
+	}
+	if !ok {
+		return found, common.ErrNotFound
+	}
+	return found, nil
+}
+
+// Page returns the slice of rows a cursor-paginated query would: rows must be sorted oldest first,
+// and up to page.Limit+1 rows after the cursor are returned so common.ApplyCursorPage can tell whether more exist.
+// Newest-first listings pass descending, which reverses the order and reads the cursor as "older than".
+func Page[T Record](rows []T, page common.PageRequest, descending bool) []T {
+	ordered := make([]T, 0, len(rows))
+	if descending {
+		for i := len(rows) - 1; i >= 0; i-- {
+			ordered = append(ordered, rows[i])
+		}
+	} else {
+		ordered = append(ordered, rows...)
+	}
+
+	result := []T{}
+	for _, row := range ordered {
+		if page.HasAfter {
+			id := row.GetID()
+			if (descending && !before(id, page.AfterID)) || (!descending && !before(page.AfterID, id)) {
+				continue
+			}
+		}
+		if page.Limit > 0 && int64(len(result)) > page.Limit {
+			break
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+func sortByID[T Record](rows []T) {
+	sort.Slice(rows, func(i, j int) bool {
+		return before(rows[i].GetID(), rows[j].GetID())
+	})
+}
+
+func before(a primitive.ObjectID, b primitive.ObjectID) bool {
+	return bytes.Compare(a[:], b[:]) < 0
+}
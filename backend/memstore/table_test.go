@@ -0,0 +1,79 @@
+package memstore
+
+import (
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type row struct {
+	ID   primitive.ObjectID
+	Name string
+}
+
+func (r row) GetID() primitive.ObjectID {
+	return r.ID
+}
+
+// rows returns n rows whose IDs increase in order.
+func rows(n int) []row {
+	result := make([]row, n)
+	for i := range result {
+		var id primitive.ObjectID
+		id[len(id)-1] = byte(i + 1)
+		result[i] = row{ID: id, Name: string(rune('a' + i))}
+	}
+	return result
+}
+
+func TestInsertRejectsDuplicatesAndConflicts(t *testing.T) {
+	table := NewTable[row]()
+	data := rows(2)
+	sameName := func(existing row) bool { return existing.Name == "x" }
+
+	assert.NoError(t, table.Insert(row{ID: data[0].ID, Name: "x"}, nil))
+	assert.ErrorIs(t, table.Insert(row{ID: data[0].ID, Name: "y"}, nil), common.ErrDuplicate)
+	assert.ErrorIs(t, table.Insert(row{ID: data[1].ID, Name: "x"}, sameName), common.ErrDuplicate)
+
+	_, err := table.Get(data[1].ID)
+	assert.ErrorIs(t, err, common.ErrNotFound)
+}
+
+func TestFindReturnsCopiesOldestFirst(t *testing.T) {
+	table := NewTable[row]()
+	data := rows(3)
+	for i := len(data) - 1; i >= 0; i-- {
+		assert.NoError(t, table.Insert(data[i], nil))
+	}
+
+	found := table.Find(nil)
+	assert.Equal(t, data, found)
+
+	found[0].Name = "changed"
+	stored, _ := table.Get(data[0].ID)
+	assert.Equal(t, "a", stored.Name)
+}
+
+func TestUpsertCreatesOnceThenUpdates(t *testing.T) {
+	table := NewTable[row]()
+	data := rows(2)
+	named := func(existing row) bool { return existing.Name == "a" }
+	create := func() row { return data[0] }
+
+	_, created := table.Upsert(named, create, func(r *row) {})
+	assert.True(t, created)
+	updated, created := table.Upsert(named, func() row { return data[1] }, func(r *row) { r.Name = "a" })
+	assert.False(t, created)
+	assert.Equal(t, data[0].ID, updated.ID)
+	assert.Equal(t, int64(1), table.Count(nil))
+}
+
+func TestPageFollowsCursorInBothDirections(t *testing.T) {
+	data := rows(5)
+
+	assert.Equal(t, []row{data[4], data[3], data[2]}, Page(data, common.PageRequest{Limit: 2}, true))
+	assert.Equal(t, []row{data[1], data[0]}, Page(data, common.PageRequest{Limit: 2, AfterID: data[2].ID, HasAfter: true}, true))
+	assert.Equal(t, []row{data[3], data[4]}, Page(data, common.PageRequest{Limit: 2, AfterID: data[2].ID, HasAfter: true}, false))
+}
@@ -0,0 +1,110 @@
+package messages
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// storedConversation keys conversations by their own ID; Conversation.GetID is the inbox cursor.
+type storedConversation struct {
+	Conversation
+}
+
+func (c storedConversation) GetID() primitive.ObjectID {
+	return c.ID
+}
+
+type memoryRepository struct {
+	conversations *memstore.Table[storedConversation]
+	messages      *memstore.Table[Message]
+}
+
+// NewMemoryRepository keeps direct messages in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		conversations: memstore.NewTable[storedConversation](),
+		messages:      memstore.NewTable[Message](),
+	}
+}
+
+func (r *memoryRepository) UpsertConversation(ctx context.Context, conversation Conversation) (Conversation, error) {
+	stored, _ := r.conversations.Upsert(
+		func(stored storedConversation) bool { return stored.Key == conversation.Key },
+		func() storedConversation { return storedConversation{conversation} },
+		func(stored *storedConversation) {
+			stored.LastMessageID = conversation.LastMessageID
+			stored.LastMessageAt = conversation.LastMessageAt
+			stored.LastMessageSender = conversation.LastMessageSender
+			stored.LastMessagePreview = conversation.LastMessagePreview
+		},
+	)
+	return stored.Conversation, nil
+}
+
+func (r *memoryRepository) FindConversation(ctx context.Context, id primitive.ObjectID, participant string) (Conversation, error) {
+	stored, err := r.conversations.Get(id)
+	if err != nil || !stored.hasParticipant(participant) {
+		return Conversation{}, common.ErrNotFound
+	}
+	return stored.Conversation, nil
+}
+
+func (r *memoryRepository) Inbox(ctx context.Context, username string, page common.PageRequest) ([]Conversation, error) {
+	conversations := []Conversation{}
+	for _, stored := range r.conversations.Find(func(stored storedConversation) bool { return stored.hasParticipant(username) }) {
+		conversations = append(conversations, stored.Conversation)
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		a, b := conversations[i].LastMessageID, conversations[j].LastMessageID
+		return a.Hex() < b.Hex()
+	})
+	return memstore.Page(conversations, page, true), nil
+}
+
+func (r *memoryRepository) InsertMessage(ctx context.Context, message Message) error {
+	return r.messages.Insert(message, nil)
+}
+
+func (r *memoryRepository) ListMessages(ctx context.Context, conversationID primitive.ObjectID, page common.PageRequest) ([]Message, error) {
+	rows := r.messages.Find(func(message Message) bool { return message.ConversationID == conversationID })
+	return memstore.Page(rows, page, true), nil
+}
+
+func (r *memoryRepository) UnreadCounts(ctx context.Context, recipient string, conversationIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	wanted := make(map[primitive.ObjectID]bool, len(conversationIDs))
+	for _, id := range conversationIDs {
+		wanted[id] = true
+	}
+
+	counts := map[primitive.ObjectID]int64{}
+	for _, message := range r.messages.Find(func(message Message) bool {
+		return wanted[message.ConversationID] && message.Recipient == recipient && message.ReadAt == nil
+	}) {
+		counts[message.ConversationID]++
+	}
+	return counts, nil
+}
+
+func (r *memoryRepository) MarkRead(ctx context.Context, conversationID primitive.ObjectID, recipient string, at time.Time) (int64, error) {
+	updated := r.messages.Update(func(message Message) bool {
+		return message.ConversationID == conversationID && message.Recipient == recipient && message.ReadAt == nil
+	}, func(message *Message) {
+		readAt := at
+		message.ReadAt = &readAt
+	})
+	return int64(len(updated)), nil
+}
+
+func (c storedConversation) hasParticipant(username string) bool {
+	for _, participant := range c.Participants {
+		if participant == username {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package messages
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// previewLength bounds how much of the latest message is copied onto its conversation for the inbox.
+const previewLength = 140
+
+// Conversation is the thread between two users. Key is the sorted participant pair and is unique.
+type Conversation struct {
+	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Key                string             `json:"-" bson:"key,omitempty"`
+	Participants       []string           `json:"participants" bson:"participants,omitempty"`
+	LastMessageID      primitive.ObjectID `json:"last_message_id" bson:"last_message_id,omitempty"`
+	LastMessageAt      time.Time          `json:"last_message_at" bson:"last_message_at,omitempty"`
+	LastMessageSender  string             `json:"last_message_sender" bson:"last_message_sender,omitempty"`
+	LastMessagePreview string             `json:"last_message_preview" bson:"last_message_preview,omitempty"`
+	UnreadCount        int64              `json:"unread_count" bson:"-"`
+	CreationDate       time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
+}
+
+// GetID returns the inbox cursor position: conversations are ordered by their latest message.
+func (c Conversation) GetID() primitive.ObjectID {
+	return c.LastMessageID
+}
+
+// Message is one direct message. ReadAt is set once the recipient has opened the conversation.
+type Message struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ConversationID primitive.ObjectID `json:"conversation_id" bson:"conversation_id,omitempty"`
+	Sender         string             `json:"sender" bson:"sender,omitempty"`
+	Recipient      string             `json:"recipient" bson:"recipient,omitempty"`
+	Text           string             `json:"text" bson:"text,omitempty"`
+	CreationDate   time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
+	ReadAt         *time.Time         `json:"read_at,omitempty" bson:"read_at,omitempty"`
+}
+
+func (m Message) GetID() primitive.ObjectID {
+	return m.ID
+}
+
+type SendMessageRequest struct {
+	To   string `json:"to" binding:"required"`
+	Text string `json:"text" binding:"required,min=1,max=10000"`
+}
+
+func conversationKey(a string, b string) (string, []string) {
+	participants := []string{a, b}
+	sort.Strings(participants)
+	return strings.Join(participants, "\x00"), participants
+}
+
+func preview(text string) string {
+	runes := []rune(text)
+	if len(runes) <= previewLength {
+		return text
+	}
+	return string(runes[:previewLength])
+}
+
+// SendMessageResponse is the data of a sent direct message.
+type SendMessageResponse struct {
+	Message       string  `json:"message"`
+	DirectMessage Message `json:"direct_message"`
+}
+
+// ConversationListResponse is the data of the caller's inbox.
+type ConversationListResponse struct {
+	Conversations []Conversation    `json:"conversations"`
+	Pagination    common.Pagination `json:"pagination"`
+}
+
+// ConversationResponse is the data of one conversation and a page of its messages.
+type ConversationResponse struct {
+	Conversation Conversation      `json:"conversation"`
+	Messages     []Message         `json:"messages"`
+	Pagination   common.Pagination `json:"pagination"`
+}
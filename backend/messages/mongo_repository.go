@@ -0,0 +1,135 @@
+package messages
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	conversations *mongo.Collection
+	messages      *mongo.Collection
+}
+
+// NewMongoRepository stores direct messages in the conversations and messages collections of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{
+		conversations: db.Collection("conversations"),
+		messages:      db.Collection("messages"),
+	}
+}
+
+func (r *mongoRepository) UpsertConversation(ctx context.Context, conversation Conversation) (Conversation, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"last_message_id":      conversation.LastMessageID,
+			"last_message_at":      conversation.LastMessageAt,
+			"last_message_sender":  conversation.LastMessageSender,
+			"last_message_preview": conversation.LastMessagePreview,
+		},
+		"$setOnInsert": bson.M{
+			"_id":           conversation.ID,
+			"key":           conversation.Key,
+			"participants":  conversation.Participants,
+			"creation_date": conversation.CreationDate,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var stored Conversation
+	err := r.conversations.FindOneAndUpdate(ctx, bson.M{"key": conversation.Key}, update, opts).Decode(&stored)
+	return stored, err
+}
+
+func (r *mongoRepository) FindConversation(ctx context.Context, id primitive.ObjectID, participant string) (Conversation, error) {
+	var conversation Conversation
+	err := r.conversations.FindOne(ctx, bson.M{"_id": id, "participants": participant}).Decode(&conversation)
+	return conversation, common.MongoError(err)
+}
+
+func (r *mongoRepository) Inbox(ctx context.Context, username string, page common.PageRequest) ([]Conversation, error) {
+	filter := bson.M{"participants": username}
+	if page.HasAfter {
+		filter["last_message_id"] = bson.M{"$lt": page.AfterID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "last_message_id", Value: -1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.conversations.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Conversation
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) InsertMessage(ctx context.Context, message Message) error {
+	_, err := r.messages.InsertOne(ctx, message)
+	return err
+}
+
+func (r *mongoRepository) ListMessages(ctx context.Context, conversationID primitive.ObjectID, page common.PageRequest) ([]Message, error) {
+	filter := bson.M{"conversation_id": conversationID}
+	if page.HasAfter {
+		filter["_id"] = bson.M{"$lt": page.AfterID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.messages.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Message
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) UnreadCounts(ctx context.Context, recipient string, conversationIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"conversation_id": bson.M{"$in": conversationIDs}, "recipient": recipient, "read_at": bson.M{"$exists": false}}},
+		{"$group": bson.M{"_id": "$conversation_id", "count": bson.M{"$sum": 1}}},
+	}
+	cursor, err := r.messages.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	byConversation := make(map[primitive.ObjectID]int64, len(counts))
+	for _, count := range counts {
+		byConversation[count.ID] = count.Count
+	}
+	return byConversation, nil
+}
+
+func (r *mongoRepository) MarkRead(ctx context.Context, conversationID primitive.ObjectID, recipient string, at time.Time) (int64, error) {
+	filter := bson.M{"conversation_id": conversationID, "recipient": recipient, "read_at": bson.M{"$exists": false}}
+	result, err := r.messages.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"read_at": at}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
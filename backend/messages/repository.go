@@ -0,0 +1,39 @@
+package messages
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repository stores conversations and their messages.
+type Repository interface {
+	// UpsertConversation records the latest message on the conversation with the same Key, creating it
+	// from conversation when there is none yet, and returns the stored conversation.
+	UpsertConversation(ctx context.Context, conversation Conversation) (Conversation, error)
+	// FindConversation returns the conversation with id if participant takes part in it, or common.ErrNotFound.
+	FindConversation(ctx context.Context, id primitive.ObjectID, participant string) (Conversation, error)
+	// Inbox returns the conversations of username, most recently active first, reading up to page.Limit+1 of them.
+	// The cursor is a last message ID.
+	Inbox(ctx context.Context, username string, page common.PageRequest) ([]Conversation, error)
+	InsertMessage(ctx context.Context, message Message) error
+	// ListMessages returns the messages of a conversation, newest first, reading up to page.Limit+1 of them.
+	ListMessages(ctx context.Context, conversationID primitive.ObjectID, page common.PageRequest) ([]Message, error)
+	// UnreadCounts counts the unread messages recipient has in each of the conversations, omitting those with none.
+	UnreadCounts(ctx context.Context, recipient string, conversationIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error)
+	// MarkRead sets the read receipt on the unread messages recipient has in a conversation and returns how many there were.
+	MarkRead(ctx context.Context, conversationID primitive.ObjectID, recipient string, at time.Time) (int64, error)
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+func repo() Repository {
+	return repository.Get()
+}
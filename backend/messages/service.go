@@ -0,0 +1,188 @@
+package messages
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/blocks"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SendMessage sends a direct message, starting the conversation between the two users if needed.
+func SendMessage(c *gin.Context) {
+	var req SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+
+	sender := c.GetString("username")
+	if req.To == sender {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_REQUEST_BODY, "You cannot message yourself"))
+		return
+	}
+
+	exists, err := users.Exists(c.Request.Context(), req.To)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding user", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to send message"))
+		return
+	}
+	if !exists {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.USER_NOT_FOUND, "User not found"))
+		return
+	}
+
+	blocked, err := blocks.IsBlocked(c.Request.Context(), req.To, sender)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error checking blocks", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to send message"))
+		return
+	}
+	if blocked {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.USER_BLOCKED, "This user is not accepting messages from you"))
+		return
+	}
+
+	now := time.Now()
+	messageID := primitive.NewObjectID()
+	key, participants := conversationKey(sender, req.To)
+
+	conversation, err := repo().UpsertConversation(c.Request.Context(), Conversation{
+		ID:                 primitive.NewObjectID(),
+		Key:                key,
+		Participants:       participants,
+		LastMessageID:      messageID,
+		LastMessageAt:      now,
+		LastMessageSender:  sender,
+		LastMessagePreview: preview(req.Text),
+		CreationDate:       now,
+	})
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error saving conversation", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to send message"))
+		return
+	}
+
+	message := Message{
+		ID:             messageID,
+		ConversationID: conversation.ID,
+		Sender:         sender,
+		Recipient:      req.To,
+		Text:           req.Text,
+		CreationDate:   now,
+	}
+	if err := repo().InsertMessage(c.Request.Context(), message); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error creating message", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to send message"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, SendMessageResponse{Message: "Message sent successfully", DirectMessage: message})
+}
+
+// GetInbox retrieves a bounded page of the caller's conversations, most recently active first.
+func GetInbox(c *gin.Context) {
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	username := c.GetString("username")
+	results, err := repo().Inbox(c.Request.Context(), username, page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding conversations", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve conversations"))
+		return
+	}
+
+	conversations, pagination := common.ApplyCursorPage(results, page.Limit)
+	if err := fillUnreadCounts(c, username, conversations); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error counting unread messages", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve conversations"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, ConversationListResponse{Conversations: conversations, Pagination: pagination})
+}
+
+func fillUnreadCounts(c *gin.Context, username string, conversations []Conversation) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, len(conversations))
+	for i := range conversations {
+		ids[i] = conversations[i].ID
+	}
+
+	byConversation, err := repo().UnreadCounts(c.Request.Context(), username, ids)
+	if err != nil {
+		return err
+	}
+	for i := range conversations {
+		conversations[i].UnreadCount = byConversation[conversations[i].ID]
+	}
+	return nil
+}
+
+// GetConversation retrieves a bounded page of a conversation's messages, newest first.
+func GetConversation(c *gin.Context) {
+	conversation, ok := findParticipantConversation(c)
+	if !ok {
+		return
+	}
+
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	results, err := repo().ListMessages(c.Request.Context(), conversation.ID, page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding messages", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve messages"))
+		return
+	}
+
+	messages, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, ConversationResponse{Conversation: conversation, Messages: messages, Pagination: pagination})
+}
+
+// MarkConversationRead sets the read receipt on every message the caller has received in a conversation.
+func MarkConversationRead(c *gin.Context) {
+	conversation, ok := findParticipantConversation(c)
+	if !ok {
+		return
+	}
+
+	updated, err := repo().MarkRead(c.Request.Context(), conversation.ID, c.GetString("username"), time.Now())
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error updating messages", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to mark messages as read"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.UpdatedResponse{Message: "Conversation marked as read", Updated: updated})
+}
+
+func findParticipantConversation(c *gin.Context) (Conversation, bool) {
+	conversationID, err := primitive.ObjectIDFromHex(c.Param("conversationId"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid conversation ID"))
+		return Conversation{}, false
+	}
+
+	conversation, err := repo().FindConversation(c.Request.Context(), conversationID, c.GetString("username"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.CONVERSATION_NOT_FOUND, "Conversation not found"))
+		return Conversation{}, false
+	}
+	return conversation, true
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the metrics every package registers with promauto, plus the Go runtime and process
+// metrics of the default Prometheus registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// NewServer returns the listener Prometheus scrapes at addr. It serves only /metrics and is kept off
+// the public API port, so request counts and latencies are not readable by clients and scrapes are not
+// rate limited.
+func NewServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerExposesRegisteredMetrics(t *testing.T) {
+	requests := promauto.NewCounterVec(prometheus.CounterOpts{Name: "test_requests_total", Help: "Requests."}, []string{"route"})
+	requests.WithLabelValues("/posts/:postId").Add(3)
+
+	w := httptest.NewRecorder()
+	NewServer("localhost:0").Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `test_requests_total{route="/posts/:postId"} 3`)
+	assert.Contains(t, w.Body.String(), "# TYPE go_goroutines gauge")
+
+	w = httptest.NewRecorder()
+	NewServer("localhost:0").Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "the metrics listener serves nothing else")
+}
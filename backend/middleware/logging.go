@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs taken from clients, which end up in every log line of the request.
+const maxRequestIDLength = 128
+
+// RequestID keeps the X-Request-ID a proxy or client sent, or generates one, echoes it in the response
+// and gives the request a logger carrying it with the method and route.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+
+		logger := logging.Default().With("request_id", id, "method", c.Request.Method, "route", routeLabel(c))
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), logger))
+		c.Next()
+	}
+}
+
+// AccessLog writes one entry per request once it has been handled, at warn level for 5xx responses.
+// It must run after RequestID, and the entry carries the username when the route authenticates.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logger := logging.FromContext(c.Request.Context()).With(
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		)
+		if len(c.Errors) > 0 {
+			logger = logger.With("errors", c.Errors.String())
+		}
+		if c.Writer.Status() >= 500 {
+			logger.Warn("request")
+			return
+		}
+		logger.Info("request")
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	var out bytes.Buffer
+	logging.Configure(&out, logging.FormatJSON, logging.LevelInfo)
+	defer logging.Configure(&bytes.Buffer{}, logging.FormatText, logging.LevelInfo)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), AccessLog())
+	router.GET("/posts/:postId", func(c *gin.Context) {
+		c.Set("username", "mary")
+		ctx := c.Request.Context()
+		c.Request = c.Request.WithContext(logging.NewContext(ctx, logging.FromContext(ctx).With("username", "mary")))
+		logging.FromContext(c.Request.Context()).Info("handled")
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/posts/123", nil)
+	req.Header.Set(RequestIDHeader, "from-the-proxy")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "from-the-proxy", w.Header().Get(RequestIDHeader))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var handled, access map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &handled))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &access))
+
+	assert.Equal(t, "from-the-proxy", handled["request_id"])
+	assert.Equal(t, "/posts/:postId", handled["route"])
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "from-the-proxy", access["request_id"])
+	assert.Equal(t, "mary", access["username"])
+	assert.Equal(t, "/posts/123", access["path"])
+	assert.Equal(t, float64(http.StatusNoContent), access["status"])
+	assert.Contains(t, access, "latency_ms")
+}
+
+func TestRequestIDIsGeneratedForMissingOrUnsafeHeaders(t *testing.T) {
+	logging.Configure(&bytes.Buffer{}, logging.FormatText, logging.LevelInfo)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, header := range []string{"", "has spaces", strings.Repeat("x", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, header)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		id := w.Header().Get(RequestIDHeader)
+		assert.Len(t, id, 32, "header %q", header)
+		assert.NotEqual(t, header, id)
+	}
+}
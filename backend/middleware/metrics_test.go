@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsCountRequestsAndRateLimitRejections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics(), RateLimit(1, time.Minute))
+	router.GET("/limited/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	before := testutil.ToFloat64(rateLimited.WithLabelValues(http.MethodGet, "/limited/:id"))
+	for i := 0; i < 3; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/limited/1", nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+	assert.Equal(t, before+2, testutil.ToFloat64(rateLimited.WithLabelValues(http.MethodGet, "/limited/:id")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/limited/:id", "200")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/limited/:id", "429")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "unmatched", "404")))
+
+	var duration dto.Metric
+	require.NoError(t, httpRequestDuration.WithLabelValues(http.MethodGet, "/limited/:id").(prometheus.Histogram).Write(&duration))
+	assert.Equal(t, uint64(3), duration.GetHistogram().GetSampleCount())
+}
@@ -69,9 +69,9 @@ func RateLimit(maxRequests int, window time.Duration) gin.HandlerFunc {
 			if remaining < 0 {
 				remaining = window
 			}
+			rateLimited.WithLabelValues(c.Request.Method, routeLabel(c)).Inc()
 			c.Header("Retry-After", strconv.Itoa(int(remaining.Seconds())+1))
-			common.RespondWithJSON(c, http.StatusTooManyRequests, common.FORBIDDEN, gin.H{"error": "Too many requests"})
-			c.Abort()
+			common.RespondWithError(c, common.NewAPIError(http.StatusTooManyRequests, common.TOO_MANY_REQUESTS, "Too many requests"))
 			return
 		}
 
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span per request, continuing the trace of an incoming W3C traceparent header.
+// Spans started from the request context, such as MongoDB commands, become its children. The response
+// carries the span's traceparent so a client can look the request up, and the request logger gets
+// the trace_id. It must run after RequestID.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !tracing.Enabled() {
+			c.Next()
+			return
+		}
+
+		route := routeLabel(c)
+		ctx := tracing.Propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracing.Tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+				attribute.String("request_id", c.GetString("request_id")),
+			),
+		)
+		defer span.End()
+
+		tracing.Propagator.Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+		ctx = logging.NewContext(ctx, logging.FromContext(ctx).With("trace_id", span.SpanContext().TraceID().String()))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if username := c.GetString("username"); username != "" {
+			span.SetAttributes(attribute.String("enduser.id", username))
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
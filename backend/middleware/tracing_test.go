@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error { return nil }
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	var out bytes.Buffer
+	logging.Configure(&out, logging.FormatJSON, logging.LevelInfo)
+	defer logging.Configure(&bytes.Buffer{}, logging.FormatText, logging.LevelInfo)
+	exporter := &recordingExporter{}
+	tracing.Configure(exporter, tracing.Options{ServiceName: "simple-reddit-backend", SampleRatio: 1})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), Tracing())
+	router.GET("/posts/:postId", func(c *gin.Context) {
+		c.Set("username", "mary")
+		_, span := tracing.Tracer().Start(c.Request.Context(), "find simple-reddit.posts", trace.WithSpanKind(trace.SpanKindClient))
+		span.End()
+		logging.FromContext(c.Request.Context()).Info("handled")
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/posts/123", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.NoError(t, tracing.Shutdown(context.Background()))
+
+	require.Len(t, exporter.spans, 2)
+	child, server := exporter.spans[0], exporter.spans[1]
+	assert.Equal(t, "GET /posts/:postId", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, codes.Error, server.Status().Code)
+	assert.Contains(t, server.Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	assert.Contains(t, server.Attributes(), attribute.String("request_id", "req-1"))
+	assert.Contains(t, server.Attributes(), attribute.String("enduser.id", "mary"))
+
+	sc := server.SpanContext()
+	assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", w.Header().Get("traceparent"))
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(out.String())), &line))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line["trace_id"])
+}
+
+func TestTracingDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), Tracing())
+	router.GET("/", func(c *gin.Context) {
+		assert.False(t, trace.SpanContextFromContext(c.Request.Context()).IsValid())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("traceparent"))
+}
@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RecountCounters recomputes the denormalized counters on posts and comments from the documents they
+// summarize: vote counters from the votes that are not suspicious, comments_count from the visible
+// comments of each post and crossposts_count from the crossposts pointing at each post.
+//
+// Votes and comments written while it runs can be miscounted, so run it while traffic is low.
+func RecountCounters(ctx context.Context, db *mongo.Database) error {
+	posts, comments, votes := db.Collection("posts"), db.Collection("comments"), db.Collection("votes")
+
+	if err := recount(ctx, votes, voteCounts("post"), posts, "up_votes", "down_votes"); err != nil {
+		return fmt.Errorf("post votes: %w", err)
+	}
+	if err := recount(ctx, votes, voteCounts("comment"), comments, "up_votes", "down_votes"); err != nil {
+		return fmt.Errorf("comment votes: %w", err)
+	}
+
+	visibleComments := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"mod_status": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$post_id", "comments_count": bson.M{"$sum": 1}}}},
+	}
+	if err := recount(ctx, comments, visibleComments, posts, "comments_count"); err != nil {
+		return fmt.Errorf("comments_count: %w", err)
+	}
+
+	crossposts := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"crosspost_parent.id": bson.M{"$exists": true}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$crosspost_parent.id", "crossposts_count": bson.M{"$sum": 1}}}},
+	}
+	if err := recount(ctx, posts, crossposts, posts, "crossposts_count"); err != nil {
+		return fmt.Errorf("crossposts_count: %w", err)
+	}
+	return nil
+}
+
+func voteCounts(targetType string) mongo.Pipeline {
+	count := func(value int) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$value", value}}, 1, 0}}}
+	}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"target_type": targetType, "suspicious": bson.M{"$ne": true}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$target_id", "up_votes": count(1), "down_votes": count(-1)}}},
+	}
+}
+
+const recountBatchSize = 500
+
+// recount sets fields on the documents of target from the output of pipeline on source, which must group
+// by the target's _id and name its accumulators after fields, then zeroes them on the documents the
+// pipeline left out. Counters are never reset ahead of their new value, so readers see the old or the
+// new count, and documents whose counts are already right are not written at all.
+func recount(ctx context.Context, source *mongo.Collection, pipeline mongo.Pipeline, target *mongo.Collection, fields ...string) error {
+	var batch []mongo.WriteModel
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := target.BulkWrite(ctx, batch)
+		batch = batch[:0]
+		return err
+	}
+	queue := func(id interface{}, counts bson.M) error {
+		differs := make(bson.A, 0, len(counts))
+		for field, value := range counts {
+			differs = append(differs, bson.M{field: bson.M{"$ne": value}})
+		}
+		filter := bson.M{"_id": id, "$or": differs}
+		batch = append(batch, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$set": counts}))
+		if len(batch) == recountBatchSize {
+			return flush()
+		}
+		return nil
+	}
+
+	counted := map[interface{}]bool{}
+	cursor, err := source.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var counts bson.M
+		if err := cursor.Decode(&counts); err != nil {
+			return err
+		}
+		id := counts["_id"]
+		delete(counts, "_id")
+		if id == nil {
+			continue
+		}
+		counted[id] = true
+		if err := queue(id, counts); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	// Documents the pipeline did not mention have nothing to count. Only those whose counters are not
+	// already 0, including those that lack them, are visited.
+	zero := bson.M{}
+	nonZero := make(bson.A, 0, len(fields))
+	for _, field := range fields {
+		zero[field] = 0
+		nonZero = append(nonZero, bson.M{field: bson.M{"$ne": 0}})
+	}
+	stale, err := target.Find(ctx, bson.M{"$or": nonZero}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer stale.Close(ctx)
+	for stale.Next(ctx) {
+		var doc struct {
+			ID interface{} `bson:"_id"`
+		}
+		if err := stale.Decode(&doc); err != nil {
+			return err
+		}
+		if counted[doc.ID] {
+			continue
+		}
+		if err := queue(doc.ID, zero); err != nil {
+			return err
+		}
+	}
+	if err := stale.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
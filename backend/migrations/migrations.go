@@ -0,0 +1,194 @@
+// Package migrations applies versioned schema and data changes to MongoDB, in order and at most once,
+// recording each applied version in the schema_migrations collection.
+//
+// To change the schema, append a Migration to All with the next version. Never renumber, edit or
+// remove a migration once it has shipped: databases that already applied it will not run it again.
+// Migrations must be safe to re-run after a partial failure, since a failed one is retried as a whole.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	collectionName = "schema_migrations"
+	lockCollection = "schema_migrations_lock"
+	// lockLease bounds how long a crashed process can keep others from migrating.
+	lockLease = 10 * time.Minute
+)
+
+// ErrLocked is returned by Up while another process is applying migrations.
+var ErrLocked = errors.New("another process is applying migrations")
+
+// Migration is one versioned change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+}
+
+// All lists every migration in version order.
+var All = []Migration{
+	{Version: 1, Name: "create_indexes", Up: configs.EnsureIndexes},
+	{Version: 2, Name: "backfill_counters", Up: RecountCounters},
+	{Version: 3, Name: "index_votes_under_review", Up: configs.EnsureIndexes},
+	{Version: 4, Name: "index_stickied_comments", Up: configs.EnsureIndexes},
+	{Version: 5, Name: "index_modlog_community_name", Up: configs.EnsureIndexes},
+}
+
+// Status describes one migration, known to this binary or recorded in the database.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+	// Unknown marks versions recorded in the database that this binary does not have, applied by a newer release.
+	Unknown bool
+}
+
+func (s Status) Applied() bool { return !s.AppliedAt.IsZero() }
+
+type record struct {
+	Version    int       `bson:"_id"`
+	Name       string    `bson:"name"`
+	AppliedAt  time.Time `bson:"applied_at"`
+	DurationMS int64     `bson:"duration_ms"`
+}
+
+// Statuses lists every migration in version order with when it was applied, if it was.
+func Statuses(ctx context.Context, db *mongo.Database) ([]Status, error) {
+	applied, err := appliedRecords(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(All))
+	for _, m := range All {
+		status := Status{Version: m.Version, Name: m.Name}
+		if r, ok := applied[m.Version]; ok {
+			status.AppliedAt = r.AppliedAt
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, r := range applied {
+		statuses = append(statuses, Status{Version: r.Version, Name: r.Name, AppliedAt: r.AppliedAt, Unknown: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Pending lists the migrations that have not been applied, in the order Up would apply them.
+func Pending(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	applied, err := appliedRecords(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range All {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in order and returns the ones it applied. It stops at the first
+// failure, leaving later migrations pending, and returns ErrLocked while another process holds the lock.
+func Up(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	release, err := acquireLock(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	pending, err := Pending(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logging.FromContext(ctx)
+	var done []Migration
+	for _, m := range pending {
+		logger.Info("applying migration", "version", m.Version, "name", m.Name)
+		start := time.Now()
+		if err := m.Up(ctx, db); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		r := record{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC(), DurationMS: time.Since(start).Milliseconds()}
+		if _, err := db.Collection(collectionName).InsertOne(ctx, r); err != nil {
+			return done, fmt.Errorf("recording migration %d %s: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Ready reports an error while migrations are pending, for readiness probes.
+func Ready(db *mongo.Database) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		pending, err := Pending(ctx, db)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d migrations pending, starting with %d %s", len(pending), pending[0].Version, pending[0].Name)
+		}
+		return nil
+	}
+}
+
+func appliedRecords(ctx context.Context, db *mongo.Database) (map[int]record, error) {
+	cursor, err := db.Collection(collectionName).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// acquireLock takes a lease on the single lock document, so that servers starting together do not
+// apply the same migration twice. The returned function releases it.
+func acquireLock(ctx context.Context, db *mongo.Database) (func(), error) {
+	locks := db.Collection(lockCollection)
+	holder := lockHolder()
+	now := time.Now()
+
+	filter := bson.M{"_id": "migrations", "locked_until": bson.M{"$lt": now}}
+	update := bson.M{"$set": bson.M{"holder": holder, "locked_until": now.Add(lockLease)}}
+	_, err := locks.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = locks.DeleteOne(ctx, bson.M{"_id": "migrations", "holder": holder})
+	}, nil
+}
+
+func lockHolder() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s/%d/%d", host, os.Getpid(), time.Now().UnixNano())
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationsAreNumberedInOrder(t *testing.T) {
+	names := map[string]bool{}
+	for i, m := range All {
+		assert.Equal(t, i+1, m.Version, "versions start at 1 and have no gaps")
+		assert.NotEmpty(t, m.Name)
+		assert.False(t, names[m.Name], "duplicate name %s", m.Name)
+		assert.NotNil(t, m.Up, m.Name)
+		names[m.Name] = true
+	}
+}
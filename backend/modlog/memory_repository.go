@@ -0,0 +1,47 @@
+package modlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRepository struct {
+	entries *memstore.Table[Entry]
+}
+
+// NewMemoryRepository keeps entries in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{entries: memstore.NewTable[Entry]()}
+}
+
+func (r *memoryRepository) Insert(ctx context.Context, entry Entry) error {
+	return r.entries.Insert(entry, nil)
+}
+
+func (r *memoryRepository) List(ctx context.Context, query Query, page common.PageRequest) ([]Entry, error) {
+	rows := r.entries.Find(func(entry Entry) bool {
+		return (query.CommunityID.IsZero() || entry.CommunityID == query.CommunityID) &&
+			(query.CommunityName == "" || entry.CommunityName == query.CommunityName) &&
+			(query.Moderator == "" || entry.Moderator == query.Moderator) &&
+			(query.Action == "" || entry.Action == query.Action) &&
+			(query.From.IsZero() || !entry.CreationDate.Before(query.From)) &&
+			(query.To.IsZero() || !entry.CreationDate.After(query.To))
+	})
+	return memstore.Page(rows, page, true), nil
+}
+
+func (r *memoryRepository) LatestActions(ctx context.Context, actions []string) (map[primitive.ObjectID]time.Time, error) {
+	latest := map[primitive.ObjectID]time.Time{}
+	for _, entry := range r.entries.Find(nil) {
+		for _, action := range actions {
+			if entry.Action == action && entry.CreationDate.After(latest[entry.TargetID]) {
+				latest[entry.TargetID] = entry.CreationDate
+			}
+		}
+	}
+	return latest, nil
+}
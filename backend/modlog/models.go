@@ -0,0 +1,65 @@
+package modlog
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ActionRemovePost         = "remove_post"
+	ActionApprovePost        = "approve_post"
+	ActionPinPost            = "pin_post"
+	ActionUnpinPost          = "unpin_post"
+	ActionLockPost           = "lock_post"
+	ActionUnlockPost         = "unlock_post"
+	ActionRemoveComment      = "remove_comment"
+	ActionApproveComment     = "approve_comment"
+	ActionApproveUser        = "approve_user"
+	ActionRemoveApprovedUser = "remove_approved_user"
+	ActionDenyJoinRequest    = "deny_join_request"
+	ActionEditSettings       = "edit_settings"
+	ActionEditAutomodRules   = "edit_automod_rules"
+	ActionDeleteCommunity    = "delete_community"
+	ActionConfirmVotes       = "confirm_flagged_votes"
+	ActionClearVotes         = "clear_flagged_votes"
+	ActionPurgePost          = "purge_post"
+	ActionPurgeComment       = "purge_comment"
+	ActionSuspendUser        = "suspend_user"
+	ActionUnsuspendUser      = "unsuspend_user"
+	ActionPromoteUser        = "promote_user"
+	ActionDemoteUser         = "demote_user"
+)
+
+// Operator is the Moderator of entries recorded by the redditctl command.
+const Operator = "redditctl"
+
+const (
+	TargetPost      = "post"
+	TargetComment   = "comment"
+	TargetUser      = "user"
+	TargetCommunity = "community"
+)
+
+// Entry records one privileged action taken in a community by a moderator, an administrator or AutoModerator.
+// Entries keep the community's name so administrators can still find them after the community is deleted.
+// Actions on accounts, such as suspensions, are site-wide and have no community.
+type Entry struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	CommunityID   primitive.ObjectID `json:"community_id" bson:"community_id"`
+	CommunityName string             `json:"community_name" bson:"community_name"`
+	Moderator     string             `json:"moderator" bson:"moderator"`
+	// Admin is set when the action was allowed because Moderator is a site administrator.
+	Admin      bool               `json:"admin,omitempty" bson:"admin,omitempty"`
+	Action     string             `json:"action" bson:"action"`
+	TargetType string             `json:"target_type" bson:"target_type"`
+	TargetID   primitive.ObjectID `json:"target_id,omitempty" bson:"target_id,omitempty"`
+	// TargetUser is the author of the target post or comment, or the user acted upon.
+	TargetUser   string    `json:"target_user,omitempty" bson:"target_user,omitempty"`
+	Details      string    `json:"details,omitempty" bson:"details,omitempty"`
+	CreationDate time.Time `json:"creation_date" bson:"creation_date"`
+}
+
+func (e Entry) GetID() primitive.ObjectID {
+	return e.ID
+}
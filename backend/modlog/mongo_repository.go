@@ -0,0 +1,93 @@
+package modlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	entries *mongo.Collection
+}
+
+// NewMongoRepository stores entries in the modlog collection of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{entries: db.Collection("modlog")}
+}
+
+func (r *mongoRepository) Insert(ctx context.Context, entry Entry) error {
+	_, err := r.entries.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *mongoRepository) List(ctx context.Context, query Query, page common.PageRequest) ([]Entry, error) {
+	filter := bson.M{}
+	if !query.CommunityID.IsZero() {
+		filter["community_id"] = query.CommunityID
+	}
+	if query.CommunityName != "" {
+		filter["community_name"] = query.CommunityName
+	}
+	if query.Moderator != "" {
+		filter["moderator"] = query.Moderator
+	}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	dateRange := bson.M{}
+	if !query.From.IsZero() {
+		dateRange["$gte"] = query.From
+	}
+	if !query.To.IsZero() {
+		dateRange["$lte"] = query.To
+	}
+	if len(dateRange) > 0 {
+		filter["creation_date"] = dateRange
+	}
+	if page.HasAfter {
+		filter["_id"] = bson.M{"$lt": page.AfterID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.entries.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Entry
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) LatestActions(ctx context.Context, actions []string) (map[primitive.ObjectID]time.Time, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"action": bson.M{"$in": actions}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$target_id", "latest": bson.M{"$max": "$creation_date"}}}},
+	}
+	cursor, err := r.entries.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID     primitive.ObjectID `bson:"_id"`
+		Latest time.Time          `bson:"latest"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	latest := make(map[primitive.ObjectID]time.Time, len(rows))
+	for _, row := range rows {
+		latest[row.ID] = row.Latest
+	}
+	return latest, nil
+}
@@ -0,0 +1,40 @@
+package modlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Query selects the entries of one community, by ID or, for communities that may have been deleted, by name.
+// Empty fields match every entry.
+type Query struct {
+	CommunityID   primitive.ObjectID
+	CommunityName string
+	Moderator     string
+	Action        string
+	From          time.Time
+	To            time.Time
+}
+
+// Repository stores moderation log entries.
+type Repository interface {
+	Insert(ctx context.Context, entry Entry) error
+	// List returns the matching entries, newest first, reading up to page.Limit+1 of them.
+	List(ctx context.Context, query Query, page common.PageRequest) ([]Entry, error)
+	// LatestActions maps the target of every entry with one of actions to when its latest such entry was recorded.
+	LatestActions(ctx context.Context, actions []string) (map[primitive.ObjectID]time.Time, error)
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by Record and List.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+func repo() Repository {
+	return repository.Get()
+}
@@ -0,0 +1,29 @@
+package modlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Record stores an entry. Failures are logged rather than returned so they never undo the action itself.
+func Record(ctx context.Context, entry Entry) {
+	entry.ID = primitive.NewObjectID()
+	entry.CreationDate = time.Now()
+	if err := repo().Insert(ctx, entry); err != nil {
+		logging.FromContext(ctx).Error("error recording moderation action", "error", err)
+	}
+}
+
+// LatestActions maps each target acted upon with one of actions to when that last happened.
+func LatestActions(ctx context.Context, actions ...string) (map[primitive.ObjectID]time.Time, error) {
+	return repo().LatestActions(ctx, actions)
+}
+
+// List returns the entries matching query, newest first, reading up to page.Limit+1 of them.
+func List(ctx context.Context, query Query, page common.PageRequest) ([]Entry, error) {
+	return repo().List(ctx, query, page)
+}
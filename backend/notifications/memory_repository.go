@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRepository struct {
+	notifications *memstore.Table[Notification]
+
+	mu          sync.RWMutex
+	preferences map[string]Preferences
+}
+
+// NewMemoryRepository keeps notifications in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		notifications: memstore.NewTable[Notification](),
+		preferences:   map[string]Preferences{},
+	}
+}
+
+func (r *memoryRepository) Insert(ctx context.Context, notification Notification) error {
+	return r.notifications.Insert(notification, nil)
+}
+
+func (r *memoryRepository) List(ctx context.Context, username string, unreadOnly bool, page common.PageRequest) ([]Notification, error) {
+	rows := r.notifications.Find(func(notification Notification) bool {
+		return notification.Username == username && (!unreadOnly || !notification.Read)
+	})
+	return memstore.Page(rows, page, true), nil
+}
+
+func (r *memoryRepository) CountUnread(ctx context.Context, username string) (int64, error) {
+	return r.notifications.Count(unreadOf(username)), nil
+}
+
+func (r *memoryRepository) MarkRead(ctx context.Context, username string, id primitive.ObjectID) error {
+	_, err := r.notifications.UpdateOne(func(notification Notification) bool {
+		return notification.ID == id && notification.Username == username
+	}, markRead)
+	return err
+}
+
+func (r *memoryRepository) MarkAllRead(ctx context.Context, username string) (int64, error) {
+	return int64(len(r.notifications.Update(unreadOf(username), markRead))), nil
+}
+
+func (r *memoryRepository) FindPreferences(ctx context.Context, username string) (Preferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs, ok := r.preferences[username]
+	if !ok {
+		return Preferences{}, common.ErrNotFound
+	}
+	return prefs, nil
+}
+
+func (r *memoryRepository) SavePreferences(ctx context.Context, prefs Preferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.preferences[prefs.Username] = prefs
+	return nil
+}
+
+func unreadOf(username string) func(Notification) bool {
+	return func(notification Notification) bool { return notification.Username == username && !notification.Read }
+}
+
+func markRead(notification *Notification) {
+	notification.Read = true
+}
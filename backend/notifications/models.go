@@ -0,0 +1,77 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	TypePostReply    = "post_reply"
+	TypeCommentReply = "comment_reply"
+	TypeMention      = "mention"
+)
+
+// Notification is one inbox entry for Username, describing something Actor did.
+type Notification struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Username     string             `json:"username" bson:"username,omitempty"`
+	Type         string             `json:"type" bson:"type,omitempty"`
+	Actor        string             `json:"actor" bson:"actor,omitempty"`
+	PostID       primitive.ObjectID `json:"post_id" bson:"post_id,omitempty"`
+	CommentID    primitive.ObjectID `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	Read         bool               `json:"read" bson:"read"`
+	CreationDate time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
+}
+
+func (n Notification) GetID() primitive.ObjectID {
+	return n.ID
+}
+
+// Preferences controls which notification types a user receives. Users without a stored document get everything.
+type Preferences struct {
+	Username       string `json:"username" bson:"username,omitempty"`
+	PostReplies    bool   `json:"post_replies" bson:"post_replies"`
+	CommentReplies bool   `json:"comment_replies" bson:"comment_replies"`
+	Mentions       bool   `json:"mentions" bson:"mentions"`
+}
+
+func defaultPreferences(username string) Preferences {
+	return Preferences{Username: username, PostReplies: true, CommentReplies: true, Mentions: true}
+}
+
+func (p Preferences) allows(notificationType string) bool {
+	switch notificationType {
+	case TypePostReply:
+		return p.PostReplies
+	case TypeCommentReply:
+		return p.CommentReplies
+	case TypeMention:
+		return p.Mentions
+	default:
+		return false
+	}
+}
+
+type UpdatePreferencesRequest struct {
+	PostReplies    *bool `json:"post_replies"`
+	CommentReplies *bool `json:"comment_replies"`
+	Mentions       *bool `json:"mentions"`
+}
+
+// NotificationListResponse is the data of the caller's notification listing.
+type NotificationListResponse struct {
+	Notifications []Notification    `json:"notifications"`
+	Pagination    common.Pagination `json:"pagination"`
+}
+
+// UnreadCountResponse is the data of the caller's unread notification count.
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// PreferencesResponse is the data of the caller's notification preferences.
+type PreferencesResponse struct {
+	Preferences Preferences `json:"preferences"`
+}
@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	notifications *mongo.Collection
+	preferences   *mongo.Collection
+}
+
+// NewMongoRepository stores notifications in the notifications and notification_preferences collections of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{
+		notifications: db.Collection("notifications"),
+		preferences:   db.Collection("notification_preferences"),
+	}
+}
+
+func (r *mongoRepository) Insert(ctx context.Context, notification Notification) error {
+	_, err := r.notifications.InsertOne(ctx, notification)
+	return err
+}
+
+func (r *mongoRepository) List(ctx context.Context, username string, unreadOnly bool, page common.PageRequest) ([]Notification, error) {
+	filter := bson.M{"username": username}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	if page.HasAfter {
+		filter["_id"] = bson.M{"$lt": page.AfterID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.notifications.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Notification
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) CountUnread(ctx context.Context, username string) (int64, error) {
+	return r.notifications.CountDocuments(ctx, bson.M{"username": username, "read": false})
+}
+
+func (r *mongoRepository) MarkRead(ctx context.Context, username string, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "username": username}
+	result, err := r.notifications.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoRepository) MarkAllRead(ctx context.Context, username string) (int64, error) {
+	filter := bson.M{"username": username, "read": false}
+	result, err := r.notifications.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *mongoRepository) FindPreferences(ctx context.Context, username string) (Preferences, error) {
+	var prefs Preferences
+	err := r.preferences.FindOne(ctx, bson.M{"username": username}).Decode(&prefs)
+	return prefs, common.MongoError(err)
+}
+
+func (r *mongoRepository) SavePreferences(ctx context.Context, prefs Preferences) error {
+	_, err := r.preferences.ReplaceOne(ctx, bson.M{"username": prefs.Username}, prefs, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,34 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repository stores notifications and notification preferences.
+type Repository interface {
+	Insert(ctx context.Context, notification Notification) error
+	// List returns the notifications of username, newest first, reading up to page.Limit+1 of them.
+	List(ctx context.Context, username string, unreadOnly bool, page common.PageRequest) ([]Notification, error)
+	CountUnread(ctx context.Context, username string) (int64, error)
+	// MarkRead marks one notification of username as read, or returns common.ErrNotFound.
+	MarkRead(ctx context.Context, username string, id primitive.ObjectID) error
+	// MarkAllRead marks every unread notification of username as read and returns how many there were.
+	MarkAllRead(ctx context.Context, username string) (int64, error)
+	// FindPreferences returns the stored preferences of username, or common.ErrNotFound.
+	FindPreferences(ctx context.Context, username string) (Preferences, error)
+	SavePreferences(ctx context.Context, prefs Preferences) error
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+func repo() Repository {
+	return repository.Get()
+}
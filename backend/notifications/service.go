@@ -0,0 +1,202 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/blocks"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w/])u/([A-Za-z0-9_-]+)`)
+
+// Notify stores a notification unless it is self-inflicted, its actor is blocked by the recipient or the recipient
+// opted out of its type. Failures are logged rather than returned so they never fail the action that triggered them.
+func Notify(ctx context.Context, notification Notification) {
+	if notification.Username == "" || notification.Username == notification.Actor {
+		return
+	}
+
+	if notification.Actor != "" {
+		blocked, err := blocks.IsBlocked(ctx, notification.Username, notification.Actor)
+		if err != nil {
+			logging.FromContext(ctx).Error("error checking blocks", "error", err)
+			return
+		}
+		if blocked {
+			return
+		}
+	}
+
+	prefs, err := loadPreferences(ctx, notification.Username)
+	if err != nil {
+		logging.FromContext(ctx).Error("error loading notification preferences", "error", err)
+		return
+	}
+	if !prefs.allows(notification.Type) {
+		return
+	}
+
+	notification.ID = primitive.NewObjectID()
+	notification.Read = false
+	notification.CreationDate = time.Now()
+	if err := repo().Insert(ctx, notification); err != nil {
+		logging.FromContext(ctx).Error("error creating notification", "error", err)
+	}
+}
+
+// NotifyMentions notifies every existing user referenced as u/username in text.
+// Usernames in skip were already notified about the same item and are left out.
+func NotifyMentions(ctx context.Context, actor string, text string, postID primitive.ObjectID, commentID primitive.ObjectID, skip ...string) {
+	seen := map[string]bool{actor: true}
+	for _, username := range skip {
+		seen[username] = true
+	}
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := match[1]
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		exists, err := users.Exists(ctx, username)
+		if err != nil || !exists {
+			continue
+		}
+
+		Notify(ctx, Notification{
+			Username:  username,
+			Type:      TypeMention,
+			Actor:     actor,
+			PostID:    postID,
+			CommentID: commentID,
+		})
+	}
+}
+
+func loadPreferences(ctx context.Context, username string) (Preferences, error) {
+	prefs, err := repo().FindPreferences(ctx, username)
+	if errors.Is(err, common.ErrNotFound) {
+		return defaultPreferences(username), nil
+	}
+	return prefs, err
+}
+
+// GetNotifications retrieves a bounded page of the caller's notifications, newest first.
+func GetNotifications(c *gin.Context) {
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	results, err := repo().List(c.Request.Context(), c.GetString("username"), c.Query("unread") == "true", page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding notifications", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve notifications"))
+		return
+	}
+
+	notifications, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, NotificationListResponse{Notifications: notifications, Pagination: pagination})
+}
+
+// GetUnreadCount returns how many unread notifications the caller has.
+func GetUnreadCount(c *gin.Context) {
+	count, err := repo().CountUnread(c.Request.Context(), c.GetString("username"))
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error counting notifications", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to count notifications"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, UnreadCountResponse{UnreadCount: count})
+}
+
+// MarkRead marks one of the caller's notifications as read.
+func MarkRead(c *gin.Context) {
+	notificationID, err := primitive.ObjectIDFromHex(c.Param("notificationId"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid notification ID"))
+		return
+	}
+
+	err = repo().MarkRead(c.Request.Context(), c.GetString("username"), notificationID)
+	if errors.Is(err, common.ErrNotFound) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.NOTIFICATION_NOT_FOUND, "Notification not found"))
+		return
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error updating notification", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update notification"))
+		return
+	}
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Notification marked as read"})
+}
+
+// MarkAllRead marks every unread notification of the caller as read.
+func MarkAllRead(c *gin.Context) {
+	updated, err := repo().MarkAllRead(c.Request.Context(), c.GetString("username"))
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error updating notifications", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update notifications"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.UpdatedResponse{Message: "Notifications marked as read", Updated: updated})
+}
+
+// GetPreferences returns the caller's notification preferences.
+func GetPreferences(c *gin.Context) {
+	prefs, err := loadPreferences(c.Request.Context(), c.GetString("username"))
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error loading notification preferences", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve preferences"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, PreferencesResponse{Preferences: prefs})
+}
+
+// UpdatePreferences changes the preferences present in the request and keeps the others.
+func UpdatePreferences(c *gin.Context) {
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	prefs, err := loadPreferences(c.Request.Context(), username)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error loading notification preferences", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve preferences"))
+		return
+	}
+
+	if req.PostReplies != nil {
+		prefs.PostReplies = *req.PostReplies
+	}
+	if req.CommentReplies != nil {
+		prefs.CommentReplies = *req.CommentReplies
+	}
+	if req.Mentions != nil {
+		prefs.Mentions = *req.Mentions
+	}
+
+	if err := repo().SavePreferences(c.Request.Context(), prefs); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error saving notification preferences", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update preferences"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, PreferencesResponse{Preferences: prefs})
+}
@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/gin-gonic/gin"
+)
+
+// Auth describes how an endpoint treats the Authorization header.
+type Auth int
+
+const (
+	// Public endpoints ignore any token.
+	Public Auth = iota
+	// OptionalAuth endpoints are public but personalise the response for a valid token.
+	OptionalAuth
+	// RequiredAuth endpoints reject requests without a valid token.
+	RequiredAuth
+)
+
+// Param is a query string parameter.
+type Param struct {
+	Name        string
+	Description string
+	Sample      interface{}
+}
+
+// Endpoint is the documentation for one registered route.
+type Endpoint struct {
+	Summary string
+	Tag     string
+	Auth    Auth
+	// Paginated endpoints accept the limit and cursor query parameters read by common.ParsePageRequest.
+	Paginated bool
+	Query     []Param
+	// Request is a sample of the JSON body, nil when the endpoint takes none.
+	Request interface{}
+	// RequestType overrides the request content type, e.g. for YAML bodies.
+	RequestType string
+	// Status is the success status, http.StatusOK when zero.
+	Status int
+	// Response is a sample of the envelope's data field.
+	Response interface{}
+	// Stream marks server-sent event endpoints.
+	Stream bool
+	// Raw endpoints write Response as the whole body instead of inside the common envelope.
+	Raw bool
+	// ResponseType overrides the content type of raw responses, application/json by default.
+	ResponseType string
+}
+
+const errorSchema = "Error"
+
+// Key identifies a route the way endpoints are registered, e.g. "GET /posts/:postId".
+func Key(method, path string) string {
+	return method + " " + path
+}
+
+// Missing lists registered routes that have no endpoint documentation.
+func Missing(routes gin.RoutesInfo, endpoints map[string]Endpoint) []string {
+	var missing []string
+	for _, route := range routes {
+		if _, ok := endpoints[Key(route.Method, route.Path)]; !ok {
+			missing = append(missing, Key(route.Method, route.Path))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Unused lists documented endpoints that are no longer registered.
+func Unused(routes gin.RoutesInfo, endpoints map[string]Endpoint) []string {
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[Key(route.Method, route.Path)] = true
+	}
+
+	var unused []string
+	for key := range endpoints {
+		if !registered[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// Build documents every registered route that has an endpoint entry.
+func Build(routes gin.RoutesInfo, endpoints map[string]Endpoint) *Document {
+	s := newSchemas()
+	s.components[errorSchema] = envelope(s.of(common.ErrorData{}))
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: "simple-reddit API", Version: "1.0.0"},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		endpoint, ok := endpoints[Key(route.Method, route.Path)]
+		if !ok {
+			continue
+		}
+
+		path, params := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = s.operation(endpoint, params)
+	}
+
+	doc.Components.Schemas = s.components
+	return doc
+}
+
+// Handler serves the document for the routes registered on router. The document is built on the
+// first request so that it sees every route, including ones registered after the handler.
+func Handler(router *gin.Engine, endpoints map[string]Endpoint) gin.HandlerFunc {
+	var once sync.Once
+	var doc *Document
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc = Build(router.Routes(), endpoints)
+		})
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// openAPIPath converts gin's ":name" segments to "{name}" and returns the parameter names.
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func (s *schemas) operation(endpoint Endpoint, pathParams []string) *Operation {
+	op := &Operation{
+		Summary:   endpoint.Summary,
+		Responses: map[string]Response{},
+	}
+	if endpoint.Tag != "" {
+		op.Tags = []string{endpoint.Tag}
+	}
+
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	if endpoint.Paginated {
+		op.Parameters = append(op.Parameters,
+			Parameter{Name: "limit", In: "query", Description: "Page size", Schema: &Schema{Type: "integer", Format: "int64"}},
+			Parameter{Name: "after", In: "query", Description: "Cursor returned as next_cursor by the previous page", Schema: &Schema{Type: "string"}},
+		)
+	}
+	for _, param := range endpoint.Query {
+		schema := &Schema{Type: "string"}
+		if param.Sample != nil {
+			schema = s.of(param.Sample)
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: param.Name, In: "query", Description: param.Description, Schema: schema})
+	}
+
+	if endpoint.Request != nil {
+		contentType := endpoint.RequestType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{contentType: {Schema: s.of(endpoint.Request)}},
+		}
+	}
+
+	switch endpoint.Auth {
+	case RequiredAuth:
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+		op.Responses["401"] = errorResponse("Missing or invalid token")
+	case OptionalAuth:
+		op.Security = []map[string][]string{{"bearerAuth": {}}, {}}
+	}
+
+	status := endpoint.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if endpoint.Stream {
+		success.Content = map[string]MediaType{"text/event-stream": {Schema: &Schema{Type: "string"}}}
+	} else if endpoint.Raw {
+		contentType := endpoint.ResponseType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		success.Content = map[string]MediaType{contentType: {Schema: s.of(endpoint.Response)}}
+	} else {
+		success.Content = map[string]MediaType{"application/json": {Schema: envelope(s.of(endpoint.Response))}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+	op.Responses["default"] = errorResponse("Error")
+	return op
+}
+
+// envelope wraps data in the shape written by common.RespondWithJSON.
+func envelope(data *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "integer", Format: "int32"},
+			"message": {Type: "string"},
+			"code":    {Type: "string"},
+			"data":    data,
+		},
+		Required: []string{"code", "data", "message", "status"},
+	}
+}
+
+func errorResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/" + errorSchema}}},
+	}
+}
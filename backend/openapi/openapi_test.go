@@ -0,0 +1,93 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type author struct {
+	Name string
+}
+
+type articleResponse struct {
+	Article    article           `json:"article"`
+	Pagination common.Pagination `json:"pagination"`
+}
+
+type article struct {
+	ID      primitive.ObjectID `json:"id"`
+	Title   string             `json:"title" binding:"required,min=1,max=300"`
+	Kind    string             `json:"kind" binding:"omitempty,oneof=link text"`
+	Tags    []string           `json:"tags" binding:"max=5,dive,max=20"`
+	Score   int                `json:"score" binding:"min=0"`
+	Edited  *time.Time         `json:"edited,omitempty"`
+	Secret  string             `json:"-"`
+	Author  author             `json:"author"`
+	Replies []article          `json:"replies"`
+}
+
+func TestSchemaFollowsJSONAndBindingTags(t *testing.T) {
+	s := newSchemas()
+	ref := s.of(article{})
+	assert.Equal(t, "#/components/schemas/openapi.article", ref.Ref)
+
+	schema := s.components["openapi.article"]
+	assert.Equal(t, []string{"title"}, schema.Required)
+	assert.NotContains(t, schema.Properties, "Secret")
+	assert.Equal(t, "^[0-9a-f]{24}$", schema.Properties["id"].Pattern)
+	assert.Equal(t, 1, *schema.Properties["title"].MinLength)
+	assert.Equal(t, 300, *schema.Properties["title"].MaxLength)
+	assert.Equal(t, []string{"link", "text"}, schema.Properties["kind"].Enum)
+	assert.Equal(t, 5, *schema.Properties["tags"].MaxItems)
+	assert.Equal(t, 20, *schema.Properties["tags"].Items.MaxLength)
+	assert.Equal(t, float64(0), *schema.Properties["score"].Minimum)
+	assert.Equal(t, "date-time", schema.Properties["edited"].Format)
+	assert.True(t, schema.Properties["edited"].Nullable)
+	assert.Equal(t, "#/components/schemas/openapi.article", schema.Properties["replies"].Items.Ref)
+
+	// Untagged fields keep their Go names, as encoding/json does.
+	assert.Contains(t, s.components["openapi.author"].Properties, "Name")
+}
+
+func TestBuildDocumentsRegisteredRoutes(t *testing.T) {
+	routes := gin.RoutesInfo{
+		{Method: http.MethodPost, Path: "/articles"},
+		{Method: http.MethodGet, Path: "/articles/:articleId"},
+		{Method: http.MethodDelete, Path: "/articles/:articleId"},
+	}
+	endpoints := map[string]Endpoint{
+		"POST /articles": {
+			Auth: RequiredAuth, Request: article{},
+			Status: http.StatusCreated, Response: articleResponse{},
+		},
+		"GET /articles/:articleId": {Paginated: true, Response: articleResponse{}},
+		"GET /articles":            {},
+	}
+
+	assert.Equal(t, []string{"DELETE /articles/:articleId"}, Missing(routes, endpoints))
+	assert.Equal(t, []string{"GET /articles"}, Unused(routes, endpoints))
+
+	doc := Build(routes, endpoints)
+	create := doc.Paths["/articles"]["post"]
+	assert.NotNil(t, create.RequestBody)
+	assert.Contains(t, create.Responses, "201")
+	assert.Contains(t, create.Responses, "401")
+	assert.Equal(t, "#/components/schemas/openapi.articleResponse", create.Responses["201"].Content["application/json"].Schema.Properties["data"].Ref)
+	assert.Equal(t, "#/components/schemas/openapi.article", doc.Components.Schemas["openapi.articleResponse"].Properties["article"].Ref)
+
+	get := doc.Paths["/articles/{articleId}"]["get"]
+	assert.Equal(t, "articleId", get.Parameters[0].Name)
+	assert.Equal(t, "path", get.Parameters[0].In)
+	assert.Len(t, get.Parameters, 3)
+	assert.NotContains(t, doc.Paths["/articles/{articleId}"], "delete")
+
+	_, err := json.Marshal(doc)
+	assert.NoError(t, err)
+}
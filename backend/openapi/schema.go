@@ -0,0 +1,200 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+// schemas turns Go values into schemas, collecting named structs as reusable components.
+type schemas struct {
+	components map[string]*Schema
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}}
+}
+
+// of describes the JSON encoding of sample, which may be a struct value, a slice or a scalar.
+func (s *schemas) of(sample interface{}) *Schema {
+	if sample == nil {
+		return &Schema{}
+	}
+	return s.forType(reflect.TypeOf(sample))
+}
+
+func (s *schemas) forType(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case objectIDType:
+		return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.forType(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.forType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.forType(t.Elem())}
+	case reflect.Struct:
+		return s.component(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// component registers a named struct once and refers to it, so recursive and shared types stay small.
+func (s *schemas) component(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return s.structSchema(t)
+	}
+
+	name := componentName(t)
+	if _, ok := s.components[name]; !ok {
+		s.components[name] = &Schema{}
+		*s.components[name] = *s.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	return pkg + "." + t.Name()
+}
+
+func (s *schemas) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := s.structSchema(field.Type)
+			for key, property := range embedded.Properties {
+				schema.Properties[key] = property
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		property := s.forType(field.Type)
+		if constrain(property, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+// jsonName returns the key encoding/json uses for a field, or false when the field is not encoded.
+// Embedded structs without a tag report an empty name so their fields are inlined.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+		name = field.Name
+	}
+	return name, true
+}
+
+// constrain applies gin binding rules to a property schema and reports whether the field is required.
+// Rules after "dive" apply to the items of a slice.
+func constrain(schema *Schema, binding string) bool {
+	if binding == "" {
+		return false
+	}
+
+	rules, itemRules := binding, ""
+	if i := strings.Index(binding, ",dive"); i >= 0 {
+		rules, itemRules = binding[:i], strings.TrimPrefix(binding[i+len(",dive"):], ",")
+	}
+	if itemRules != "" && schema.Items != nil && schema.Items.Ref == "" {
+		constrain(schema.Items, itemRules)
+	}
+
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "oneof":
+			schema.Enum = strings.Fields(value)
+		case "min", "max":
+			limit, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			applyLimit(schema, key == "min", limit)
+		}
+	}
+	return required
+}
+
+func applyLimit(schema *Schema, lower bool, limit int) {
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = &limit
+		} else {
+			schema.MaxLength = &limit
+		}
+	case "array":
+		if lower {
+			schema.MinItems = &limit
+		} else {
+			schema.MaxItems = &limit
+		}
+	case "integer", "number":
+		value := float64(limit)
+		if lower {
+			schema.Minimum = &value
+		} else {
+			schema.Maximum = &value
+		}
+	}
+}
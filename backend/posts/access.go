@@ -0,0 +1,55 @@
+package posts
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FindAccessiblePost loads a post and checks that the caller has the given access to its community.
+// Posts held or removed by moderation are only found by their author and the community's moderators.
+// When it returns false the error response has already been written.
+func FindAccessiblePost(c *gin.Context, postID primitive.ObjectID, access communities.Access) (Post, bool) {
+	post, err := Repo().FindByID(c.Request.Context(), postID)
+	if err != nil || !canSeeModerated(c, post) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.POST_NOT_FOUND, "Post not found"))
+		return Post{}, false
+	}
+	if !CheckCommunityAccess(c, post.Community, access) {
+		return Post{}, false
+	}
+	return post, true
+}
+
+func canSeeModerated(c *gin.Context, post Post) bool {
+	username := c.GetString("username")
+	if post.ModStatus == "" || post.Username == username {
+		return true
+	}
+	community, err := communities.FindByID(c.Request.Context(), post.Community)
+	return err == nil && community.IsModerator(username)
+}
+
+// CheckCommunityAccess reports whether the caller has the given access to a community, writing the error response if not.
+// Posts whose community has been deleted stay visible as they were before community types existed.
+func CheckCommunityAccess(c *gin.Context, communityID primitive.ObjectID, access communities.Access) bool {
+	community, err := communities.FindByID(c.Request.Context(), communityID)
+	if errors.Is(err, common.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding community", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to check community access"))
+		return false
+	}
+	if !community.Allows(c.GetString("username"), access) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.COMMUNITY_ACCESS_DENIED, "You do not have access to this community"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CrosspostPost creates a post in another community that points back to the original.
+// Crossposting a crosspost points the new post at the original rather than at the intermediate copy.
+func CrosspostPost(c *gin.Context) {
+	postID, err := primitive.ObjectIDFromHex(c.Param("postId"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid post ID"))
+		return
+	}
+
+	var req CrosspostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+
+	source, ok := FindAccessiblePost(c, postID, communities.AccessRead)
+	if !ok {
+		return
+	}
+
+	var parent CrosspostParent
+	if source.CrosspostParent != nil {
+		if source.CrosspostParent.Deleted {
+			common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.POST_NOT_FOUND, "The original post has been deleted"))
+			return
+		}
+		parent = *source.CrosspostParent
+	} else {
+		parent = CrosspostParent{ID: source.ID, Title: source.Title, Username: source.Username, Community: source.Community}
+	}
+
+	// The snapshot publishes the original's title, author and community wherever it is crossposted,
+	// so only posts that anyone may read can be crossposted.
+	origin, err := communities.FindByID(c.Request.Context(), parent.Community)
+	switch {
+	case err == nil && !origin.IsPublic():
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.COMMUNITY_ACCESS_DENIED, "Only posts from public communities can be crossposted"))
+		return
+	case err == nil && parent.CommunityName == "":
+		parent.CommunityName = origin.Name
+	case err != nil && !errors.Is(err, common.ErrNotFound):
+		logging.FromContext(c.Request.Context()).Error("error finding community", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to create crosspost"))
+		return
+	}
+
+	if req.Community == parent.Community {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_REQUEST_BODY, "The post is already in this community"))
+		return
+	}
+	community, ok := findPostableCommunity(c, req.Community, req.Flair)
+	if !ok {
+		return
+	}
+
+	title := req.Title
+	if title == "" {
+		title = parent.Title
+	}
+
+	now := time.Now()
+	crosspost := Post{
+		ID:              primitive.NewObjectID(),
+		Title:           title,
+		Community:       req.Community,
+		Username:        c.GetString("username"),
+		CreationDate:    now,
+		UpdationDate:    now,
+		Flair:           req.Flair,
+		CrosspostParent: &parent,
+	}
+	var replies []string
+	crosspost.ModStatus, crosspost.FilterReasons, replies = screen(c.Request.Context(), community, crosspost, false)
+
+	if err := Repo().Insert(c.Request.Context(), crosspost); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error creating crosspost", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to create crosspost"))
+		return
+	}
+	postsCreated.WithLabelValues(common.ModStatusLabel(crosspost.ModStatus)).Inc()
+
+	_, _ = Repo().AddCounters(c.Request.Context(), parent.ID, Counters{Crossposts: 1})
+
+	for _, reply := range replies {
+		if err := automod.Reply(c.Request.Context(), crosspost.ID, community.ID, primitive.NilObjectID, reply); err != nil {
+			logging.FromContext(c.Request.Context()).Error("error posting automod reply", "error", err)
+		}
+	}
+	message := "Crosspost created successfully"
+	switch crosspost.ModStatus {
+	case common.ModStatusFiltered:
+		message = "Crosspost submitted for moderator review"
+	case common.ModStatusRemoved:
+		recordAutomodRemoval(c.Request.Context(), community, crosspost)
+		message = "Crosspost was removed by AutoModerator"
+	}
+	common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, PostResponse{Message: message, Post: crosspost.forDisplay()})
+}
+
+// DetachCrossposts keeps crosspost counters and snapshots consistent after post has been deleted,
+// by its author or by redditctl purge.
+func DetachCrossposts(ctx context.Context, post Post) error {
+	if post.CrosspostParent != nil {
+		if post.CrosspostParent.Deleted {
+			return nil
+		}
+		_, err := Repo().AddCounters(ctx, post.CrosspostParent.ID, Counters{Crossposts: -1})
+		if errors.Is(err, common.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if post.CrosspostsCount == 0 {
+		return nil
+	}
+	return Repo().MarkParentDeleted(ctx, post.ID)
+}
@@ -0,0 +1,76 @@
+package posts
+
+import (
+	"net/http"
+
+	"github.com/ganesh96/simple-reddit/backend/blocks"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/follows"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// GetFollowingFeed retrieves a bounded page of posts written by the users the caller follows, newest first.
+// The $in over at most follows.MaxFollowing authors is served by the (username, _id) index, which Mongo merges
+// per author in _id order.
+func GetFollowingFeed(c *gin.Context) {
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	username := c.GetString("username")
+	followed, err := follows.FollowedUsernames(c.Request.Context(), username)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error loading followed users", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve feed"))
+		return
+	}
+	blocked, err := blocks.BlockedUsernames(c.Request.Context(), username)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error loading blocked users", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve feed"))
+		return
+	}
+	authors := withoutUsernames(followed, blocked)
+	if len(authors) == 0 {
+		posts, pagination := common.ApplyCursorPage([]Post{}, page.Limit)
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, PostListResponse{Posts: posts, Pagination: pagination})
+		return
+	}
+
+	hidden, err := communities.HiddenCommunityIDs(c.Request.Context(), username)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding private communities", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve feed"))
+		return
+	}
+
+	query := PostQuery{Authors: authors, ExcludeCommunities: hidden, Visible: true}
+	results, err := Repo().List(c.Request.Context(), query, page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding posts", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve feed"))
+		return
+	}
+
+	posts, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, PostListResponse{Posts: forDisplay(posts), Pagination: pagination})
+}
+
+func withoutUsernames(usernames []string, excluded []string) []string {
+	skip := make(map[string]bool, len(excluded))
+	for _, username := range excluded {
+		skip[username] = true
+	}
+
+	kept := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		if !skip[username] {
+			kept = append(kept, username)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,205 @@
+package posts
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRepository struct {
+	posts *memstore.Table[Post]
+	saved *memstore.Table[Saved]
+}
+
+// NewMemoryRepository keeps posts in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		posts: memstore.NewTable[Post](),
+		saved: memstore.NewTable[Saved](),
+	}
+}
+
+func (r *memoryRepository) Insert(ctx context.Context, post Post) error {
+	return r.posts.Insert(post, nil)
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Post, error) {
+	return r.posts.Get(id)
+}
+
+func (r *memoryRepository) List(ctx context.Context, query PostQuery, page common.PageRequest) ([]Post, error) {
+	return memstore.Page(r.posts.Find(query.matches), page, true), nil
+}
+
+func (r *memoryRepository) Count(ctx context.Context, query PostQuery) (int64, error) {
+	return r.posts.Count(query.matches), nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, id primitive.ObjectID, update PostUpdate) error {
+	_, err := r.posts.UpdateOne(withID(id), func(post *Post) {
+		if update.Title != nil {
+			post.Title = *update.Title
+		}
+		if update.Text != nil {
+			post.Text = *update.Text
+		}
+		if update.ContentHash != nil {
+			post.ContentHash = *update.ContentHash
+		}
+		if update.UpdationDate != nil {
+			post.UpdationDate = *update.UpdationDate
+		}
+		if update.Pinned != nil {
+			post.Pinned = *update.Pinned
+		}
+		if update.Locked != nil {
+			post.Locked = *update.Locked
+		}
+		if update.ClearModeration {
+			post.ModStatus, post.FilterReasons = "", nil
+		}
+		if update.ModStatus != nil {
+			post.ModStatus = *update.ModStatus
+		}
+		if update.FilterReasons != nil {
+			post.FilterReasons = *update.FilterReasons
+		}
+	})
+	return err
+}
+
+func (r *memoryRepository) Pin(ctx context.Context, id primitive.ObjectID, community primitive.ObjectID, limit int64) error {
+	unpinned := func(post Post) bool { return post.ID == id && !post.Pinned }
+	if _, err := r.posts.UpdateOne(unpinned, func(post *Post) { post.Pinned = true }); err != nil {
+		return err
+	}
+
+	pinned := true
+	if r.posts.Count(PostQuery{Community: community, Pinned: &pinned, Visible: true}.matches) <= limit {
+		return nil
+	}
+	r.posts.Update(withID(id), func(post *Post) { post.Pinned = false })
+	return ErrPinLimit
+}
+
+func (r *memoryRepository) AddCounters(ctx context.Context, id primitive.ObjectID, delta Counters) (Post, error) {
+	return r.posts.UpdateOne(withID(id), func(post *Post) {
+		post.UpVotes += delta.UpVotes
+		post.DownVotes += delta.DownVotes
+		post.CommentsCount += delta.Comments
+		post.CrosspostsCount += delta.Crossposts
+	})
+}
+
+func (r *memoryRepository) MarkParentDeleted(ctx context.Context, parentID primitive.ObjectID) error {
+	r.posts.Update(func(post Post) bool {
+		return post.CrosspostParent != nil && post.CrosspostParent.ID == parentID
+	}, func(post *Post) {
+		parent := *post.CrosspostParent
+		parent.Deleted = true
+		post.CrosspostParent = &parent
+	})
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if len(r.posts.Delete(withID(id))) == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryRepository) Score(ctx context.Context, username string) (int, error) {
+	score := 0
+	for _, post := range r.posts.Find(func(post Post) bool { return post.Username == username }) {
+		score += post.UpVotes - post.DownVotes
+	}
+	return score, nil
+}
+
+func (r *memoryRepository) Save(ctx context.Context, saved Saved) error {
+	err := r.saved.Insert(saved, savedItem(saved.Username, saved.ItemType, saved.ItemID))
+	if err == common.ErrDuplicate {
+		return nil
+	}
+	return err
+}
+
+func (r *memoryRepository) Unsave(ctx context.Context, username string, itemType string, itemID primitive.ObjectID) error {
+	r.saved.Delete(savedItem(username, itemType, itemID))
+	return nil
+}
+
+func (r *memoryRepository) ListSaved(ctx context.Context, username string, page common.PageRequest) ([]Saved, error) {
+	rows := r.saved.Find(func(saved Saved) bool { return saved.Username == username })
+	return memstore.Page(rows, page, true), nil
+}
+
+func (r *memoryRepository) DeleteSaved(ctx context.Context, itemIDs []primitive.ObjectID) error {
+	r.saved.Delete(func(saved Saved) bool { return containsID(itemIDs, saved.ItemID) })
+	return nil
+}
+
+// matches mirrors the filter the Mongo repository builds from the query.
+func (q PostQuery) matches(post Post) bool {
+	if !q.Community.IsZero() {
+		if post.Community != q.Community {
+			return false
+		}
+	} else if containsID(q.ExcludeCommunities, post.Community) {
+		return false
+	}
+	if q.Authors != nil && !containsString(q.Authors, post.Username) {
+		return false
+	}
+	if containsString(q.ExcludeAuthors, post.Username) {
+		return false
+	}
+	if q.Pinned != nil && post.Pinned != *q.Pinned {
+		return false
+	}
+	if q.Visible {
+		if post.ModStatus != "" {
+			return false
+		}
+	} else if q.ModStatus != "" && post.ModStatus != q.ModStatus {
+		return false
+	}
+	if q.ContentHash != "" && post.ContentHash != q.ContentHash {
+		return false
+	}
+	if !q.CreatedSince.IsZero() && post.CreationDate.Before(q.CreatedSince) {
+		return false
+	}
+	return q.ExcludeID.IsZero() || post.ID != q.ExcludeID
+}
+
+func withID(id primitive.ObjectID) func(Post) bool {
+	return func(post Post) bool { return post.ID == id }
+}
+
+func savedItem(username string, itemType string, itemID primitive.ObjectID) func(Saved) bool {
+	return func(saved Saved) bool {
+		return saved.Username == username && saved.ItemType == itemType && saved.ItemID == itemID
+	}
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+package posts
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var postsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "simple_reddit_posts_created_total",
+	Help: "Posts and crossposts created, by moderation state: visible, filtered or removed.",
+}, []string{"status"})
@@ -3,19 +3,20 @@ package posts
 import (
 	"time"
 
-	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/content"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var PostCollection *mongo.Collection = configs.GetCollection("posts")
-var SavedCollection *mongo.Collection = configs.GetCollection("saved")
+// MaxPinnedPosts is how many announcement posts a community may pin at once.
+const MaxPinnedPosts = 2
 
 // Post stores the feed item plus denormalized counters needed for fast reads.
 type Post struct {
 	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Title         string             `json:"title" bson:"title,omitempty"`
 	Text          string             `json:"text" bson:"text,omitempty"`
+	TextHTML      string             `json:"text_html" bson:"-"`
 	Community     primitive.ObjectID `json:"community" bson:"community,omitempty"`
 	Username      string             `json:"username" bson:"username,omitempty"`
 	UpdationDate  time.Time          `json:"updation_date" bson:"updation_date,omitempty"`
@@ -23,16 +24,62 @@ type Post struct {
 	UpVotes       int                `json:"up_votes" bson:"up_votes"`
 	DownVotes     int                `json:"down_votes" bson:"down_votes"`
 	CommentsCount int                `json:"comments_count" bson:"comments_count"`
+	Flair         string             `json:"flair,omitempty" bson:"flair,omitempty"`
+	Pinned        bool               `json:"pinned" bson:"pinned"`
+	Locked        bool               `json:"locked" bson:"locked"`
+	// CrosspostParent is set on crossposts; CrosspostsCount counts the crossposts of an original post.
+	CrosspostParent *CrosspostParent `json:"crosspost_parent,omitempty" bson:"crosspost_parent,omitempty"`
+	CrosspostsCount int              `json:"crossposts_count" bson:"crossposts_count"`
+	// ModStatus is empty for visible posts, or one of common.ModStatusFiltered and common.ModStatusRemoved.
+	ModStatus string `json:"mod_status,omitempty" bson:"mod_status,omitempty"`
+	// FilterReasons are only sent in the moderation queue, so authors cannot learn what the filters match.
+	FilterReasons []string `json:"filter_reasons,omitempty" bson:"filter_reasons,omitempty"`
+	ContentHash   string   `json:"-" bson:"content_hash,omitempty"`
+}
+
+// CrosspostParent snapshots the original post when it is crossposted, so crossposts can still
+// show where they came from after the original is deleted.
+type CrosspostParent struct {
+	ID            primitive.ObjectID `json:"id" bson:"id"`
+	Title         string             `json:"title" bson:"title"`
+	Username      string             `json:"username" bson:"username"`
+	Community     primitive.ObjectID `json:"community" bson:"community"`
+	CommunityName string             `json:"community_name" bson:"community_name"`
+	Deleted       bool               `json:"deleted" bson:"deleted"`
 }
 
 func (p Post) GetID() primitive.ObjectID {
 	return p.ID
 }
 
+// forDisplay prepares a post for a response: TextHTML is rendered from the stored markdown,
+// the vote counters are fuzzed and the filter reasons are dropped.
+func (p Post) forDisplay() Post {
+	p.TextHTML = content.RenderMarkdown(p.Text)
+	p.FilterReasons = nil
+	p.UpVotes, p.DownVotes = common.FuzzVoteCounts(p.ID, p.UpVotes, p.DownVotes)
+	return p
+}
+
+func forDisplay(posts []Post) []Post {
+	for i := range posts {
+		posts[i] = posts[i].forDisplay()
+	}
+	return posts
+}
+
 type CreatePostRequest struct {
 	Title     string             `json:"title" binding:"required,min=1,max=180"`
 	Text      string             `json:"text" binding:"max=10000"`
 	Community primitive.ObjectID `json:"community" binding:"required"`
+	Flair     string             `json:"flair" binding:"max=64"`
+}
+
+// CrosspostRequest shares an existing post into another community. Title defaults to the original title.
+type CrosspostRequest struct {
+	Community primitive.ObjectID `json:"community" binding:"required"`
+	Title     string             `json:"title" binding:"max=180"`
+	Flair     string             `json:"flair" binding:"max=64"`
 }
 
 type UpdatePostRequest struct {
@@ -40,6 +87,18 @@ type UpdatePostRequest struct {
 	Text  string `json:"text" binding:"max=10000"`
 }
 
+// PostResponse is the data of responses about one post. Message is only set when the post was just submitted.
+type PostResponse struct {
+	Message string `json:"message,omitempty"`
+	Post    Post   `json:"post"`
+}
+
+// PostListResponse is the data of every paginated post listing.
+type PostListResponse struct {
+	Posts      []Post            `json:"posts"`
+	Pagination common.Pagination `json:"pagination"`
+}
+
 // Saved represents a saved post or comment.
 type Saved struct {
 	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -47,3 +106,7 @@ type Saved struct {
 	ItemType string             `json:"item_type" bson:"item_type,omitempty"`
 	Username string             `json:"username" bson:"username,omitempty"`
 }
+
+func (s Saved) GetID() primitive.ObjectID {
+	return s.ID
+}
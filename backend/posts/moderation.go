@@ -0,0 +1,187 @@
+package posts
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PinPost pins a post to the top of its community listing.
+func PinPost(c *gin.Context) {
+	post, community, ok := findModeratedPost(c)
+	if !ok {
+		return
+	}
+	if post.Pinned {
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post already pinned"})
+		return
+	}
+
+	// Only visible posts count against the limit, since removed and filtered ones are not listed.
+	err := Repo().Pin(c.Request.Context(), post.ID, post.Community, MaxPinnedPosts)
+	if errors.Is(err, common.ErrNotFound) {
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post already pinned"})
+		return
+	}
+	if errors.Is(err, ErrPinLimit) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusConflict, common.PIN_LIMIT_REACHED, "Unpin another post first"))
+		return
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error pinning post", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to pin post"))
+		return
+	}
+	recordPostAction(c, community, post, modlog.ActionPinPost)
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post pinned successfully"})
+}
+
+// UnpinPost removes a post from the top of its community listing.
+func UnpinPost(c *gin.Context) {
+	post, community, ok := findModeratedPost(c)
+	if !ok {
+		return
+	}
+	pinned := false
+	setModerationFlag(c, community, post, PostUpdate{Pinned: &pinned}, modlog.ActionUnpinPost, "Post unpinned successfully")
+}
+
+// LockPost stops new comments and votes on a post.
+func LockPost(c *gin.Context) {
+	post, community, ok := findModeratedPost(c)
+	if !ok {
+		return
+	}
+	locked := true
+	setModerationFlag(c, community, post, PostUpdate{Locked: &locked}, modlog.ActionLockPost, "Post locked successfully")
+}
+
+// UnlockPost accepts comments and votes on a post again.
+func UnlockPost(c *gin.Context) {
+	post, community, ok := findModeratedPost(c)
+	if !ok {
+		return
+	}
+	locked := false
+	setModerationFlag(c, community, post, PostUpdate{Locked: &locked}, modlog.ActionUnlockPost, "Post unlocked successfully")
+}
+
+// ApprovePost publishes a post held by the spam filter or restores a removed one.
+func ApprovePost(c *gin.Context) {
+	post, community, ok := findModeratedPost(c)
+	if !ok {
+		return
+	}
+	if post.ModStatus == "" {
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post already approved"})
+		return
+	}
+
+	if err := Repo().Update(c.Request.Context(), post.ID, PostUpdate{ClearModeration: true}); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error approving post", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update post"))
+		return
+	}
+	recordPostAction(c, community, post, modlog.ActionApprovePost)
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post approved successfully"})
+}
+
+// RemovePost hides a post from everyone but its author and the community's moderators.
+func RemovePost(c *gin.Context) {
+	post, community, ok := findModeratedPost(c)
+	if !ok {
+		return
+	}
+	if post.ModStatus == common.ModStatusRemoved {
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post already removed"})
+		return
+	}
+
+	removed, pinned := common.ModStatusRemoved, false
+	if err := Repo().Update(c.Request.Context(), post.ID, PostUpdate{ModStatus: &removed, Pinned: &pinned}); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error removing post", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update post"))
+		return
+	}
+	recordPostAction(c, community, post, modlog.ActionRemovePost)
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post removed successfully"})
+}
+
+// GetModQueue lists the posts of a community held by the spam filter, newest first, for its moderators.
+func GetModQueue(c *gin.Context) {
+	community, ok := communities.FindModeratedCommunity(c)
+	if !ok {
+		return
+	}
+
+	page, err := common.ParsePageRequest(c)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
+		return
+	}
+
+	results, err := Repo().List(c.Request.Context(), PostQuery{Community: community.ID, ModStatus: common.ModStatusFiltered}, page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding queued posts", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve moderation queue"))
+		return
+	}
+
+	posts, pagination := common.ApplyCursorPage(results, page.Limit)
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, PostListResponse{Posts: posts, Pagination: pagination})
+}
+
+func setModerationFlag(c *gin.Context, community communities.Community, post Post, update PostUpdate, action string, message string) {
+	if err := Repo().Update(c.Request.Context(), post.ID, update); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error updating post", "action", action, "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update post"))
+		return
+	}
+	recordPostAction(c, community, post, action)
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: message})
+}
+
+// findModeratedPost loads the post in the URL and checks that the caller moderates its community.
+func findModeratedPost(c *gin.Context) (Post, communities.Community, bool) {
+	postID, err := primitive.ObjectIDFromHex(c.Param("postId"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid post ID"))
+		return Post{}, communities.Community{}, false
+	}
+
+	post, err := Repo().FindByID(c.Request.Context(), postID)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.POST_NOT_FOUND, "Post not found"))
+		return Post{}, communities.Community{}, false
+	}
+
+	community, err := communities.FindByID(c.Request.Context(), post.Community)
+	if err != nil || !community.IsModerator(c.GetString("username")) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Only moderators of this community can do that"))
+		return Post{}, communities.Community{}, false
+	}
+	return post, community, true
+}
+
+func recordPostAction(c *gin.Context, community communities.Community, post Post, action string) {
+	modlog.Record(c.Request.Context(), modlog.Entry{
+		CommunityID:   community.ID,
+		CommunityName: community.Name,
+		Moderator:     c.GetString("username"),
+		Action:        action,
+		TargetType:    modlog.TargetPost,
+		TargetID:      post.ID,
+		TargetUser:    post.Username,
+		Details:       post.Title,
+	})
+}
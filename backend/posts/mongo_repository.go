@@ -0,0 +1,243 @@
+package posts
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	posts *mongo.Collection
+	saved *mongo.Collection
+}
+
+// NewMongoRepository stores posts in the posts and saved collections of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{
+		posts: db.Collection("posts"),
+		saved: db.Collection("saved"),
+	}
+}
+
+func (r *mongoRepository) Insert(ctx context.Context, post Post) error {
+	_, err := r.posts.InsertOne(ctx, post)
+	return common.MongoError(err)
+}
+
+func (r *mongoRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Post, error) {
+	var post Post
+	err := r.posts.FindOne(ctx, bson.M{"_id": id}).Decode(&post)
+	return post, common.MongoError(err)
+}
+
+func (r *mongoRepository) List(ctx context.Context, query PostQuery, page common.PageRequest) ([]Post, error) {
+	filter := postFilter(query)
+	if page.HasAfter {
+		common.AddCondition(filter, "_id", "$lt", page.AfterID)
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.posts.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Post
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) Count(ctx context.Context, query PostQuery) (int64, error) {
+	return r.posts.CountDocuments(ctx, postFilter(query))
+}
+
+func (r *mongoRepository) Update(ctx context.Context, id primitive.ObjectID, update PostUpdate) error {
+	set := bson.M{}
+	if update.Title != nil {
+		set["title"] = *update.Title
+	}
+	if update.Text != nil {
+		set["text"] = *update.Text
+	}
+	if update.ContentHash != nil {
+		set["content_hash"] = *update.ContentHash
+	}
+	if update.UpdationDate != nil {
+		set["updation_date"] = *update.UpdationDate
+	}
+	if update.Pinned != nil {
+		set["pinned"] = *update.Pinned
+	}
+	if update.Locked != nil {
+		set["locked"] = *update.Locked
+	}
+	if update.ModStatus != nil {
+		set["mod_status"] = *update.ModStatus
+	}
+	if update.FilterReasons != nil {
+		set["filter_reasons"] = *update.FilterReasons
+	}
+
+	document := bson.M{}
+	if len(set) > 0 {
+		document["$set"] = set
+	}
+	if update.ClearModeration {
+		document["$unset"] = bson.M{"mod_status": "", "filter_reasons": ""}
+	}
+
+	result, err := r.posts.UpdateOne(ctx, bson.M{"_id": id}, document)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoRepository) Pin(ctx context.Context, id primitive.ObjectID, community primitive.ObjectID, limit int64) error {
+	result, err := r.posts.UpdateOne(ctx, bson.M{"_id": id, "pinned": bson.M{"$ne": true}}, bson.M{"$set": bson.M{"pinned": true}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return common.ErrNotFound
+	}
+
+	// Counting after pinning means moderators racing for the last slot can never both keep it.
+	pinned := true
+	count, err := r.Count(ctx, PostQuery{Community: community, Pinned: &pinned, Visible: true})
+	if err == nil && count <= limit {
+		return nil
+	}
+	if _, undoErr := r.posts.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"pinned": false}}); undoErr != nil {
+		return undoErr
+	}
+	if err != nil {
+		return err
+	}
+	return ErrPinLimit
+}
+
+func (r *mongoRepository) AddCounters(ctx context.Context, id primitive.ObjectID, delta Counters) (Post, error) {
+	inc := bson.M{
+		"up_votes":         delta.UpVotes,
+		"down_votes":       delta.DownVotes,
+		"comments_count":   delta.Comments,
+		"crossposts_count": delta.Crossposts,
+	}
+
+	var post Post
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.posts.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$inc": inc}, opts).Decode(&post)
+	return post, common.MongoError(err)
+}
+
+func (r *mongoRepository) MarkParentDeleted(ctx context.Context, parentID primitive.ObjectID) error {
+	_, err := r.posts.UpdateMany(ctx, bson.M{"crosspost_parent.id": parentID}, bson.M{"$set": bson.M{"crosspost_parent.deleted": true}})
+	return err
+}
+
+func (r *mongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.posts.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoRepository) Score(ctx context.Context, username string) (int, error) {
+	return common.SumScore(ctx, r.posts, username)
+}
+
+func (r *mongoRepository) Save(ctx context.Context, saved Saved) error {
+	filter := bson.M{"item_type": saved.ItemType, "item_id": saved.ItemID, "username": saved.Username}
+	_, err := r.saved.UpdateOne(ctx, filter, bson.M{"$setOnInsert": saved}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoRepository) Unsave(ctx context.Context, username string, itemType string, itemID primitive.ObjectID) error {
+	_, err := r.saved.DeleteOne(ctx, bson.M{"item_type": itemType, "item_id": itemID, "username": username})
+	return err
+}
+
+func (r *mongoRepository) DeleteSaved(ctx context.Context, itemIDs []primitive.ObjectID) error {
+	_, err := r.saved.DeleteMany(ctx, bson.M{"item_id": bson.M{"$in": itemIDs}})
+	return err
+}
+
+func (r *mongoRepository) ListSaved(ctx context.Context, username string, page common.PageRequest) ([]Saved, error) {
+	filter := bson.M{"username": username}
+	if page.HasAfter {
+		filter["_id"] = bson.M{"$lt": page.AfterID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(page.Limit + 1)
+
+	cursor, err := r.saved.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Saved
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func postFilter(query PostQuery) bson.M {
+	filter := bson.M{}
+	if !query.Community.IsZero() {
+		filter["community"] = query.Community
+	} else if len(query.ExcludeCommunities) > 0 {
+		filter["community"] = bson.M{"$nin": query.ExcludeCommunities}
+	}
+
+	authors := bson.M{}
+	if query.Authors != nil {
+		authors["$in"] = query.Authors
+	}
+	if len(query.ExcludeAuthors) > 0 {
+		authors["$nin"] = query.ExcludeAuthors
+	}
+	if len(authors) > 0 {
+		filter["username"] = authors
+	}
+
+	if query.Pinned != nil {
+		if *query.Pinned {
+			filter["pinned"] = true
+		} else {
+			filter["pinned"] = bson.M{"$ne": true}
+		}
+	}
+	if query.Visible {
+		filter["mod_status"] = bson.M{"$exists": false}
+	} else if query.ModStatus != "" {
+		filter["mod_status"] = query.ModStatus
+	}
+	if query.ContentHash != "" {
+		filter["content_hash"] = query.ContentHash
+	}
+	if !query.CreatedSince.IsZero() {
+		filter["creation_date"] = bson.M{"$gte": query.CreatedSince}
+	}
+	if !query.ExcludeID.IsZero() {
+		common.AddCondition(filter, "_id", "$ne", query.ExcludeID)
+	}
+	return filter
+}
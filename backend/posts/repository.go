@@ -0,0 +1,97 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostQuery selects posts. Zero fields match every post.
+type PostQuery struct {
+	// Community restricts the query to one community. ExcludeCommunities is only used when it is unset.
+	Community          primitive.ObjectID
+	ExcludeCommunities []primitive.ObjectID
+	// Authors restricts the query to posts written by one of the usernames.
+	Authors        []string
+	ExcludeAuthors []string
+	// Pinned, when set, selects pinned posts only (true) or every post that is not pinned (false).
+	Pinned *bool
+	// Visible selects posts no moderation state hides. ModStatus selects posts in exactly that state.
+	Visible   bool
+	ModStatus string
+	// ContentHash, CreatedSince and ExcludeID serve the spam filters' lookups of recent similar posts.
+	ContentHash  string
+	CreatedSince time.Time
+	ExcludeID    primitive.ObjectID
+}
+
+// PostUpdate changes only the fields that are set. ClearModeration approves the post, dropping its
+// moderation state and filter reasons; it is applied before ModStatus and FilterReasons.
+type PostUpdate struct {
+	Title           *string
+	Text            *string
+	ContentHash     *string
+	UpdationDate    *time.Time
+	Pinned          *bool
+	Locked          *bool
+	ClearModeration bool
+	ModStatus       *string
+	FilterReasons   *[]string
+}
+
+// Counters are the amounts added to a post's denormalized counters.
+type Counters struct {
+	UpVotes    int
+	DownVotes  int
+	Comments   int
+	Crossposts int
+}
+
+// ErrPinLimit is returned by Pin when the community already shows MaxPinnedPosts pinned posts.
+var ErrPinLimit = errors.New("pin limit reached")
+
+// Repository stores posts and the posts and comments users saved. Lookups that find nothing return common.ErrNotFound.
+type Repository interface {
+	Insert(ctx context.Context, post Post) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (Post, error)
+	// List returns the matching posts, newest first, reading up to page.Limit+1 of them.
+	List(ctx context.Context, query PostQuery, page common.PageRequest) ([]Post, error)
+	Count(ctx context.Context, query PostQuery) (int64, error)
+	// Update applies update to the post with id, or returns common.ErrNotFound.
+	Update(ctx context.Context, id primitive.ObjectID, update PostUpdate) error
+	// Pin pins the post with id in community unless that leaves more than limit visible pinned posts there,
+	// in which case the pin is undone and ErrPinLimit returned. It returns common.ErrNotFound when no
+	// unpinned post has id.
+	Pin(ctx context.Context, id primitive.ObjectID, community primitive.ObjectID, limit int64) error
+	// AddCounters adds delta to the counters of the post with id and returns the updated post.
+	AddCounters(ctx context.Context, id primitive.ObjectID, delta Counters) (Post, error)
+	// MarkParentDeleted flags the crosspost snapshots of a deleted original post.
+	MarkParentDeleted(ctx context.Context, parentID primitive.ObjectID) error
+	// Delete removes the post with id, or returns common.ErrNotFound.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// Score sums the up votes minus the down votes of every post written by username.
+	Score(ctx context.Context, username string) (int, error)
+
+	// Save stores saved unless its user already saved the same item.
+	Save(ctx context.Context, saved Saved) error
+	Unsave(ctx context.Context, username string, itemType string, itemID primitive.ObjectID) error
+	// ListSaved returns what username saved, most recent first, reading up to page.Limit+1 items.
+	ListSaved(ctx context.Context, username string, page common.PageRequest) ([]Saved, error)
+	// DeleteSaved removes every user's saves of the posts or comments with itemIDs.
+	DeleteSaved(ctx context.Context, itemIDs []primitive.ObjectID) error
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+// Repo returns the repository installed with SetRepository, for packages that need to read or count posts.
+func Repo() Repository {
+	return repository.Get()
+}
@@ -1,23 +1,34 @@
 package posts
 
 import (
-	"log"
+	"context"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/blocks"
 	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/notifications"
+	"github.com/ganesh96/simple-reddit/backend/spam"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // CreatePost creates a new post.
 func CreatePost(c *gin.Context) {
 	var req CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Error binding JSON: %v", err)
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": "Invalid request body"})
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+
+	community, ok := findPostableCommunity(c, req.Community, req.Flair)
+	if !ok {
 		return
 	}
 
@@ -33,134 +44,281 @@ func CreatePost(c *gin.Context) {
 		UpVotes:       0,
 		DownVotes:     0,
 		CommentsCount: 0,
+		Flair:         req.Flair,
+		ContentHash:   spam.ContentHash(req.Title, req.Text),
 	}
+	var replies []string
+	newPost.ModStatus, newPost.FilterReasons, replies = screen(c.Request.Context(), community, newPost, false)
 
-	_, err := PostCollection.InsertOne(c.Request.Context(), newPost)
-	if err != nil {
-		log.Printf("Error creating post: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to create post"})
+	if err := Repo().Insert(c.Request.Context(), newPost); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error creating post", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to create post"))
 		return
 	}
+	postsCreated.WithLabelValues(common.ModStatusLabel(newPost.ModStatus)).Inc()
 
-	common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, gin.H{"message": "Post created successfully", "post": newPost})
+	for _, reply := range replies {
+		if err := automod.Reply(c.Request.Context(), newPost.ID, community.ID, primitive.NilObjectID, reply); err != nil {
+			logging.FromContext(c.Request.Context()).Error("error posting automod reply", "error", err)
+		}
+	}
+	switch newPost.ModStatus {
+	case common.ModStatusFiltered:
+		common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, PostResponse{Message: "Post submitted for moderator review", Post: newPost.forDisplay()})
+		return
+	case common.ModStatusRemoved:
+		recordAutomodRemoval(c.Request.Context(), community, newPost)
+		common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, PostResponse{Message: "Post was removed by AutoModerator", Post: newPost.forDisplay()})
+		return
+	}
+
+	notifications.NotifyMentions(c.Request.Context(), newPost.Username, newPost.Text, newPost.ID, primitive.NilObjectID)
+
+	common.RespondWithJSON(c, http.StatusCreated, common.SUCCESS, PostResponse{Message: "Post created successfully", Post: newPost.forDisplay()})
+}
+
+func recordAutomodRemoval(ctx context.Context, community communities.Community, post Post) {
+	modlog.Record(ctx, modlog.Entry{
+		CommunityID:   community.ID,
+		CommunityName: community.Name,
+		Moderator:     automod.Username,
+		Action:        modlog.ActionRemovePost,
+		TargetType:    modlog.TargetPost,
+		TargetID:      post.ID,
+		TargetUser:    post.Username,
+		Details:       strings.Join(post.FilterReasons, "; "),
+	})
+}
+
+// screen runs the spam filter chain and the community's AutoModerator rules over a post.
+// It returns the post's moderation status, why it was held or removed, and the replies AutoModerator should post.
+// Moderators' posts are never screened.
+func screen(ctx context.Context, community communities.Community, post Post, edit bool) (string, []string, []string) {
+	if community.IsModerator(post.Username) {
+		return "", nil, nil
+	}
+
+	spamReasons := spam.Check(ctx, spam.Item{
+		Kind:      spam.KindPost,
+		ID:        post.ID,
+		Community: community.ID,
+		Settings:  community.SpamFilter,
+		Username:  post.Username,
+		Edit:      edit,
+		Title:     post.Title,
+		Text:      post.Text,
+		Now:       post.UpdationDate,
+	})
+
+	item := automod.Item{Type: automod.TypePost, Title: post.Title, Text: post.Text, Flair: post.Flair, Edit: edit}
+	result, err := automod.Evaluate(ctx, community.AutomodRules, item, automod.NewAuthor(post.Username))
+	if err != nil {
+		logging.FromContext(ctx).Error("error evaluating automod rules", "error", err)
+		// Fail closed: a post the rules could not check waits for a moderator instead of being published.
+		spamReasons = append(spamReasons, automod.UnevaluatedReason)
+	}
+
+	status, reasons := result.Status(spamReasons)
+	return status, reasons, result.Replies
+}
+
+// findPostableCommunity loads the community a new post targets and checks that the caller may post there
+// with the requested flair. When it returns false the error response has already been written.
+func findPostableCommunity(c *gin.Context, communityID primitive.ObjectID, flair string) (communities.Community, bool) {
+	community, err := communities.FindByID(c.Request.Context(), communityID)
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.COMMUNITY_NOT_FOUND, "Community not found"))
+		return communities.Community{}, false
+	}
+	if !community.Allows(c.GetString("username"), communities.AccessWrite) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.COMMUNITY_ACCESS_DENIED, "You are not approved to post in this community"))
+		return communities.Community{}, false
+	}
+	if flair != "" && !community.HasFlair(flair) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_FLAIR, "Flair is not available in this community"))
+		return communities.Community{}, false
+	}
+	return community, true
 }
 
 // GetAllPosts retrieves a bounded page of posts.
+// When listing a single community, its pinned posts are returned ahead of the first page and are kept
+// out of the cursor walk, so they neither count against the limit nor repeat on later pages.
 func GetAllPosts(c *gin.Context) {
 	page, err := common.ParsePageRequest(c)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": err.Error()})
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, err.Error()))
 		return
 	}
 
-	filter := bson.M{}
+	username := c.GetString("username")
+	query := PostQuery{Visible: true}
+	communityListing := c.Query("community") != ""
 	if community := c.Query("community"); community != "" {
 		communityID, err := primitive.ObjectIDFromHex(community)
 		if err != nil {
-			common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid community ID"})
+			common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid community ID"))
 			return
 		}
-		filter["community"] = communityID
-	}
-	if page.HasAfter {
-		filter["_id"] = bson.M{"$lt": page.AfterID}
+		if !CheckCommunityAccess(c, communityID, communities.AccessRead) {
+			return
+		}
+		notPinned := false
+		query.Community = communityID
+		query.Pinned = &notPinned
+	} else {
+		hidden, err := communities.HiddenCommunityIDs(c.Request.Context(), username)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("error finding private communities", "error", err)
+			common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve posts"))
+			return
+		}
+		query.ExcludeCommunities = hidden
 	}
-
-	findOptions := options.Find().
-		SetSort(bson.D{{Key: "_id", Value: -1}}).
-		SetLimit(page.Limit + 1)
-
-	cursor, err := PostCollection.Find(c.Request.Context(), filter, findOptions)
+	// Posts by blocked users are skipped by the query itself, keeping cursor pages full.
+	blocked, err := blocks.BlockedUsernames(c.Request.Context(), username)
 	if err != nil {
-		log.Printf("Error finding posts: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to retrieve posts"})
+		logging.FromContext(c.Request.Context()).Error("error loading blocked users", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve posts"))
 		return
 	}
-	defer cursor.Close(c.Request.Context())
+	query.ExcludeAuthors = blocked
 
-	var results []Post
-	if err = cursor.All(c.Request.Context(), &results); err != nil {
-		log.Printf("Error decoding posts: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to retrieve posts"})
+	results, err := Repo().List(c.Request.Context(), query, page)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding posts", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve posts"))
 		return
 	}
 
 	posts, pagination := common.ApplyCursorPage(results, page.Limit)
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"posts": posts, "pagination": pagination})
+	if communityListing && !page.HasAfter {
+		pinned := true
+		query.Pinned = &pinned
+		pinnedPosts, err := Repo().List(c.Request.Context(), query, common.PageRequest{Limit: MaxPinnedPosts})
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("error finding pinned posts", "error", err)
+			common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve posts"))
+			return
+		}
+		pinnedPosts, _ = common.ApplyCursorPage(pinnedPosts, MaxPinnedPosts)
+		posts = append(pinnedPosts, posts...)
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, PostListResponse{Posts: forDisplay(posts), Pagination: pagination})
 }
 
 // GetPostById retrieves a single post by its ID.
 func GetPostById(c *gin.Context) {
 	postID, err := primitive.ObjectIDFromHex(c.Param("postId"))
 	if err != nil {
-		log.Printf("Error converting postId to ObjectID: %v", err)
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid post ID"})
+		logging.FromContext(c.Request.Context()).Debug("invalid post ID", "post_id", c.Param("postId"), "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid post ID"))
 		return
 	}
 
-	var post Post
-	err = PostCollection.FindOne(c.Request.Context(), bson.M{"_id": postID}).Decode(&post)
-	if err != nil {
-		log.Printf("Error finding post: %v", err)
-		common.RespondWithJSON(c, http.StatusNotFound, common.POST_NOT_FOUND, gin.H{"error": "Post not found"})
+	post, ok := FindAccessiblePost(c, postID, communities.AccessRead)
+	if !ok {
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"post": post})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, PostResponse{Post: post.forDisplay()})
 }
 
 // UpdatePost updates a post.
 func UpdatePost(c *gin.Context) {
 	postID, err := primitive.ObjectIDFromHex(c.Param("postId"))
 	if err != nil {
-		log.Printf("Error converting postId to ObjectID: %v", err)
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid post ID"})
+		logging.FromContext(c.Request.Context()).Debug("invalid post ID", "post_id", c.Param("postId"), "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid post ID"))
 		return
 	}
 
 	var req UpdatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Error binding JSON: %v", err)
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": "Invalid request body"})
+		common.RespondWithError(c, common.BindError(err))
 		return
 	}
 
-	filter := bson.M{"_id": postID, "username": c.GetString("username")}
-	update := bson.M{"$set": bson.M{"title": req.Title, "text": req.Text, "updation_date": time.Now()}}
+	post, ok := FindAccessiblePost(c, postID, communities.AccessWrite)
+	if !ok {
+		return
+	}
 
-	result, err := PostCollection.UpdateOne(c.Request.Context(), filter, update)
-	if err != nil {
-		log.Printf("Error updating post: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to update post"})
+	if post.Username != c.GetString("username") {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Post not found or not owned by user"))
+		return
+	}
+
+	post.Title, post.Text, post.UpdationDate = req.Title, req.Text, time.Now()
+	contentHash := spam.ContentHash(post.Title, post.Text)
+	update := PostUpdate{Title: &post.Title, Text: &post.Text, UpdationDate: &post.UpdationDate, ContentHash: &contentHash}
+	// Edits can send a post to the moderation queue or remove it, but never bring it back; only moderators approve.
+	var newStatus string
+	community, err := communities.FindByID(c.Request.Context(), post.Community)
+	if post.ModStatus == "" && err == nil {
+		if status, reasons, _ := screen(c.Request.Context(), community, post, true); status != "" {
+			newStatus, post.ModStatus, post.FilterReasons = status, status, reasons
+			update.ModStatus, update.FilterReasons = &post.ModStatus, &post.FilterReasons
+		}
+	}
+
+	err = Repo().Update(c.Request.Context(), post.ID, update)
+	if errors.Is(err, common.ErrNotFound) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Post not found or not owned by user"))
 		return
 	}
-	if result.MatchedCount == 0 {
-		common.RespondWithJSON(c, http.StatusForbidden, common.FORBIDDEN, gin.H{"error": "Post not found or not owned by user"})
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error updating post", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update post"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Post updated successfully"})
+	switch newStatus {
+	case common.ModStatusFiltered:
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post updated and submitted for moderator review"})
+		return
+	case common.ModStatusRemoved:
+		recordAutomodRemoval(c.Request.Context(), community, post)
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post updated and removed by AutoModerator"})
+		return
+	}
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post updated successfully"})
 }
 
 // DeletePost deletes a post.
 func DeletePost(c *gin.Context) {
 	postID, err := primitive.ObjectIDFromHex(c.Param("postId"))
 	if err != nil {
-		log.Printf("Error converting postId to ObjectID: %v", err)
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid post ID"})
+		logging.FromContext(c.Request.Context()).Debug("invalid post ID", "post_id", c.Param("postId"), "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid post ID"))
 		return
 	}
 
-	result, err := PostCollection.DeleteOne(c.Request.Context(), bson.M{"_id": postID, "username": c.GetString("username")})
-	if err != nil {
-		log.Printf("Error deleting post: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to delete post"})
+	post, ok := FindAccessiblePost(c, postID, communities.AccessRead)
+	if !ok {
+		return
+	}
+
+	if post.Username != c.GetString("username") {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Post not found or not owned by user"))
+		return
+	}
+
+	err = Repo().Delete(c.Request.Context(), postID)
+	if errors.Is(err, common.ErrNotFound) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Post not found or not owned by user"))
 		return
 	}
-	if result.DeletedCount == 0 {
-		common.RespondWithJSON(c, http.StatusForbidden, common.FORBIDDEN, gin.H{"error": "Post not found or not owned by user"})
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error deleting post", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to delete post"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Post deleted successfully"})
+	if err := DetachCrossposts(c.Request.Context(), post); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error detaching crossposts of deleted post", "error", err)
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Post deleted successfully"})
 }
@@ -0,0 +1,79 @@
+package posts
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/blocks"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/events"
+	"github.com/ganesh96/simple-reddit/backend/health"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const streamHeartbeatInterval = 25 * time.Second
+
+// StreamPost pushes comment and vote changes for a post as Server-Sent Events until the client disconnects
+// or the server shuts down. Comments by users the reader blocked are left out, as the thread collapses them.
+func StreamPost(c *gin.Context) {
+	postID, err := primitive.ObjectIDFromHex(c.Param("postId"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid post ID"))
+		return
+	}
+
+	if _, ok := FindAccessiblePost(c, postID, communities.AccessRead); !ok {
+		return
+	}
+
+	blocked := map[string]bool{}
+	if username := c.GetString("username"); username != "" {
+		usernames, err := blocks.BlockedUsernames(c.Request.Context(), username)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("error finding blocked users", "error", err)
+			common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to open stream"))
+			return
+		}
+		for _, blockedUser := range usernames {
+			blocked[blockedUser] = true
+		}
+	}
+
+	stream, unsubscribe := events.SubscribePost(postID)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Header("Content-Type", "text/event-stream")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-health.ShuttingDown():
+			return false
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+			if blocked[event.Author] {
+				return true
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
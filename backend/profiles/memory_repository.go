@@ -0,0 +1,35 @@
+package profiles
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRepository struct {
+	profiles *memstore.Table[Profile]
+}
+
+// NewMemoryRepository keeps profiles in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{profiles: memstore.NewTable[Profile]()}
+}
+
+func (r *memoryRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) (Profile, error) {
+	return r.profiles.First(ofUser(userID))
+}
+
+func (r *memoryRepository) Update(ctx context.Context, userID primitive.ObjectID, profile Profile) error {
+	create := func() Profile { return Profile{ID: primitive.NewObjectID(), UserID: userID} }
+	r.profiles.Upsert(ofUser(userID), create, func(stored *Profile) {
+		stored.DisplayName = profile.DisplayName
+		stored.Description = profile.Description
+		stored.AvatarURL = profile.AvatarURL
+	})
+	return nil
+}
+
+func ofUser(userID primitive.ObjectID) func(Profile) bool {
+	return func(profile Profile) bool { return profile.UserID == userID }
+}
@@ -11,3 +11,14 @@ type Profile struct {
 	Description string             `bson:"description,omitempty"`
 	AvatarURL   string             `bson:"avatar_url,omitempty"`
 }
+
+func (p Profile) GetID() primitive.ObjectID {
+	return p.ID
+}
+
+// ProfileResponse is the data of a user's profile page.
+type ProfileResponse struct {
+	Profile        Profile `json:"profile"`
+	FollowersCount int64   `json:"followers_count"`
+	FollowingCount int64   `json:"following_count"`
+}
@@ -0,0 +1,38 @@
+package profiles
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	profiles *mongo.Collection
+}
+
+// NewMongoRepository stores profiles in the profiles collection of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{profiles: db.Collection("profiles")}
+}
+
+func (r *mongoRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) (Profile, error) {
+	var profile Profile
+	err := r.profiles.FindOne(ctx, bson.M{"user_id": userID}).Decode(&profile)
+	return profile, common.MongoError(err)
+}
+
+func (r *mongoRepository) Update(ctx context.Context, userID primitive.ObjectID, profile Profile) error {
+	update := bson.M{
+		"$set": bson.M{
+			"display_name": profile.DisplayName,
+			"description":  profile.Description,
+			"avatar_url":   profile.AvatarURL,
+		},
+	}
+	_, err := r.profiles.UpdateOne(ctx, bson.M{"user_id": userID}, update, options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,26 @@
+package profiles
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repository stores user profiles. Lookups that find nothing return common.ErrNotFound.
+type Repository interface {
+	FindByUserID(ctx context.Context, userID primitive.ObjectID) (Profile, error)
+	// Update replaces the editable fields of the profile of userID, creating the profile on its first edit.
+	Update(ctx context.Context, userID primitive.ObjectID, profile Profile) error
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+func repo() Repository {
+	return repository.Get()
+}
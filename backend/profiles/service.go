@@ -1,68 +1,70 @@
 package profiles
 
 import (
-	"context"
+	"errors"
 	"net/http"
 
 	"github.com/ganesh96/simple-reddit/backend/common"
-	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/follows"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/users"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 func GetProfileByUsername(c *gin.Context) {
 	username := c.Param("username")
 
-	userCollection := configs.GetCollection("users")
-	var user common.User
-	err := userCollection.FindOne(context.TODO(), bson.M{"username": username}).Decode(&user)
+	user, err := users.Repo().FindByUsername(c.Request.Context(), username)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusNotFound, common.USER_NOT_FOUND, gin.H{"error": "User not found"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.USER_NOT_FOUND, "User not found"))
 		return
 	}
 
-	profileCollection := configs.GetCollection("profiles")
-	var profile Profile
-	err = profileCollection.FindOne(context.TODO(), bson.M{"user_id": user.ID}).Decode(&profile)
+	// Profiles are created on their first edit; until then every user has an empty one.
+	profile, err := repo().FindByUserID(c.Request.Context(), user.ID)
+	if errors.Is(err, common.ErrNotFound) {
+		profile, err = Profile{UserID: user.ID}, nil
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding profile", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve profile"))
+		return
+	}
+
+	followers, following, err := follows.Counts(c.Request.Context(), username)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusNotFound, common.USER_NOT_FOUND, gin.H{"error": "Profile not found"})
+		logging.FromContext(c.Request.Context()).Error("error counting follows", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve profile"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"profile": profile})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, ProfileResponse{Profile: profile, FollowersCount: followers, FollowingCount: following})
 }
 
 func UpdateProfile(c *gin.Context) {
 	username := c.Param("username")
+	if username != c.GetString("username") {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "You can only update your own profile"))
+		return
+	}
 
-	userCollection := configs.GetCollection("users")
-	var user common.User
-	err := userCollection.FindOne(context.TODO(), bson.M{"username": username}).Decode(&user)
+	user, err := users.Repo().FindByUsername(c.Request.Context(), username)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusNotFound, common.USER_NOT_FOUND, gin.H{"error": "User not found"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.USER_NOT_FOUND, "User not found"))
 		return
 	}
 
 	var profile Profile
 	if err := c.ShouldBindJSON(&profile); err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": err.Error()})
+		common.RespondWithError(c, common.BindError(err))
 		return
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"display_name": profile.DisplayName,
-			"description":  profile.Description,
-			"avatar_url":   profile.AvatarURL,
-		},
-	}
-
-	profileCollection := configs.GetCollection("profiles")
-	_, err = profileCollection.UpdateOne(context.TODO(), bson.M{"user_id": user.ID}, update)
+	err = repo().Update(c.Request.Context(), user.ID, profile)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to update profile"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update profile"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Profile updated successfully"})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Profile updated successfully"})
 }
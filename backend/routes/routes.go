@@ -1,8 +1,17 @@
 package routes
 
 import (
+	"net/http"
+
+	"github.com/ganesh96/simple-reddit/backend/blocks"
 	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/common"
 	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/follows"
+	"github.com/ganesh96/simple-reddit/backend/health"
+	"github.com/ganesh96/simple-reddit/backend/messages"
+	"github.com/ganesh96/simple-reddit/backend/notifications"
+	"github.com/ganesh96/simple-reddit/backend/openapi"
 	"github.com/ganesh96/simple-reddit/backend/posts"
 	"github.com/ganesh96/simple-reddit/backend/profiles"
 	"github.com/ganesh96/simple-reddit/backend/users"
@@ -15,6 +24,14 @@ func SetupRoutes(router *gin.Engine) {
 	router.POST("/signup", users.Signup)
 	router.POST("/login", users.Login)
 	router.DELETE("/users/:username", users.AuthorizeJWT(), users.DeleteUser)
+	router.POST("/users/:username/block", users.AuthorizeJWT(), blocks.BlockUser)
+	router.DELETE("/users/:username/block", users.AuthorizeJWT(), blocks.UnblockUser)
+	router.GET("/blocks", users.AuthorizeJWT(), blocks.GetBlocks)
+	router.POST("/users/:username/follow", users.AuthorizeJWT(), follows.FollowUser)
+	router.DELETE("/users/:username/follow", users.AuthorizeJWT(), follows.UnfollowUser)
+	router.GET("/users/:username/followers", follows.GetFollowers)
+	router.GET("/users/:username/following", follows.GetFollowing)
+	router.GET("/feed/following", users.AuthorizeJWT(), posts.GetFollowingFeed)
 
 	// Profile routes
 	router.GET("/profiles/:username", profiles.GetProfileByUsername)
@@ -23,22 +40,83 @@ func SetupRoutes(router *gin.Engine) {
 	// Community routes
 	router.POST("/communities", users.AuthorizeJWT(), communities.CreateCommunity)
 	router.GET("/communities", communities.GetAllCommunities)
+	router.GET("/communities/:communityName", users.OptionalJWT(), communities.GetCommunityByName)
+	router.PATCH("/communities/:communityName", users.AuthorizeJWT(), communities.UpdateCommunity)
+	router.POST("/communities/:communityName/join", users.AuthorizeJWT(), communities.RequestToJoin)
+	router.GET("/communities/:communityName/join_requests", users.AuthorizeJWT(), communities.GetJoinRequests)
+	router.DELETE("/communities/:communityName/join_requests/:username", users.AuthorizeJWT(), communities.DenyJoinRequest)
+	router.GET("/communities/:communityName/approved_users", users.AuthorizeJWT(), communities.GetApprovedUsers)
+	router.POST("/communities/:communityName/approved_users/:username", users.AuthorizeJWT(), communities.ApproveUser)
+	router.DELETE("/communities/:communityName/approved_users/:username", users.AuthorizeJWT(), communities.RemoveApprovedUser)
 	router.DELETE("/communities/:communityName", users.AuthorizeJWT(), communities.DeleteCommunityByName)
 
 	// Post routes
 	router.POST("/posts", users.AuthorizeJWT(), posts.CreatePost)
-	router.GET("/posts", posts.GetAllPosts)
-	router.GET("/posts/:postId", posts.GetPostById)
+	router.GET("/posts", users.OptionalJWT(), posts.GetAllPosts)
+	router.GET("/posts/:postId", users.OptionalJWT(), posts.GetPostById)
+	router.GET("/posts/:postId/stream", users.OptionalJWT(), posts.StreamPost)
 	router.PUT("/posts/:postId", users.AuthorizeJWT(), posts.UpdatePost)
 	router.DELETE("/posts/:postId", users.AuthorizeJWT(), posts.DeletePost)
 	router.POST("/posts/:postId/vote", users.AuthorizeJWT(), votes.VotePost)
 	router.DELETE("/posts/:postId/vote", users.AuthorizeJWT(), votes.DeletePostVote)
+	router.POST("/posts/:postId/crosspost", users.AuthorizeJWT(), posts.CrosspostPost)
+	router.POST("/posts/:postId/pin", users.AuthorizeJWT(), posts.PinPost)
+	router.DELETE("/posts/:postId/pin", users.AuthorizeJWT(), posts.UnpinPost)
+	router.POST("/posts/:postId/lock", users.AuthorizeJWT(), posts.LockPost)
+	router.DELETE("/posts/:postId/lock", users.AuthorizeJWT(), posts.UnlockPost)
 
 	// Comment routes
 	router.POST("/posts/:postId/comments", users.AuthorizeJWT(), comments.CreateComment)
-	router.GET("/posts/:postId/comments", comments.GetCommentsByPostId)
+	router.GET("/posts/:postId/comments", users.OptionalJWT(), comments.GetCommentsByPostId)
 	router.PUT("/comments/:commentId", users.AuthorizeJWT(), comments.UpdateComment)
 	router.DELETE("/comments/:commentId", users.AuthorizeJWT(), comments.DeleteComment)
 	router.POST("/comments/:commentId/vote", users.AuthorizeJWT(), votes.VoteComment)
 	router.DELETE("/comments/:commentId/vote", users.AuthorizeJWT(), votes.DeleteCommentVote)
+
+	// Notification routes
+	router.GET("/notifications", users.AuthorizeJWT(), notifications.GetNotifications)
+	router.GET("/notifications/unread_count", users.AuthorizeJWT(), notifications.GetUnreadCount)
+	router.PUT("/notifications/read", users.AuthorizeJWT(), notifications.MarkAllRead)
+	router.PUT("/notifications/:notificationId/read", users.AuthorizeJWT(), notifications.MarkRead)
+	router.GET("/notifications/preferences", users.AuthorizeJWT(), notifications.GetPreferences)
+	router.PUT("/notifications/preferences", users.AuthorizeJWT(), notifications.UpdatePreferences)
+
+	// Direct message routes
+	router.POST("/messages", users.AuthorizeJWT(), messages.SendMessage)
+	router.GET("/messages", users.AuthorizeJWT(), messages.GetInbox)
+	router.GET("/messages/:conversationId", users.AuthorizeJWT(), messages.GetConversation)
+	router.PUT("/messages/:conversationId/read", users.AuthorizeJWT(), messages.MarkConversationRead)
+
+	// Admin routes
+	router.GET("/admin/modlog", users.AuthorizeJWT(), users.RequireAdmin(), communities.GetAdminModlog)
+	router.GET("/admin/votes/flagged", users.AuthorizeJWT(), users.RequireAdmin(), votes.GetFlaggedVotes)
+	router.POST("/admin/votes/flagged/:targetType/:targetId/confirm", users.AuthorizeJWT(), users.RequireAdmin(), votes.ConfirmFlaggedVotes)
+	router.POST("/admin/votes/flagged/:targetType/:targetId/clear", users.AuthorizeJWT(), users.RequireAdmin(), votes.ClearFlaggedVotes)
+
+	// Moderation queue routes
+	router.GET("/communities/:communityName/modqueue/posts", users.AuthorizeJWT(), posts.GetModQueue)
+	router.GET("/communities/:communityName/modqueue/comments", users.AuthorizeJWT(), comments.GetModQueue)
+	router.POST("/posts/:postId/approve", users.AuthorizeJWT(), posts.ApprovePost)
+	router.POST("/posts/:postId/remove", users.AuthorizeJWT(), posts.RemovePost)
+	router.POST("/comments/:commentId/approve", users.AuthorizeJWT(), comments.ApproveComment)
+	router.POST("/comments/:commentId/remove", users.AuthorizeJWT(), comments.RemoveComment)
+
+	// AutoModerator routes
+	router.GET("/communities/:communityName/automod", users.AuthorizeJWT(), communities.GetAutomodRules)
+	router.PUT("/communities/:communityName/automod", users.AuthorizeJWT(), communities.UpdateAutomodRules)
+	router.POST("/communities/:communityName/automod/dry_run", users.AuthorizeJWT(), communities.DryRunAutomod)
+
+	// Moderation log routes
+	router.GET("/communities/:communityName/modlog", users.AuthorizeJWT(), communities.GetModlog)
+
+	// Probes
+	router.GET("/healthz", health.Healthz)
+	router.GET("/readyz", health.Readyz)
+
+	// API documentation
+	router.GET("/openapi.json", openapi.Handler(router, Endpoints))
+
+	router.NoRoute(func(c *gin.Context) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.ROUTE_NOT_FOUND, "No route for "+c.Request.Method+" "+c.Request.URL.Path))
+	})
 }
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRoutes(router)
+	return router
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	router := setupRouter()
+
+	assert.Empty(t, openapi.Missing(router.Routes(), Endpoints), "routes without an entry in Endpoints")
+	assert.Empty(t, openapi.Unused(router.Routes(), Endpoints), "Endpoints entries for routes that are not registered")
+}
+
+func TestOpenAPIDocumentIsServed(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"openapi":"3.0.3"`)
+	assert.Contains(t, w.Body.String(), `"/posts/{postId}/comments"`)
+	assert.Contains(t, w.Body.String(), `"posts.CreatePostRequest"`)
+	assert.Contains(t, w.Body.String(), `"posts.PostListResponse"`, "typed responses are documented by their structs")
+	assert.Contains(t, w.Body.String(), `"communities.CommunityResponse"`)
+	assert.Contains(t, w.Body.String(), `"users.SignupRequest"`)
+}
+
+func TestUnknownRouteUsesErrorEnvelope(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"status":404,"message":"Route not found","code":"ROUTE_NOT_FOUND","data":{"error":"No route for GET /nope"}}`, w.Body.String())
+}
@@ -0,0 +1,365 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/blocks"
+	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/follows"
+	"github.com/ganesh96/simple-reddit/backend/health"
+	"github.com/ganesh96/simple-reddit/backend/messages"
+	"github.com/ganesh96/simple-reddit/backend/notifications"
+	"github.com/ganesh96/simple-reddit/backend/openapi"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/profiles"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"github.com/ganesh96/simple-reddit/backend/votes"
+)
+
+var (
+	messageOnly = common.MessageResponse{}
+	updated     = common.UpdatedResponse{}
+)
+
+// Endpoints documents every route registered by SetupRoutes, keyed by openapi.Key.
+// TestEveryRouteIsDocumented fails when a route is added without an entry here.
+var Endpoints = map[string]openapi.Endpoint{
+	// User routes
+	"POST /signup": {
+		Summary: "Create an account", Tag: "users",
+		Request: users.SignupRequest{},
+		Status:  http.StatusCreated, Response: messageOnly,
+	},
+	"POST /login": {
+		Summary: "Exchange credentials for a JWT", Tag: "users",
+		Request: users.LoginDetails{}, Response: users.LoginResponse{},
+	},
+	"DELETE /users/:username": {
+		Summary: "Delete your account", Tag: "users", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"POST /users/:username/block": {
+		Summary: "Block a user", Tag: "users", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"DELETE /users/:username/block": {
+		Summary: "Unblock a user", Tag: "users", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"GET /blocks": {
+		Summary: "List the users you have blocked", Tag: "users", Auth: openapi.RequiredAuth, Paginated: true,
+		Response: blocks.BlockListResponse{},
+	},
+	"POST /users/:username/follow": {
+		Summary: "Follow a user", Tag: "users", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"DELETE /users/:username/follow": {
+		Summary: "Unfollow a user", Tag: "users", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"GET /users/:username/followers": {
+		Summary: "List a user's followers", Tag: "users", Paginated: true,
+		Response: follows.FollowersResponse{},
+	},
+	"GET /users/:username/following": {
+		Summary: "List the users a user follows", Tag: "users", Paginated: true,
+		Response: follows.FollowingResponse{},
+	},
+	"GET /feed/following": {
+		Summary: "Posts by the users you follow", Tag: "posts", Auth: openapi.RequiredAuth, Paginated: true,
+		Response: posts.PostListResponse{},
+	},
+
+	// Profile routes
+	"GET /profiles/:username": {
+		Summary: "Get a user's profile", Tag: "profiles",
+		Response: profiles.ProfileResponse{},
+	},
+	"PUT /profiles/:username": {
+		Summary: "Update your profile", Tag: "profiles", Auth: openapi.RequiredAuth,
+		Request: profiles.Profile{}, Response: messageOnly,
+	},
+
+	// Community routes
+	"POST /communities": {
+		Summary: "Create a community", Tag: "communities", Auth: openapi.RequiredAuth,
+		Request: communities.CreateCommunityRequest{},
+		Status:  http.StatusCreated, Response: communities.CommunityResponse{},
+	},
+	"GET /communities": {
+		Summary: "List communities", Tag: "communities",
+		Response: communities.CommunityListResponse{},
+	},
+	"GET /communities/:communityName": {
+		Summary: "Get a community; moderators also receive spam_filter", Tag: "communities", Auth: openapi.OptionalAuth,
+		Response: communities.CommunityResponse{},
+	},
+	"PATCH /communities/:communityName": {
+		Summary: "Update a community's settings", Tag: "communities", Auth: openapi.RequiredAuth,
+		Request:  communities.UpdateCommunityRequest{},
+		Response: communities.CommunityResponse{},
+	},
+	"POST /communities/:communityName/join": {
+		Summary: "Ask to join a restricted or private community", Tag: "communities", Auth: openapi.RequiredAuth,
+		Request: communities.JoinCommunityRequest{},
+		Status:  http.StatusAccepted, Response: messageOnly,
+	},
+	"GET /communities/:communityName/join_requests": {
+		Summary: "List pending join requests", Tag: "communities", Auth: openapi.RequiredAuth, Paginated: true,
+		Response: communities.JoinRequestListResponse{},
+	},
+	"DELETE /communities/:communityName/join_requests/:username": {
+		Summary: "Deny a join request", Tag: "communities", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"GET /communities/:communityName/approved_users": {
+		Summary: "List the users approved into a community; moderators only", Tag: "communities", Auth: openapi.RequiredAuth,
+		Response: communities.ApprovedUsersResponse{},
+	},
+	"POST /communities/:communityName/approved_users/:username": {
+		Summary: "Approve a user to post in the community", Tag: "communities", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"DELETE /communities/:communityName/approved_users/:username": {
+		Summary: "Remove an approved user", Tag: "communities", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"DELETE /communities/:communityName": {
+		Summary: "Delete a community", Tag: "communities", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+
+	// Post routes
+	"POST /posts": {
+		Summary: "Create a post", Tag: "posts", Auth: openapi.RequiredAuth,
+		Request: posts.CreatePostRequest{},
+		Status:  http.StatusCreated, Response: posts.PostResponse{},
+	},
+	"GET /posts": {
+		Summary: "List posts, newest first; pinned posts lead the first page of a community listing", Tag: "posts", Auth: openapi.OptionalAuth, Paginated: true,
+		Query:    []openapi.Param{{Name: "community", Description: "Community ID to list"}},
+		Response: posts.PostListResponse{},
+	},
+	"GET /posts/:postId": {
+		Summary: "Get a post", Tag: "posts", Auth: openapi.OptionalAuth,
+		Response: posts.PostResponse{},
+	},
+	"GET /posts/:postId/stream": {
+		Summary: "Stream vote and comment events for a post", Tag: "posts", Auth: openapi.OptionalAuth,
+		Stream: true,
+	},
+	"PUT /posts/:postId": {
+		Summary: "Edit your post", Tag: "posts", Auth: openapi.RequiredAuth,
+		Request: posts.UpdatePostRequest{}, Response: messageOnly,
+	},
+	"DELETE /posts/:postId": {
+		Summary: "Delete your post", Tag: "posts", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"POST /posts/:postId/vote": {
+		Summary: "Vote on a post", Tag: "votes", Auth: openapi.RequiredAuth,
+		Request: votes.VoteRequest{}, Response: votes.VoteResponse{},
+	},
+	"DELETE /posts/:postId/vote": {
+		Summary: "Remove your vote on a post", Tag: "votes", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"POST /posts/:postId/crosspost": {
+		Summary: "Crosspost a post from a public community into another community", Tag: "posts", Auth: openapi.RequiredAuth,
+		Request: posts.CrosspostRequest{},
+		Status:  http.StatusCreated, Response: posts.PostResponse{},
+	},
+	"POST /posts/:postId/pin": {
+		Summary: "Pin a post to its community", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"DELETE /posts/:postId/pin": {
+		Summary: "Unpin a post", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"POST /posts/:postId/lock": {
+		Summary: "Lock a post against new comments", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"DELETE /posts/:postId/lock": {
+		Summary: "Unlock a post", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+
+	// Comment routes
+	"POST /posts/:postId/comments": {
+		Summary: "Comment on a post or reply to a comment", Tag: "comments", Auth: openapi.RequiredAuth,
+		Request: comments.CreateCommentRequest{},
+		Status:  http.StatusCreated, Response: comments.CommentResponse{},
+	},
+	"GET /posts/:postId/comments": {
+		Summary: "List a post's comments, stickied ones first", Tag: "comments", Auth: openapi.OptionalAuth, Paginated: true,
+		Response: comments.CommentListResponse{},
+	},
+	"PUT /comments/:commentId": {
+		Summary: "Edit your comment", Tag: "comments", Auth: openapi.RequiredAuth,
+		Request: comments.UpdateCommentRequest{}, Response: messageOnly,
+	},
+	"DELETE /comments/:commentId": {
+		Summary: "Delete your comment", Tag: "comments", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"POST /comments/:commentId/vote": {
+		Summary: "Vote on a comment", Tag: "votes", Auth: openapi.RequiredAuth,
+		Request: votes.VoteRequest{}, Response: votes.VoteResponse{},
+	},
+	"DELETE /comments/:commentId/vote": {
+		Summary: "Remove your vote on a comment", Tag: "votes", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+
+	// Notification routes
+	"GET /notifications": {
+		Summary: "List your notifications", Tag: "notifications", Auth: openapi.RequiredAuth, Paginated: true,
+		Query:    []openapi.Param{{Name: "unread", Description: "Only unread notifications when true", Sample: false}},
+		Response: notifications.NotificationListResponse{},
+	},
+	"GET /notifications/unread_count": {
+		Summary: "Count your unread notifications", Tag: "notifications", Auth: openapi.RequiredAuth,
+		Response: notifications.UnreadCountResponse{},
+	},
+	"PUT /notifications/read": {
+		Summary: "Mark all notifications as read", Tag: "notifications", Auth: openapi.RequiredAuth,
+		Response: updated,
+	},
+	"PUT /notifications/:notificationId/read": {
+		Summary: "Mark a notification as read", Tag: "notifications", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"GET /notifications/preferences": {
+		Summary: "Get your notification preferences", Tag: "notifications", Auth: openapi.RequiredAuth,
+		Response: notifications.PreferencesResponse{},
+	},
+	"PUT /notifications/preferences": {
+		Summary: "Update your notification preferences", Tag: "notifications", Auth: openapi.RequiredAuth,
+		Request:  notifications.UpdatePreferencesRequest{},
+		Response: notifications.PreferencesResponse{},
+	},
+
+	// Direct message routes
+	"POST /messages": {
+		Summary: "Send a direct message", Tag: "messages", Auth: openapi.RequiredAuth,
+		Request: messages.SendMessageRequest{},
+		Status:  http.StatusCreated, Response: messages.SendMessageResponse{},
+	},
+	"GET /messages": {
+		Summary: "List your conversations", Tag: "messages", Auth: openapi.RequiredAuth, Paginated: true,
+		Response: messages.ConversationListResponse{},
+	},
+	"GET /messages/:conversationId": {
+		Summary: "Read a conversation", Tag: "messages", Auth: openapi.RequiredAuth, Paginated: true,
+		Response: messages.ConversationResponse{},
+	},
+	"PUT /messages/:conversationId/read": {
+		Summary: "Mark a conversation as read", Tag: "messages", Auth: openapi.RequiredAuth,
+		Response: updated,
+	},
+
+	// Admin routes
+	"GET /admin/modlog": {
+		Summary: "List moderator actions in a community by name, including deleted communities", Tag: "admin", Auth: openapi.RequiredAuth, Paginated: true,
+		Query: []openapi.Param{
+			{Name: "community", Description: "Community name (required)"},
+			{Name: "moderator", Description: "Only actions by this moderator"},
+			{Name: "action", Description: "Only this action"},
+			{Name: "from", Description: "Earliest action, RFC 3339", Sample: time.Time{}},
+			{Name: "to", Description: "Latest action, RFC 3339", Sample: time.Time{}},
+		},
+		Response: communities.ModlogResponse{},
+	},
+	"GET /admin/votes/flagged": {
+		Summary: "Clusters of suspicious votes and the earlier votes queued for review with them", Tag: "admin", Auth: openapi.RequiredAuth,
+		Query:    []openapi.Param{{Name: "limit", Description: "Number of clusters, 1 to 200", Sample: 0}},
+		Response: votes.FlaggedVotesResponse{},
+	},
+	"POST /admin/votes/flagged/:targetType/:targetId/confirm": {
+		Summary: "Discount a target's suspicious and queued votes; all of them unless usernames are given", Tag: "admin", Auth: openapi.RequiredAuth,
+		Request:  votes.ReviewVotesRequest{},
+		Response: votes.ReviewVotesResponse{},
+	},
+	"POST /admin/votes/flagged/:targetType/:targetId/clear": {
+		Summary: "Clear false positives: count a target's flagged votes again and empty its review queue", Tag: "admin", Auth: openapi.RequiredAuth,
+		Request:  votes.ReviewVotesRequest{},
+		Response: votes.ReviewVotesResponse{},
+	},
+
+	// Moderation queue routes
+	"GET /communities/:communityName/modqueue/posts": {
+		Summary: "Posts held for moderator review", Tag: "moderation", Auth: openapi.RequiredAuth, Paginated: true,
+		Response: posts.PostListResponse{},
+	},
+	"GET /communities/:communityName/modqueue/comments": {
+		Summary: "Comments held for moderator review", Tag: "moderation", Auth: openapi.RequiredAuth, Paginated: true,
+		Response: comments.CommentListResponse{},
+	},
+	"POST /posts/:postId/approve": {
+		Summary: "Approve a held or removed post", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"POST /posts/:postId/remove": {
+		Summary: "Remove a post", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"POST /comments/:commentId/approve": {
+		Summary: "Approve a held or removed comment", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+	"POST /comments/:commentId/remove": {
+		Summary: "Remove a comment", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: messageOnly,
+	},
+
+	// AutoModerator routes
+	"GET /communities/:communityName/automod": {
+		Summary: "Get the community's AutoModerator rules", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Response: communities.AutomodRulesResponse{},
+	},
+	"PUT /communities/:communityName/automod": {
+		Summary: "Replace the AutoModerator rules with a YAML or JSON document", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Request: automod.RuleSet{}, RequestType: "application/yaml",
+		Response: communities.AutomodRulesResponse{},
+	},
+	"POST /communities/:communityName/automod/dry_run": {
+		Summary: "Evaluate AutoModerator rules against a sample item", Tag: "moderation", Auth: openapi.RequiredAuth,
+		Request:  communities.AutomodDryRunRequest{},
+		Response: communities.AutomodDryRunResponse{},
+	},
+
+	// Moderation log routes
+	"GET /communities/:communityName/modlog": {
+		Summary: "List moderator actions", Tag: "moderation", Auth: openapi.RequiredAuth, Paginated: true,
+		Query: []openapi.Param{
+			{Name: "moderator", Description: "Only actions by this moderator"},
+			{Name: "action", Description: "Only this action"},
+			{Name: "from", Description: "Earliest action, RFC 3339", Sample: time.Time{}},
+			{Name: "to", Description: "Latest action, RFC 3339", Sample: time.Time{}},
+		},
+		Response: communities.ModlogResponse{},
+	},
+
+	// Probes
+	"GET /healthz": {
+		Summary: "Report that the process is up", Tag: "ops",
+		Response: health.Report{},
+	},
+	"GET /readyz": {
+		Summary: "Check the database and indexes; 503 when the API should not get traffic", Tag: "ops",
+		Response: health.Report{Checks: map[string]health.CheckResult{}},
+	},
+
+	// Documentation
+	"GET /openapi.json": {
+		Summary: "This document", Tag: "docs",
+		Raw: true, Response: openapi.Document{},
+	},
+}
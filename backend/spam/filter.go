@@ -0,0 +1,132 @@
+package spam
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+// Item is a post or comment about to be created or edited.
+type Item struct {
+	Kind string
+	// ID is the item being edited, or the ID the new item will be stored under.
+	ID primitive.ObjectID
+	// Community is the community the item is posted to; comments use their post's community.
+	Community primitive.ObjectID
+	Settings  Settings
+	Username  string
+	// Edit is set when an existing item is being changed rather than created.
+	Edit  bool
+	Title string
+	Text  string
+	Now   time.Time
+}
+
+// Hash identifies the item's content for duplicate detection. Case and whitespace are ignored.
+func (item Item) Hash() string {
+	return ContentHash(item.Title, item.Text)
+}
+
+// Settings are the spam filter settings a community's moderators control.
+type Settings struct {
+	BannedWords    []string `json:"banned_words" bson:"banned_words,omitempty" binding:"omitempty,max=200,dive,min=1,max=100"`
+	BannedPatterns []string `json:"banned_patterns" bson:"banned_patterns,omitempty" binding:"omitempty,max=50,dive,min=1,max=200"`
+	BlockedDomains []string `json:"blocked_domains" bson:"blocked_domains,omitempty" binding:"omitempty,max=200,dive,min=1,max=253"`
+
+	// wordPatterns and bannedPatterns are BannedWords and BannedPatterns compiled by Compile or when the
+	// settings are read from the database, so checking an item never compiles a pattern again.
+	wordPatterns   []*regexp.Regexp
+	bannedPatterns []*regexp.Regexp
+}
+
+// UnmarshalBSON decodes stored settings and compiles their banned words and patterns.
+func (settings *Settings) UnmarshalBSON(data []byte) error {
+	type storedSettings Settings
+	if err := bson.Unmarshal(data, (*storedSettings)(settings)); err != nil {
+		return err
+	}
+	settings.Compile()
+	return nil
+}
+
+// Compile prepares the banned words and patterns for matching. Patterns are validated when moderators
+// save them, so one that fails to compile is skipped and never matches.
+func (settings *Settings) Compile() {
+	settings.wordPatterns = make([]*regexp.Regexp, len(settings.BannedWords))
+	for i, word := range settings.BannedWords {
+		settings.wordPatterns[i] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+	}
+	settings.bannedPatterns = make([]*regexp.Regexp, len(settings.BannedPatterns))
+	for i, raw := range settings.BannedPatterns {
+		settings.bannedPatterns[i], _ = regexp.Compile(raw)
+	}
+}
+
+// Filter is one check in the chain. It returns a reason when the item should be held for
+// moderator review, and an empty string when the item passes.
+type Filter interface {
+	Name() string
+	Check(ctx context.Context, item Item) (string, error)
+}
+
+// Chain runs filters in order and collects the reasons of every filter that matched.
+type Chain []Filter
+
+// DefaultChain is the chain run by Check. Register adds filters to it.
+var DefaultChain = Chain{
+	BannedContent{},
+	LinkDomains{},
+	DuplicateContent{Window: 24 * time.Hour, MinLength: 20},
+	NewAccountThrottle{MaxAge: 24 * time.Hour, Window: time.Hour, MaxPosts: 2, MaxComments: 5},
+}
+
+// Register appends a filter to DefaultChain. It is meant to be called during start-up.
+func Register(filter Filter) {
+	DefaultChain = append(DefaultChain, filter)
+}
+
+// Check runs DefaultChain against item.
+func Check(ctx context.Context, item Item) []string {
+	return DefaultChain.Check(ctx, item)
+}
+
+// Check returns why item should be held for review, or nothing if it may be published.
+// A filter that fails is logged and skipped so an outage never blocks posting.
+func (chain Chain) Check(ctx context.Context, item Item) []string {
+	if item.Now.IsZero() {
+		item.Now = time.Now()
+	}
+
+	var reasons []string
+	for _, filter := range chain {
+		reason, err := filter.Check(ctx, item)
+		if err != nil {
+			logging.FromContext(ctx).Error("error running spam filter", "filter", filter.Name(), "error", err)
+			continue
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
+
+// ContentHash hashes a title and text after lower-casing them and collapsing whitespace.
+func ContentHash(title string, text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(title)), " ") + "\x00" +
+		strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/configs"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>()\[\]"']+`)
+
+// BannedContent holds items containing one of the community's banned words or matching one of its banned patterns.
+type BannedContent struct{}
+
+func (BannedContent) Name() string { return "banned_content" }
+
+func (BannedContent) Check(ctx context.Context, item Item) (string, error) {
+	content := item.Title + "\n" + item.Text
+	for i, pattern := range item.Settings.wordPatterns {
+		if pattern.MatchString(content) {
+			return fmt.Sprintf("contains banned word %q", item.Settings.BannedWords[i]), nil
+		}
+	}
+	for i, pattern := range item.Settings.bannedPatterns {
+		if pattern != nil && pattern.MatchString(content) {
+			return fmt.Sprintf("matches banned pattern %q", item.Settings.BannedPatterns[i]), nil
+		}
+	}
+	return "", nil
+}
+
+// LinkDomains holds items linking to a domain blocked by the community or site-wide, including its subdomains.
+type LinkDomains struct{}
+
+func (LinkDomains) Name() string { return "link_domains" }
+
+func (LinkDomains) Check(ctx context.Context, item Item) (string, error) {
+	siteWide := configs.Current().SpamBlockedDomains
+	blocked := append(siteWide[:len(siteWide):len(siteWide)], item.Settings.BlockedDomains...)
+	if len(blocked) == 0 {
+		return "", nil
+	}
+
+	for _, link := range linkPattern.FindAllString(item.Title+"\n"+item.Text, -1) {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.ToLower(parsed.Hostname())
+		for _, domain := range blocked {
+			domain = strings.ToLower(strings.TrimSpace(domain))
+			if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+				return fmt.Sprintf("links to blocked domain %s", domain), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// DuplicateContent holds items whose content was already posted by anyone within Window.
+// Items shorter than MinLength are let through, since short replies repeat naturally.
+type DuplicateContent struct {
+	Window    time.Duration
+	MinLength int
+}
+
+func (DuplicateContent) Name() string { return "duplicate_content" }
+
+func (f DuplicateContent) Check(ctx context.Context, item Item) (string, error) {
+	if len(strings.TrimSpace(item.Title+item.Text)) < f.MinLength {
+		return "", nil
+	}
+
+	recent := Recent{ContentHash: item.Hash(), Since: item.Now.Add(-f.Window), ExcludeID: item.ID}
+	count, err := currentStore().CountRecent(ctx, item.Kind, recent)
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return fmt.Sprintf("duplicates a %s submitted in the last %s", item.Kind, f.Window), nil
+	}
+	return "", nil
+}
+
+// NewAccountThrottle holds new items from accounts younger than MaxAge once they have submitted
+// MaxPosts posts or MaxComments comments within Window. Edits are never throttled.
+type NewAccountThrottle struct {
+	MaxAge      time.Duration
+	Window      time.Duration
+	MaxPosts    int64
+	MaxComments int64
+}
+
+func (NewAccountThrottle) Name() string { return "new_account_throttle" }
+
+func (f NewAccountThrottle) Check(ctx context.Context, item Item) (string, error) {
+	if item.Edit {
+		return "", nil
+	}
+
+	created, err := currentStore().AccountCreated(ctx, item.Username)
+	if err != nil {
+		return "", err
+	}
+	if item.Now.Sub(created) >= f.MaxAge {
+		return "", nil
+	}
+
+	limit := f.MaxPosts
+	if item.Kind == KindComment {
+		limit = f.MaxComments
+	}
+	recent := Recent{Username: item.Username, Since: item.Now.Add(-f.Window), ExcludeID: item.ID}
+	count, err := currentStore().CountRecent(ctx, item.Kind, recent)
+	if err != nil {
+		return "", err
+	}
+	if count >= limit {
+		return fmt.Sprintf("new account submitted more than %d %ss in %s", limit, item.Kind, f.Window), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,71 @@
+package spam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type stubFilter struct {
+	reason string
+	err    error
+}
+
+func (stubFilter) Name() string { return "stub" }
+
+func (f stubFilter) Check(ctx context.Context, item Item) (string, error) {
+	return f.reason, f.err
+}
+
+func TestBannedContentMatchesWholeWordsAndPatterns(t *testing.T) {
+	settings := Settings{BannedWords: []string{"casino"}, BannedPatterns: []string{`(?i)free\s+money`}}
+	settings.Compile()
+
+	reason, err := BannedContent{}.Check(context.Background(), Item{Settings: settings, Title: "Best CASINO deals"})
+	assert.NoError(t, err)
+	assert.Contains(t, reason, "casino")
+
+	reason, _ = BannedContent{}.Check(context.Background(), Item{Settings: settings, Text: "casinos near me"})
+	assert.Empty(t, reason)
+
+	reason, _ = BannedContent{}.Check(context.Background(), Item{Settings: settings, Text: "get Free   Money now"})
+	assert.Contains(t, reason, "pattern")
+}
+
+func TestLinkDomainsBlocksSubdomains(t *testing.T) {
+	settings := Settings{BlockedDomains: []string{"spam.example"}}
+
+	reason, err := LinkDomains{}.Check(context.Background(), Item{Settings: settings, Text: "see [this](https://www.SPAM.example/offer)"})
+	assert.NoError(t, err)
+	assert.Contains(t, reason, "spam.example")
+
+	reason, _ = LinkDomains{}.Check(context.Background(), Item{Settings: settings, Text: "https://notspam.example/ is fine"})
+	assert.Empty(t, reason)
+}
+
+func TestChainCollectsReasonsAndSkipsFailures(t *testing.T) {
+	chain := Chain{stubFilter{reason: "first"}, stubFilter{err: errors.New("down")}, stubFilter{}, stubFilter{reason: "second"}}
+
+	assert.Equal(t, []string{"first", "second"}, chain.Check(context.Background(), Item{}))
+}
+
+func TestContentHashIgnoresCaseAndWhitespace(t *testing.T) {
+	assert.Equal(t, ContentHash("Buy  Now", "Great\ndeal"), ContentHash("buy now", "great deal"))
+	assert.NotEqual(t, ContentHash("buy", "now"), ContentHash("buy now", ""))
+}
+
+func TestBannedContentIsCompiledOnce(t *testing.T) {
+	settings := Settings{BannedWords: []string{"casino"}, BannedPatterns: []string{`(?i)free\s+money`}}
+	data, err := bson.Marshal(settings)
+	assert.NoError(t, err)
+
+	var stored Settings
+	assert.NoError(t, bson.Unmarshal(data, &stored))
+	assert.Len(t, stored.wordPatterns, 1, "reading stored settings compiles the banned words")
+	assert.Len(t, stored.bannedPatterns, 1)
+	reason, _ := BannedContent{}.Check(context.Background(), Item{Settings: stored, Text: "free money at the casino"})
+	assert.Contains(t, reason, "casino")
+}
@@ -0,0 +1,37 @@
+package spam
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Recent selects items submitted since Since, other than ExcludeID. Empty fields match every item.
+type Recent struct {
+	ContentHash string
+	Username    string
+	Since       time.Time
+	ExcludeID   primitive.ObjectID
+}
+
+// Store answers the lookups of the filters that compare an item with earlier submissions.
+// It is implemented over the user, post and comment repositories, which import this package.
+type Store interface {
+	// AccountCreated returns when username registered, or common.ErrNotFound.
+	AccountCreated(ctx context.Context, username string) (time.Time, error)
+	// CountRecent counts the items of kind matching recent.
+	CountRecent(ctx context.Context, kind string, recent Recent) (int64, error)
+}
+
+var store common.Holder[Store]
+
+// SetStore installs the store used by the filters.
+func SetStore(s Store) {
+	store.Set(s)
+}
+
+func currentStore() Store {
+	return store.Get()
+}
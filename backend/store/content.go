@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/spam"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// contentStore implements spam.Store and automod.Store over the installed user, post and comment repositories.
+// Those packages import spam and automod, so the adapter lives here rather than beside the interfaces.
+type contentStore struct{}
+
+func (contentStore) AccountCreated(ctx context.Context, username string) (time.Time, error) {
+	user, err := users.Repo().FindByUsername(ctx, username)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return user.ID.Timestamp(), nil
+}
+
+func (contentStore) CountRecent(ctx context.Context, kind string, recent spam.Recent) (int64, error) {
+	if kind == spam.KindComment {
+		return comments.Repo().Count(ctx, comments.CommentQuery{
+			Username:     recent.Username,
+			ContentHash:  recent.ContentHash,
+			CreatedSince: recent.Since,
+			ExcludeID:    recent.ExcludeID,
+		})
+	}
+
+	query := posts.PostQuery{ContentHash: recent.ContentHash, CreatedSince: recent.Since, ExcludeID: recent.ExcludeID}
+	if recent.Username != "" {
+		query.Authors = []string{recent.Username}
+	}
+	return posts.Repo().Count(ctx, query)
+}
+
+func (contentStore) Karma(ctx context.Context, username string) (int, error) {
+	postScore, err := posts.Repo().Score(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	commentScore, err := comments.Repo().Score(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	return postScore + commentScore, nil
+}
+
+func (contentStore) InsertReply(ctx context.Context, postID primitive.ObjectID, communityID primitive.ObjectID, parentID primitive.ObjectID, text string) error {
+	now := time.Now()
+	comment := comments.Comment{
+		ID:           primitive.NewObjectID(),
+		PostID:       postID,
+		Community:    communityID,
+		ParentID:     parentID,
+		Text:         text,
+		Username:     automod.Username,
+		CreationDate: now,
+		UpdationDate: now,
+		Stickied:     parentID.IsZero(),
+	}
+	if err := comments.Repo().Insert(ctx, comment); err != nil {
+		return err
+	}
+	_, err := posts.Repo().AddCounters(ctx, postID, posts.Counters{Comments: 1})
+	return err
+}
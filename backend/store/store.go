@@ -0,0 +1,56 @@
+// Package store installs the repositories the handlers read and write through.
+package store
+
+import (
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/blocks"
+	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/follows"
+	"github.com/ganesh96/simple-reddit/backend/messages"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/notifications"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/profiles"
+	"github.com/ganesh96/simple-reddit/backend/spam"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"github.com/ganesh96/simple-reddit/backend/votes"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UseMongo stores everything in db.
+func UseMongo(db *mongo.Database) {
+	users.SetRepository(users.NewMongoRepository(db))
+	profiles.SetRepository(profiles.NewMongoRepository(db))
+	blocks.SetRepository(blocks.NewMongoRepository(db))
+	follows.SetRepository(follows.NewMongoRepository(db))
+	communities.SetRepository(communities.NewMongoRepository(db))
+	modlog.SetRepository(modlog.NewMongoRepository(db))
+	notifications.SetRepository(notifications.NewMongoRepository(db))
+	messages.SetRepository(messages.NewMongoRepository(db))
+	posts.SetRepository(posts.NewMongoRepository(db))
+	comments.SetRepository(comments.NewMongoRepository(db))
+	votes.SetRepository(votes.NewMongoRepository(db))
+	useContentStore()
+}
+
+// UseMemory stores everything in process memory, starting empty. Tests call it to run without MongoDB.
+func UseMemory() {
+	users.SetRepository(users.NewMemoryRepository())
+	profiles.SetRepository(profiles.NewMemoryRepository())
+	blocks.SetRepository(blocks.NewMemoryRepository())
+	follows.SetRepository(follows.NewMemoryRepository())
+	communities.SetRepository(communities.NewMemoryRepository())
+	modlog.SetRepository(modlog.NewMemoryRepository())
+	notifications.SetRepository(notifications.NewMemoryRepository())
+	messages.SetRepository(messages.NewMemoryRepository())
+	posts.SetRepository(posts.NewMemoryRepository())
+	comments.SetRepository(comments.NewMemoryRepository())
+	votes.SetRepository(votes.NewMemoryRepository())
+	useContentStore()
+}
+
+func useContentStore() {
+	spam.SetStore(contentStore{})
+	automod.SetStore(contentStore{})
+}
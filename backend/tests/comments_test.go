@@ -1,231 +1,118 @@
 package tests
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"fmt"
 	"net/http"
-	"net/http/httptest"
-	"simple-reddit/comments"
-	"simple-reddit/common"
-	"simple-reddit/posts"
-	t_utils "simple-reddit/test_utils"
 	"testing"
 
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var postID string
-var commentID string
-
-const commentBody1 string = "test comment 1"
-
-func CreatePostForComment() string {
-	var resp = common.APIResponse{}
-	// community we know exists. id corresponds to "maths" community.
-	commID, _ := primitive.ObjectIDFromHex("622034f897a3bc4ddc6333cd")
-	var body = posts.CreatePostRequest{
-		UserName:    "albert",
-		CommunityID: commID,
-		Title:       "test post title for comment",
-		Body:        "Lorem ipsum dolor sit amet, consectetur adipiscing elit.",
-	}
-
-	req, err := t_utils.MakeRequest(t_utils.POST, posts.POST_ROUTE_PREFIX, body)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-
-	// add the customed headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-
-	postID = resp.Data["data"].(map[string]interface{})["InsertedID"].(string)
-	return postID
+func TestCommentCRUD(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Discuss")
+
+	comment := createComment(t, john, post.ID, primitive.NilObjectID, "First comment")
+	reply := createComment(t, mary, post.ID, comment.ID, "A reply")
+	assert.Equal(t, comment.ID, reply.ParentID)
+	assert.Equal(t, 2, getPost(t, "", post.ID).CommentsCount)
+
+	missingParent := comments.CreateCommentRequest{Text: "Orphan", ParentID: primitive.NewObjectID()}
+	expectError(t, call(t, "POST", "/posts/"+post.ID.Hex()+"/comments", john, missingParent), http.StatusNotFound, common.COMMENT_NOT_FOUND)
+	expectError(t, call(t, "POST", "/posts/"+post.ID.Hex()+"/comments", john, comments.CreateCommentRequest{}), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+	expectError(t, call(t, "POST", "/posts/"+primitive.NewObjectID().Hex()+"/comments", john, comments.CreateCommentRequest{Text: "Lost"}), http.StatusNotFound, common.POST_NOT_FOUND)
+
+	update := comments.UpdateCommentRequest{Text: "First comment, edited"}
+	expectError(t, call(t, "PUT", "/comments/"+comment.ID.Hex(), mary, update), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "PUT", "/comments/"+comment.ID.Hex(), john, update), http.StatusOK)
+
+	list := expect[comments.CommentListResponse](t, call(t, "GET", "/posts/"+post.ID.Hex()+"/comments", "", nil), http.StatusOK)
+	require.Len(t, list.Comments, 2)
+	assert.Equal(t, "First comment, edited", list.Comments[0].Text)
+	assert.True(t, list.Comments[0].Edited)
+
+	expectError(t, call(t, "DELETE", "/comments/"+comment.ID.Hex(), mary, nil), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "DELETE", "/comments/"+comment.ID.Hex(), john, nil), http.StatusOK)
+	expectError(t, call(t, "DELETE", "/comments/"+comment.ID.Hex(), john, nil), http.StatusForbidden, common.FORBIDDEN)
+	assert.Equal(t, 1, getPost(t, "", post.ID).CommentsCount)
+
+	expectError(t, call(t, "PUT", "/comments/not-an-id", john, update), http.StatusBadRequest, common.INVALID_PARAM)
 }
 
-func TestCreateComment(t *testing.T) {
-	var resp = common.APIResponse{}
-	var expected = common.APIResponse{
-		Status:  http.StatusCreated,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{"created": map[string]string{"InsertedID": ""}},
-	}
-
-	postID := CreatePostForComment()
-
-	body := map[string]interface{}{
-		"username":  "albert",
-		"post_id":   postID,
-		"parent_id": nil,
-		"body":      commentBody1,
-	}
-
-	req, err := t_utils.MakeRequest(t_utils.POST, comments.COMMENTS_ROUTE_PREFIX, body)
-	if err != nil {
-		return
-	}
+func TestCommentPagination(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Count off")
 
-	// add the custom headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
+	var created []comments.Comment
+	for i := 0; i < 3; i++ {
+		created = append(created, createComment(t, mary, post.ID, primitive.NilObjectID, fmt.Sprintf("Comment %d", i)))
 	}
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	path := "/posts/" + post.ID.Hex() + "/comments?limit=2"
+	first := expect[comments.CommentListResponse](t, call(t, "GET", path, "", nil), http.StatusOK)
+	require.Len(t, first.Comments, 2)
+	assert.Equal(t, created[0].ID, first.Comments[0].ID, "comments are listed oldest first")
+	assert.True(t, first.Pagination.HasMore)
 
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
+	second := expect[comments.CommentListResponse](t, call(t, "GET", path+"&after="+first.Pagination.NextCursor, "", nil), http.StatusOK)
+	require.Len(t, second.Comments, 1)
+	assert.Equal(t, created[2].ID, second.Comments[0].ID)
+	assert.False(t, second.Pagination.HasMore)
 
-	assert.Equal(t, 201, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "created")
+	expectError(t, call(t, "GET", "/posts/"+post.ID.Hex()+"/comments?limit=-1", "", nil), http.StatusBadRequest, common.INVALID_PARAM)
 }
 
-func TestGetComment(t *testing.T) {
-	var resp = common.APIResponse{}
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{"comments": map[string]interface{}{}},
-	}
-
-	queryStr := "?" + "post_id=" + postID
-
-	req, err := t_utils.MakeRequest(t_utils.GET, comments.COMMENTS_ROUTE_PREFIX+queryStr, nil)
-	if err != nil {
-		return
-	}
-
-	// add the custom headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
-
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "comments")
-
-	val := resp.Data["comments"]
-	comment := val.([]interface{})[0].(map[string]interface{})
-	log.Println("Comment Data:", comment)
-	commentMessage := comment["body"]
-	assert.Equal(t, commentBody1, commentMessage)
-	assert.Equal(t, float64(0), comment["upvotes"])
-	commentID = comment["_id"].(string)
-}
-
-func TestVoteComment(t *testing.T) {
-	var resp = common.APIResponse{}
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{"result": "voted"},
-	}
-
-	// Upvoting comment created in TestCreateComment()
-	body := comments.CommentVoteRequest{
-		UserName:  "albert",
-		CommentId: commentID,
-		Vote:      comments.UPVOTE,
-	}
-
-	req, err := t_utils.MakeRequest(t_utils.POST, comments.COMMENTS_ROUTE_VOTE, body)
-	if err != nil {
-		return
-	}
-
-	// add the custom headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
+func TestStickiedCommentsAreListedFirst(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Pinned reply")
+
+	first := createComment(t, mary, post.ID, primitive.NilObjectID, "First")
+	second := createComment(t, mary, post.ID, primitive.NilObjectID, "Second")
+	require.NoError(t, automod.Reply(context.Background(), post.ID, community.ID, primitive.NilObjectID, "Please read the rules."))
+
+	path := "/posts/" + post.ID.Hex() + "/comments?limit=1"
+	var listed []comments.Comment
+	for page := expect[comments.CommentListResponse](t, call(t, "GET", path, "", nil), http.StatusOK); ; {
+		listed = append(listed, page.Comments...)
+		if !page.Pagination.HasMore {
+			break
+		}
+		page = expect[comments.CommentListResponse](t, call(t, "GET", path+"&after="+page.Pagination.NextCursor, "", nil), http.StatusOK)
 	}
-
-	log.Println("Vote Response: ", resp.Data)
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "result")
-
-	result := resp.Data["result"].(string)
-	assert.Equal(t, "voted", result)
-	log.Println("All assertions passed for Vote Comment.")
+	require.Len(t, listed, 3)
+	assert.Equal(t, automod.Username, listed[0].Username, "the stickied reply comes first")
+	assert.True(t, listed[0].Stickied)
+	assert.Equal(t, []primitive.ObjectID{first.ID, second.ID}, []primitive.ObjectID{listed[1].ID, listed[2].ID})
 }
 
-func TestDeleteComment(t *testing.T) {
-	var resp = common.APIResponse{}
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{},
-	}
-
-	// Upvoting comment created in TestCreateComment()
-	// queryStr := "?" + "comment_id=" + commentID
-
-	// req, err := t_utils.MakeRequest(t_utils.DELETE, comments.COMMENTS_ROUTE_DELETE+queryStr, nil)
-	var body = comments.DeleteCommentRequest{
-		CommentId: commentID,
-	}
-	req, err := t_utils.MakeRequest(t_utils.POST, comments.COMMENTS_ROUTE_DELETE+"/delete", body)
-	if err != nil {
-		return
-	}
-
-	// add the custom headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
-
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-
-	mongoDeletionResult, ok := resp.Data["deleted"]
-	if !ok {
-		log.Fatal("DeleteComment does not have expected response body. Comment deletion has likely failed.")
-	} else {
-		count, ok := mongoDeletionResult.(map[string]interface{})["ModifiedCount"]
-		if ok {
-			assert.Equal(t, float64(1), count)
-			log.Println("ModifiedCount == 1, which means the comment has been deleted")
-		}
-		log.Println("DeleteComment has expected response body. Comment deleted successfully.")
-	}
+func TestCommentsFromBlockedUsersAreCollapsed(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Discuss")
+	comment := createComment(t, john, post.ID, primitive.NilObjectID, "Unpopular opinion")
+
+	expect[common.MessageResponse](t, call(t, "POST", "/users/john/block", mary, nil), http.StatusOK)
+	list := expect[comments.CommentListResponse](t, call(t, "GET", "/posts/"+post.ID.Hex()+"/comments", mary, nil), http.StatusOK)
+	require.Len(t, list.Comments, 1)
+	assert.True(t, list.Comments[0].Collapsed)
+
+	reply := comments.CreateCommentRequest{Text: "Reply to mary", ParentID: primitive.NilObjectID}
+	expectError(t, call(t, "POST", "/posts/"+post.ID.Hex()+"/comments", john, reply), http.StatusForbidden, common.USER_BLOCKED)
+	createComment(t, mary, post.ID, comment.ID, "Mary can still reply")
 }
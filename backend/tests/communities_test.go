@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/automod"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateAndListCommunities(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	assert.Equal(t, []string{"mary"}, community.Moderators)
+
+	duplicate := map[string]string{"Name": "golang", "Description": "Again"}
+	expectError(t, call(t, "POST", "/communities", mary, duplicate), http.StatusConflict, common.COMMUNITY_ALREADY_EXISTS)
+	badType := map[string]string{"Name": "rust", "Description": "Crabs", "Type": "secret"}
+	expectError(t, call(t, "POST", "/communities", mary, badType), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+	scriptBanner := communities.CreateCommunityRequest{Name: "rust", BannerURL: "javascript:alert(1)"}
+	expectError(t, call(t, "POST", "/communities", mary, scriptBanner), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+	duplicateFlairs := communities.CreateCommunityRequest{Name: "rust", Flairs: []communities.Flair{{Text: "News"}, {Text: "News"}}}
+	expectError(t, call(t, "POST", "/communities", mary, duplicateFlairs), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+	inflated := map[string]interface{}{"name": "python", "members_count": 1000000}
+	created := expect[communities.CommunityResponse](t, call(t, "POST", "/communities", mary, inflated), http.StatusCreated).Community
+	assert.Zero(t, created.MembersCount)
+
+	list := expect[communities.CommunityListResponse](t, call(t, "GET", "/communities", "", nil), http.StatusOK)
+	require.Len(t, list.Communities, 2)
+	assert.Equal(t, "golang", list.Communities[0].Name)
+
+	fetched := expect[communities.CommunityResponse](t, call(t, "GET", "/communities/golang", "", nil), http.StatusOK)
+	assert.Equal(t, community.ID, fetched.Community.ID)
+	assert.Nil(t, fetched.SpamFilter, "only moderators see the spam filter")
+	fetched = expect[communities.CommunityResponse](t, call(t, "GET", "/communities/golang", mary, nil), http.StatusOK)
+	assert.NotNil(t, fetched.SpamFilter)
+	expectError(t, call(t, "GET", "/communities/rust", "", nil), http.StatusNotFound, common.COMMUNITY_NOT_FOUND)
+}
+
+func TestUpdateCommunity(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	createCommunity(t, mary, "golang", communities.TypePublic)
+
+	description := "Everything Go"
+	rules := []communities.Rule{{Title: "Be kind"}, {Title: "Stay on topic"}}
+	update := communities.UpdateCommunityRequest{Description: &description, Rules: &rules}
+	expectError(t, call(t, "PATCH", "/communities/golang", john, update), http.StatusForbidden, common.FORBIDDEN)
+
+	updated := expect[communities.CommunityResponse](t, call(t, "PATCH", "/communities/golang", mary, update), http.StatusOK).Community
+	assert.Equal(t, description, updated.Description)
+	require.Len(t, updated.Rules, 2)
+	assert.Equal(t, 2, updated.Rules[1].Number)
+
+	banner := "ftp://example.com/banner.png"
+	expectError(t, call(t, "PATCH", "/communities/golang", mary, communities.UpdateCommunityRequest{BannerURL: &banner}), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+	flairs := []communities.Flair{{Text: "News"}, {Text: "News"}}
+	expectError(t, call(t, "PATCH", "/communities/golang", mary, communities.UpdateCommunityRequest{Flairs: &flairs}), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+}
+
+func TestRestrictedCommunityMembership(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	anne := signup(t, "anne")
+	community := createCommunity(t, mary, "announcements", communities.TypeRestricted)
+	createPost(t, mary, community.ID, "Welcome")
+
+	post := posts.CreatePostRequest{Title: "Can I post?", Community: community.ID}
+	expectError(t, call(t, "POST", "/posts", john, post), http.StatusForbidden, common.COMMUNITY_ACCESS_DENIED)
+	listing := expect[posts.PostListResponse](t, call(t, "GET", "/posts?community="+community.ID.Hex(), john, nil), http.StatusOK)
+	assert.Len(t, listing.Posts, 1, "restricted communities stay readable")
+
+	expect[common.MessageResponse](t, call(t, "POST", "/communities/announcements/join", john, communities.JoinCommunityRequest{Message: "Please"}), http.StatusAccepted)
+	expect[common.MessageResponse](t, call(t, "POST", "/communities/announcements/join", anne, nil), http.StatusAccepted)
+	expect[common.MessageResponse](t, call(t, "POST", "/communities/announcements/join", mary, nil), http.StatusOK)
+
+	expectError(t, call(t, "GET", "/communities/announcements/join_requests", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	requests := expect[communities.JoinRequestListResponse](t, call(t, "GET", "/communities/announcements/join_requests", mary, nil), http.StatusOK)
+	require.Len(t, requests.JoinRequests, 2)
+	assert.Equal(t, "john", requests.JoinRequests[0].Username)
+	assert.Equal(t, "Please", requests.JoinRequests[0].Message)
+
+	expect[common.MessageResponse](t, call(t, "DELETE", "/communities/announcements/join_requests/anne", mary, nil), http.StatusOK)
+	expectError(t, call(t, "DELETE", "/communities/announcements/join_requests/anne", mary, nil), http.StatusNotFound, common.JOIN_REQUEST_NOT_FOUND)
+
+	expectError(t, call(t, "POST", "/communities/announcements/approved_users/nobody", mary, nil), http.StatusNotFound, common.USER_NOT_FOUND)
+	expect[common.MessageResponse](t, call(t, "POST", "/communities/announcements/approved_users/john", mary, nil), http.StatusOK)
+	roster := expect[communities.ApprovedUsersResponse](t, call(t, "GET", "/communities/announcements/approved_users", mary, nil), http.StatusOK)
+	assert.Equal(t, []string{"john"}, roster.ApprovedUsers)
+	expectError(t, call(t, "GET", "/communities/announcements/approved_users", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	public := call(t, "GET", "/communities/announcements", john, nil)
+	assert.NotContains(t, public.Body.String(), "approved_users")
+	requests = expect[communities.JoinRequestListResponse](t, call(t, "GET", "/communities/announcements/join_requests", mary, nil), http.StatusOK)
+	assert.Empty(t, requests.JoinRequests)
+	createPost(t, john, community.ID, "Thanks for having me")
+
+	expect[common.MessageResponse](t, call(t, "DELETE", "/communities/announcements/approved_users/john", mary, nil), http.StatusOK)
+	expectError(t, call(t, "POST", "/posts", john, post), http.StatusForbidden, common.COMMUNITY_ACCESS_DENIED)
+}
+
+func TestDeleteCommunity(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	root := admin(t, "root")
+	createCommunity(t, mary, "golang", communities.TypePublic)
+	createCommunity(t, mary, "rust", communities.TypePublic)
+
+	expectError(t, call(t, "DELETE", "/communities/golang", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "DELETE", "/communities/golang", mary, nil), http.StatusOK)
+	expectError(t, call(t, "GET", "/communities/golang", "", nil), http.StatusNotFound, common.COMMUNITY_NOT_FOUND)
+	expectError(t, call(t, "DELETE", "/communities/golang", mary, nil), http.StatusNotFound, common.COMMUNITY_NOT_FOUND)
+
+	expect[common.MessageResponse](t, call(t, "DELETE", "/communities/rust", root, nil), http.StatusOK)
+
+	expectError(t, call(t, "GET", "/admin/modlog?community=golang", mary, nil), http.StatusForbidden, common.FORBIDDEN)
+	expectError(t, call(t, "GET", "/admin/modlog", root, nil), http.StatusBadRequest, common.INVALID_PARAM)
+	entries := expect[communities.ModlogResponse](t, call(t, "GET", "/admin/modlog?community=golang", root, nil), http.StatusOK).Entries
+	require.Len(t, entries, 1, "the log of a deleted community stays readable")
+	assert.Equal(t, modlog.ActionDeleteCommunity, entries[0].Action)
+	assert.Equal(t, "mary", entries[0].Moderator)
+
+	entries = expect[communities.ModlogResponse](t, call(t, "GET", "/admin/modlog?community=rust&moderator=root", root, nil), http.StatusOK).Entries
+	require.Len(t, entries, 1)
+	assert.True(t, entries[0].Admin)
+}
+
+func TestAutomodRules(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+
+	expectError(t, call(t, "GET", "/communities/golang/automod", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	rules := expect[communities.AutomodRulesResponse](t, call(t, "GET", "/communities/golang/automod", mary, nil), http.StatusOK).Rules
+	assert.Empty(t, rules)
+
+	document := strings.Join([]string{
+		"rules:",
+		"  - name: no shouting",
+		"    title_matches: '^[A-Z !]+$'",
+		"    action: remove",
+		"    action_reason: Please do not shout",
+	}, "\n")
+	w := callRaw(t, "PUT", "/communities/golang/automod", mary, "application/yaml", document)
+	rules = expect[communities.AutomodRulesResponse](t, w, http.StatusOK).Rules
+	require.Len(t, rules, 1)
+	assert.Equal(t, "no shouting", rules[0].Name)
+
+	invalid := callRaw(t, "PUT", "/communities/golang/automod", mary, "application/yaml", "rules:\n  - name: broken\n    title_matches: '('\n")
+	expectError(t, invalid, http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+
+	shouting := communities.AutomodDryRunRequest{Item: automod.Item{Type: "post", Title: "HELLO WORLD"}}
+	result := expect[communities.AutomodDryRunResponse](t, call(t, "POST", "/communities/golang/automod/dry_run", mary, shouting), http.StatusOK)
+	assert.Equal(t, common.ModStatusRemoved, result.ModStatus)
+	assert.NotEmpty(t, result.Reasons)
+
+	quiet := communities.AutomodDryRunRequest{Item: automod.Item{Type: "post", Title: "Hello world"}}
+	result = expect[communities.AutomodDryRunResponse](t, call(t, "POST", "/communities/golang/automod/dry_run", mary, quiet), http.StatusOK)
+	assert.Empty(t, result.ModStatus)
+
+	removed := createPost(t, john, community.ID, "HELLO WORLD")
+	assert.Equal(t, common.ModStatusRemoved, removed.ModStatus)
+}
+
+// unavailableAuthors fails every author lookup AutoModerator makes.
+type unavailableAuthors struct {
+	automod.Store
+}
+
+func (unavailableAuthors) AccountCreated(ctx context.Context, username string) (time.Time, error) {
+	return time.Time{}, errors.New("database unavailable")
+}
+
+func (unavailableAuthors) Karma(ctx context.Context, username string) (int, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func TestAutomodFailsClosed(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Discuss")
+
+	document := "rules:\n  - name: new accounts\n    author:\n      account_age_below_days: 1\n    action: filter\n"
+	expect[communities.AutomodRulesResponse](t, callRaw(t, "PUT", "/communities/golang/automod", mary, "application/yaml", document), http.StatusOK)
+	automod.SetStore(unavailableAuthors{})
+
+	held := createPost(t, john, community.ID, "Hello")
+	assert.Equal(t, common.ModStatusFiltered, held.ModStatus, "a post the rules could not check is held")
+	comment := createComment(t, john, post.ID, primitive.NilObjectID, "Hello")
+	assert.Equal(t, common.ModStatusFiltered, comment.ModStatus, "a comment the rules could not check is held")
+
+	queue := expect[posts.PostListResponse](t, call(t, "GET", "/communities/golang/modqueue/posts", mary, nil), http.StatusOK)
+	require.Len(t, queue.Posts, 1)
+	assert.Contains(t, queue.Posts[0].FilterReasons, automod.UnevaluatedReason)
+}
+
+func TestModlog(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, john, community.ID, "Pin me")
+
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+post.ID.Hex()+"/pin", mary, nil), http.StatusOK)
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+post.ID.Hex()+"/lock", mary, nil), http.StatusOK)
+
+	expectError(t, call(t, "GET", "/communities/golang/modlog", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	entries := expect[communities.ModlogResponse](t, call(t, "GET", "/communities/golang/modlog", mary, nil), http.StatusOK)
+	require.Len(t, entries.Entries, 2)
+	assert.Equal(t, modlog.ActionLockPost, entries.Entries[0].Action)
+	assert.Equal(t, modlog.ActionPinPost, entries.Entries[1].Action)
+	assert.Equal(t, "mary", entries.Entries[0].Moderator)
+
+	filtered := expect[communities.ModlogResponse](t, call(t, "GET", "/communities/golang/modlog?action="+modlog.ActionPinPost, mary, nil), http.StatusOK)
+	require.Len(t, filtered.Entries, 1)
+	assert.Equal(t, post.ID, filtered.Entries[0].TargetID)
+
+	expectError(t, call(t, "GET", "/communities/golang/modlog?from=yesterday", mary, nil), http.StatusBadRequest, common.INVALID_PARAM)
+}
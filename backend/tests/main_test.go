@@ -1,77 +1,185 @@
+// Package tests exercises every route registered by routes.SetupRoutes end to end, against the in-memory store.
+// It needs no database: go test ./tests runs offline.
 package tests
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
-	"time"
 
-	"simple-reddit/comments"
-	"simple-reddit/communities"
-	"simple-reddit/configs"
-	"simple-reddit/posts"
-	"simple-reddit/profiles"
-	"simple-reddit/routes"
-	"simple-reddit/users"
+	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/routes"
+	"github.com/ganesh96/simple-reddit/backend/store"
+	t_utils "github.com/ganesh96/simple-reddit/backend/test_utils"
+	"github.com/ganesh96/simple-reddit/backend/users"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const password = "password123"
 
-	"go.mongodb.org/mongo-driver/mongo"
+var router *gin.Engine
+
+// calledRoutes records every route a test reached, keyed like "GET /posts/:postId".
+var (
+	calledMu     sync.Mutex
+	calledRoutes = map[string]bool{}
 )
 
-var router http.Handler
+func TestMain(m *testing.M) {
+	os.Setenv("SECRET_KEY", "integration-test-secret")
+	gin.SetMode(gin.TestMode)
 
-// custom request headers for token authorization and so on
-var customHeaders = make(map[string]string, 0)
+	router = gin.New()
+	router.Use(func(c *gin.Context) {
+		if route := c.FullPath(); route != "" {
+			calledMu.Lock()
+			calledRoutes[c.Request.Method+" "+route] = true
+			calledMu.Unlock()
+		}
+		c.Next()
+	})
+	routes.SetupRoutes(router)
 
-func init() {
-	router = routes.SetupRouter()
-	configs.LoadEnvVariables()
+	code := m.Run()
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		if missing := uncalledRoutes(); len(missing) > 0 {
+			fmt.Println("FAIL: routes no test called:\n\t" + strings.Join(missing, "\n\t"))
+			code = 1
+		}
+	}
+	os.Exit(code)
 }
 
-func AddHeader(key string, value string) {
-	customHeaders[key] = value
-}
+func uncalledRoutes() []string {
+	calledMu.Lock()
+	defer calledMu.Unlock()
 
-func CreateCollections(MongoDB *mongo.Database) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	collections := []string{users.UsersCollectionName, communities.CommunitiesCollectionName}
-	for i := range collections {
-		MongoDB.CreateCollection(ctx, collections[i])
+	var missing []string
+	for _, route := range router.Routes() {
+		if key := route.Method + " " + route.Path; !calledRoutes[key] {
+			missing = append(missing, key)
+		}
 	}
+	sort.Strings(missing)
+	return missing
 }
 
-func ReplaceCollectionObjects(MongoDB *mongo.Database) {
-	users.UsersCollection = configs.GetCollection(MongoDB, users.UsersCollectionName)
-	profiles.ProfileCollection = configs.GetCollection(MongoDB, profiles.ProfilesCollectionName)
-	profiles.UsersCollection = configs.GetCollection(MongoDB, profiles.UsersCollectionName)
-	profiles.CommentsCollection = configs.GetCollection(MongoDB, profiles.CommentsCollectionName)
-	profiles.SavedCollection = configs.GetCollection(MongoDB, profiles.SavedCollectionName)
-	profiles.PostsCollection = configs.GetCollection(MongoDB, profiles.PostsCollectionName)
-	communities.CommunityCollection = configs.GetCollection(MongoDB, communities.CommunitiesCollectionName)
-	posts.PostsCollection = configs.GetCollection(MongoDB, posts.PostsCollectionName)
-	comments.CommentsCollection = configs.GetCollection(MongoDB, comments.CommentsCollectionName)
+// setup gives the test an empty store.
+func setup(t *testing.T) {
+	t.Helper()
+	store.UseMemory()
 }
 
-func TestMain(m *testing.M) {
-	log.Println("Database setup for test")
-	// user and password will need to match running postgres instance
-	configs.MongoDB = configs.MongoClient.Database(os.Getenv("TEST_DB_NAME"))
-	// Check if DB is connected
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	err := configs.MongoClient.Ping(ctx, nil)
-	if err != nil {
-		log.Println(err.Error())
-		panic(err)
+// call sends a JSON request to the router, authenticated when token is set.
+func call(t *testing.T, method string, path string, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := t_utils.MakeRequest(method, path, body)
+	require.NoError(t, err)
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
-	CreateCollections(configs.MongoDB)
-	ReplaceCollectionObjects(configs.MongoDB)
 
-	exitVal := m.Run()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// callRaw sends body as is with the given content type, for endpoints that do not take JSON.
+func callRaw(t *testing.T, method string, path string, token string, contentType string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// expect checks the status of a response and decodes its data into T.
+func expect[T any](t *testing.T, w *httptest.ResponseRecorder, status int) T {
+	t.Helper()
+	require.Equal(t, status, w.Code, w.Body.String())
+
+	var envelope common.Envelope[T]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope), w.Body.String())
+	require.Equal(t, status, envelope.Status)
+	return envelope.Data
+}
+
+// expectError checks the status and error code of a failed request.
+func expectError(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	require.Equal(t, status, w.Code, w.Body.String())
+
+	var envelope common.Envelope[common.ErrorData]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope), w.Body.String())
+	require.Equal(t, code, envelope.Code)
+}
+
+// signup registers username through the API and logs in, returning a bearer token.
+func signup(t *testing.T, username string) string {
+	t.Helper()
+	email := username + "@example.com"
+	body := map[string]string{"Username": username, "Email": email, "Password": password}
+	expect[common.MessageResponse](t, call(t, t_utils.POST, "/signup", "", body), http.StatusCreated)
+	return login(t, email)
+}
+
+func login(t *testing.T, email string) string {
+	t.Helper()
+	body := users.LoginDetails{Email: email, Password: password}
+	return expect[users.LoginResponse](t, call(t, t_utils.POST, "/login", "", body), http.StatusOK).Token
+}
 
-	log.Println("Database dropped after test")
+// admin stores a site administrator directly, since no route grants the role, and returns a token for them.
+func admin(t *testing.T, username string) string {
+	t.Helper()
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := common.User{ID: primitive.NewObjectID(), Username: username, Email: username + "@example.com", Password: string(hashed), IsAdmin: true}
+	require.NoError(t, users.Repo().Create(context.Background(), user))
+	return login(t, user.Email)
+}
+
+func createCommunity(t *testing.T, token string, name string, communityType string) communities.Community {
+	t.Helper()
+	body := communities.CreateCommunityRequest{Name: name, Description: "All about " + name, Type: communityType}
+	return expect[communities.CommunityResponse](t, call(t, t_utils.POST, "/communities", token, body), http.StatusCreated).Community
+}
+
+func createPost(t *testing.T, token string, communityID primitive.ObjectID, title string) posts.Post {
+	t.Helper()
+	body := posts.CreatePostRequest{Title: title, Text: "Body of " + title, Community: communityID}
+	return expect[posts.PostResponse](t, call(t, t_utils.POST, "/posts", token, body), http.StatusCreated).Post
+}
+
+func createComment(t *testing.T, token string, postID primitive.ObjectID, parentID primitive.ObjectID, text string) comments.Comment {
+	t.Helper()
+	body := comments.CreateCommentRequest{Text: text, ParentID: parentID}
+	return expect[comments.CommentResponse](t, call(t, t_utils.POST, "/posts/"+postID.Hex()+"/comments", token, body), http.StatusCreated).Comment
+}
 
-	os.Exit(exitVal)
+func getPost(t *testing.T, token string, postID primitive.ObjectID) posts.Post {
+	t.Helper()
+	return expect[posts.PostResponse](t, call(t, t_utils.GET, "/posts/"+postID.Hex(), token, nil), http.StatusOK).Post
 }
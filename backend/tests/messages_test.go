@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/messages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func sendMessage(t *testing.T, token string, to string, text string) messages.Message {
+	t.Helper()
+	return expect[messages.SendMessageResponse](t, call(t, "POST", "/messages", token, messages.SendMessageRequest{To: to, Text: text}), http.StatusCreated).DirectMessage
+}
+
+func TestDirectMessages(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	anne := signup(t, "anne")
+
+	first := sendMessage(t, mary, "john", "Hi john")
+	reply := sendMessage(t, john, "mary", "Hi mary")
+	sendMessage(t, mary, "john", "How are you?")
+	assert.Equal(t, first.ConversationID, reply.ConversationID, "both directions share one conversation")
+
+	expectError(t, call(t, "POST", "/messages", mary, messages.SendMessageRequest{To: "mary", Text: "Me"}), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+	expectError(t, call(t, "POST", "/messages", mary, messages.SendMessageRequest{To: "nobody", Text: "Hello?"}), http.StatusNotFound, common.USER_NOT_FOUND)
+
+	inbox := expect[messages.ConversationListResponse](t, call(t, "GET", "/messages", john, nil), http.StatusOK)
+	require.Len(t, inbox.Conversations, 1)
+	assert.Equal(t, int64(2), inbox.Conversations[0].UnreadCount)
+	assert.Equal(t, "How are you?", inbox.Conversations[0].LastMessagePreview)
+
+	path := "/messages/" + first.ConversationID.Hex()
+	conversation := expect[messages.ConversationResponse](t, call(t, "GET", path, john, nil), http.StatusOK)
+	require.Len(t, conversation.Messages, 3)
+	assert.Equal(t, "How are you?", conversation.Messages[0].Text, "messages are listed newest first")
+
+	expectError(t, call(t, "GET", path, anne, nil), http.StatusNotFound, common.CONVERSATION_NOT_FOUND)
+	expectError(t, call(t, "GET", "/messages/"+primitive.NewObjectID().Hex(), john, nil), http.StatusNotFound, common.CONVERSATION_NOT_FOUND)
+	expectError(t, call(t, "PUT", "/messages/nope/read", john, nil), http.StatusBadRequest, common.INVALID_PARAM)
+
+	updated := expect[common.UpdatedResponse](t, call(t, "PUT", path+"/read", john, nil), http.StatusOK).Updated
+	assert.Equal(t, int64(2), updated)
+	inbox = expect[messages.ConversationListResponse](t, call(t, "GET", "/messages", john, nil), http.StatusOK)
+	assert.Equal(t, int64(0), inbox.Conversations[0].UnreadCount)
+	inbox = expect[messages.ConversationListResponse](t, call(t, "GET", "/messages", mary, nil), http.StatusOK)
+	assert.Equal(t, int64(1), inbox.Conversations[0].UnreadCount)
+}
+
+func TestBlockedUsersCannotMessage(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+
+	expect[common.MessageResponse](t, call(t, "POST", "/users/john/block", mary, nil), http.StatusOK)
+	expectError(t, call(t, "POST", "/messages", john, messages.SendMessageRequest{To: "mary", Text: "Hello"}), http.StatusForbidden, common.USER_BLOCKED)
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/spam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// spamFilteredCommunity creates a community owned by mary whose spam filter holds anything mentioning a casino.
+func spamFilteredCommunity(t *testing.T, mary string) communities.Community {
+	t.Helper()
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	filter := spam.Settings{BannedWords: []string{"casino"}}
+	expect[communities.CommunityResponse](t, call(t, "PATCH", "/communities/golang", mary, communities.UpdateCommunityRequest{SpamFilter: &filter}), http.StatusOK)
+	return community
+}
+
+func TestPostModQueue(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := spamFilteredCommunity(t, mary)
+
+	held := createPost(t, john, community.ID, "Visit my casino")
+	assert.Equal(t, common.ModStatusFiltered, held.ModStatus)
+	assert.Empty(t, held.FilterReasons, "authors are not told what the filter matched")
+	fetched := expect[posts.PostResponse](t, call(t, "GET", "/posts/"+held.ID.Hex(), john, nil), http.StatusOK).Post
+	assert.Empty(t, fetched.FilterReasons)
+	listing := expect[posts.PostListResponse](t, call(t, "GET", "/posts", "", nil), http.StatusOK)
+	assert.Empty(t, listing.Posts, "filtered posts wait for a moderator")
+
+	expectError(t, call(t, "GET", "/communities/golang/modqueue/posts", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	queue := expect[posts.PostListResponse](t, call(t, "GET", "/communities/golang/modqueue/posts", mary, nil), http.StatusOK)
+	require.Len(t, queue.Posts, 1)
+	assert.Equal(t, held.ID, queue.Posts[0].ID)
+	assert.NotEmpty(t, queue.Posts[0].FilterReasons, "moderators see why a post was held")
+
+	expectError(t, call(t, "POST", "/posts/"+held.ID.Hex()+"/approve", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+held.ID.Hex()+"/approve", mary, nil), http.StatusOK)
+	assert.Equal(t, "Post already approved", expect[common.MessageResponse](t, call(t, "POST", "/posts/"+held.ID.Hex()+"/approve", mary, nil), http.StatusOK).Message)
+	listing = expect[posts.PostListResponse](t, call(t, "GET", "/posts", "", nil), http.StatusOK)
+	assert.Len(t, listing.Posts, 1)
+	queue = expect[posts.PostListResponse](t, call(t, "GET", "/communities/golang/modqueue/posts", mary, nil), http.StatusOK)
+	assert.Empty(t, queue.Posts)
+
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+held.ID.Hex()+"/remove", mary, nil), http.StatusOK)
+	assert.Equal(t, "Post already removed", expect[common.MessageResponse](t, call(t, "POST", "/posts/"+held.ID.Hex()+"/remove", mary, nil), http.StatusOK).Message)
+	listing = expect[posts.PostListResponse](t, call(t, "GET", "/posts", "", nil), http.StatusOK)
+	assert.Empty(t, listing.Posts)
+
+	expectError(t, call(t, "POST", "/posts/"+primitive.NewObjectID().Hex()+"/approve", mary, nil), http.StatusNotFound, common.POST_NOT_FOUND)
+}
+
+func TestCommentModQueue(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := spamFilteredCommunity(t, mary)
+	post := createPost(t, mary, community.ID, "Discuss")
+
+	held := createComment(t, john, post.ID, primitive.NilObjectID, "Try my casino")
+	assert.Equal(t, common.ModStatusFiltered, held.ModStatus)
+	assert.Empty(t, held.FilterReasons, "authors are not told what the filter matched")
+	list := expect[comments.CommentListResponse](t, call(t, "GET", "/posts/"+post.ID.Hex()+"/comments", "", nil), http.StatusOK)
+	assert.Empty(t, list.Comments)
+
+	expectError(t, call(t, "GET", "/communities/golang/modqueue/comments", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	queue := expect[comments.CommentListResponse](t, call(t, "GET", "/communities/golang/modqueue/comments", mary, nil), http.StatusOK)
+	require.Len(t, queue.Comments, 1)
+	assert.Equal(t, held.ID, queue.Comments[0].ID)
+	assert.NotEmpty(t, queue.Comments[0].FilterReasons, "moderators see why a comment was held")
+
+	expectError(t, call(t, "POST", "/comments/"+held.ID.Hex()+"/remove", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "POST", "/comments/"+held.ID.Hex()+"/remove", mary, nil), http.StatusOK)
+	queue = expect[comments.CommentListResponse](t, call(t, "GET", "/communities/golang/modqueue/comments", mary, nil), http.StatusOK)
+	assert.Empty(t, queue.Comments)
+
+	expect[common.MessageResponse](t, call(t, "POST", "/comments/"+held.ID.Hex()+"/approve", mary, nil), http.StatusOK)
+	list = expect[comments.CommentListResponse](t, call(t, "GET", "/posts/"+post.ID.Hex()+"/comments", "", nil), http.StatusOK)
+	require.Len(t, list.Comments, 1)
+	assert.Equal(t, 1, getPost(t, "", post.ID).CommentsCount)
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func unreadCount(t *testing.T, token string) int64 {
+	t.Helper()
+	return expect[notifications.UnreadCountResponse](t, call(t, "GET", "/notifications/unread_count", token, nil), http.StatusOK).UnreadCount
+}
+
+func TestRepliesAndMentionsNotify(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	anne := signup(t, "anne")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Discuss")
+
+	comment := createComment(t, john, post.ID, primitive.NilObjectID, "What do you think, u/anne?")
+	createComment(t, mary, post.ID, comment.ID, "Thanks john")
+	createComment(t, mary, post.ID, primitive.NilObjectID, "Replying to my own post")
+
+	list := expect[notifications.NotificationListResponse](t, call(t, "GET", "/notifications", mary, nil), http.StatusOK)
+	require.Len(t, list.Notifications, 1)
+	assert.Equal(t, notifications.TypePostReply, list.Notifications[0].Type)
+	assert.Equal(t, "john", list.Notifications[0].Actor)
+
+	list = expect[notifications.NotificationListResponse](t, call(t, "GET", "/notifications", john, nil), http.StatusOK)
+	require.Len(t, list.Notifications, 1)
+	assert.Equal(t, notifications.TypeCommentReply, list.Notifications[0].Type)
+
+	list = expect[notifications.NotificationListResponse](t, call(t, "GET", "/notifications", anne, nil), http.StatusOK)
+	require.Len(t, list.Notifications, 1)
+	assert.Equal(t, notifications.TypeMention, list.Notifications[0].Type)
+	assert.Equal(t, comment.ID, list.Notifications[0].CommentID)
+
+	expect[common.MessageResponse](t, call(t, "POST", "/users/john/block", anne, nil), http.StatusOK)
+	createComment(t, john, post.ID, primitive.NilObjectID, "Still there, u/anne?")
+	list = expect[notifications.NotificationListResponse](t, call(t, "GET", "/notifications", anne, nil), http.StatusOK)
+	assert.Len(t, list.Notifications, 1, "mentions by blocked users are dropped")
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Discuss")
+	createComment(t, john, post.ID, primitive.NilObjectID, "One")
+	createComment(t, john, post.ID, primitive.NilObjectID, "Two")
+	createComment(t, john, post.ID, primitive.NilObjectID, "Three")
+	assert.Equal(t, int64(3), unreadCount(t, mary))
+
+	list := expect[notifications.NotificationListResponse](t, call(t, "GET", "/notifications", mary, nil), http.StatusOK)
+	require.Len(t, list.Notifications, 3)
+	first := list.Notifications[0].ID.Hex()
+	expectError(t, call(t, "PUT", "/notifications/"+first+"/read", john, nil), http.StatusNotFound, common.NOTIFICATION_NOT_FOUND)
+	expect[common.MessageResponse](t, call(t, "PUT", "/notifications/"+first+"/read", mary, nil), http.StatusOK)
+	assert.Equal(t, int64(2), unreadCount(t, mary))
+	expectError(t, call(t, "PUT", "/notifications/nope/read", mary, nil), http.StatusBadRequest, common.INVALID_PARAM)
+
+	updated := expect[common.UpdatedResponse](t, call(t, "PUT", "/notifications/read", mary, nil), http.StatusOK).Updated
+	assert.Equal(t, int64(2), updated)
+	assert.Equal(t, int64(0), unreadCount(t, mary))
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Discuss")
+
+	prefs := expect[notifications.PreferencesResponse](t, call(t, "GET", "/notifications/preferences", mary, nil), http.StatusOK).Preferences
+	assert.True(t, prefs.PostReplies)
+	assert.True(t, prefs.Mentions)
+
+	off := false
+	prefs = expect[notifications.PreferencesResponse](t, call(t, "PUT", "/notifications/preferences", mary, notifications.UpdatePreferencesRequest{PostReplies: &off}), http.StatusOK).Preferences
+	assert.False(t, prefs.PostReplies)
+	assert.True(t, prefs.Mentions, "preferences missing from the request are kept")
+
+	createComment(t, john, post.ID, primitive.NilObjectID, "Mary will not hear about this")
+	assert.Equal(t, int64(0), unreadCount(t, mary))
+}
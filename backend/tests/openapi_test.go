@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDocumentListsEveryRoute(t *testing.T) {
+	setup(t)
+	w := call(t, "GET", "/openapi.json", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Contains(t, doc.Paths, "/posts/{postId}/comments")
+	assert.Contains(t, doc.Paths, "/communities/{communityName}/automod/dry_run")
+	for _, route := range router.Routes() {
+		segments := strings.Split(route.Path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + segment[1:] + "}"
+			}
+		}
+		assert.Contains(t, doc.Paths, strings.Join(segments, "/"), route.Method+" "+route.Path)
+	}
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/health"
+	"github.com/ganesh96/simple-reddit/backend/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbes(t *testing.T) {
+	setup(t)
+
+	live := expect[health.Report](t, call(t, "GET", "/healthz", "", nil), http.StatusOK)
+	assert.Equal(t, health.StatusOK, live.Status)
+
+	// The in-memory store has nothing to check, so it is ready as soon as it is installed.
+	ready := expect[health.Report](t, call(t, "GET", "/readyz", "", nil), http.StatusOK)
+	assert.Equal(t, health.StatusOK, ready.Status)
+	assert.Empty(t, ready.Checks)
+}
+
+// scrape returns the value of one series from the metrics handler, or 0 when it has not been written yet.
+func scrape(t *testing.T, series string) float64 {
+	t.Helper()
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if value := strings.TrimPrefix(line, series+" "); value != line {
+			parsed, err := strconv.ParseFloat(value, 64)
+			require.NoError(t, err, line)
+			return parsed
+		}
+	}
+	return 0
+}
+
+func TestMetricsCountActivity(t *testing.T) {
+	setup(t)
+	signups := scrape(t, "simple_reddit_signups_total")
+	visiblePosts := scrape(t, `simple_reddit_posts_created_total{status="visible"}`)
+	upvotes := scrape(t, `simple_reddit_votes_cast_total{direction="up",suspicious="false",target_type="post"}`)
+
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Counted")
+	vote(t, john, "/posts/"+post.ID.Hex(), 1)
+
+	assert.Equal(t, signups+2, scrape(t, "simple_reddit_signups_total"))
+	assert.Equal(t, visiblePosts+1, scrape(t, `simple_reddit_posts_created_total{status="visible"}`))
+	// Metrics are only served on their own listener, never by the API.
+	assert.Equal(t, http.StatusNotFound, call(t, "GET", "/metrics", "", nil).Code)
+
+	assert.Equal(t, upvotes+1, scrape(t, `simple_reddit_votes_cast_total{direction="up",suspicious="false",target_type="post"}`))
+}
@@ -1,99 +1,320 @@
 package tests
 
 import (
-	"encoding/json"
-	"log"
+	"bufio"
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"simple-reddit/common"
-	"simple-reddit/posts"
-	t_utils "simple-reddit/test_utils"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/users"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func TestCreatePost(t *testing.T) {
-	var resp = common.APIResponse{}
-	commID, err := primitive.ObjectIDFromHex("622034f897a3bc4ddc6333cd")
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	var body = posts.CreatePostRequest{
-		UserName:    "johndoe",
-		CommunityID: commID,
-		Title:       "alpha post!!",
-		Body:        "this really an alpha post!!",
-	}
-	var expected = common.APIResponse{
-		Status:  http.StatusCreated,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{"data": ""},
-	}
+func TestPostCRUD(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
 
-	req, err := t_utils.MakeRequest(t_utils.POST, posts.POST_ROUTE_PREFIX, body)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	post := createPost(t, mary, community.ID, "Hello gophers")
+	assert.Equal(t, "mary", post.Username)
+	assert.Equal(t, "<p>Body of Hello gophers</p>\n", post.TextHTML)
 
-	// add the customed headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
+	fetched := getPost(t, "", post.ID)
+	assert.Equal(t, post.Title, fetched.Title)
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	update := posts.UpdatePostRequest{Title: "Hello again", Text: "Edited body"}
+	expectError(t, call(t, "PUT", "/posts/"+post.ID.Hex(), john, update), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "PUT", "/posts/"+post.ID.Hex(), mary, update), http.StatusOK)
+	fetched = getPost(t, "", post.ID)
+	assert.Equal(t, "Hello again", fetched.Title)
+	assert.Equal(t, "Edited body", fetched.Text)
 
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	expectError(t, call(t, "DELETE", "/posts/"+post.ID.Hex(), john, nil), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "DELETE", "/posts/"+post.ID.Hex(), mary, nil), http.StatusOK)
+	expectError(t, call(t, "GET", "/posts/"+post.ID.Hex(), "", nil), http.StatusNotFound, common.POST_NOT_FOUND)
+	expectError(t, call(t, "DELETE", "/posts/"+post.ID.Hex(), mary, nil), http.StatusNotFound, common.POST_NOT_FOUND)
+}
+
+func TestCreatePostValidation(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
 
-	log.Println(resp)
+	missingTitle := posts.CreatePostRequest{Community: community.ID}
+	expectError(t, call(t, "POST", "/posts", mary, missingTitle), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+	unknownCommunity := posts.CreatePostRequest{Title: "Lost", Community: primitive.NewObjectID()}
+	expectError(t, call(t, "POST", "/posts", mary, unknownCommunity), http.StatusNotFound, common.COMMUNITY_NOT_FOUND)
+	unknownFlair := posts.CreatePostRequest{Title: "Flair", Community: community.ID, Flair: "News"}
+	expectError(t, call(t, "POST", "/posts", mary, unknownFlair), http.StatusBadRequest, common.INVALID_FLAIR)
 
-	assert.Equal(t, 201, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
+	expectError(t, call(t, "GET", "/posts/not-an-id", "", nil), http.StatusBadRequest, common.INVALID_PARAM)
+	expectError(t, call(t, "GET", "/posts/"+primitive.NewObjectID().Hex(), "", nil), http.StatusNotFound, common.POST_NOT_FOUND)
 }
 
-func TestGetPost(t *testing.T) {
-	var resp = common.APIResponse{}
-	commID, err := primitive.ObjectIDFromHex("622034f897a3bc4ddc6333cd")
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	var body = posts.CreatePostRequest{
-		UserName:    "johndoe",
-		CommunityID: commID,
-	}
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{"data": ""},
-	}
+func TestPostPagination(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
 
-	req, err := t_utils.MakeRequest(t_utils.GET, posts.POST_ROUTE_PREFIX, body)
-	if err != nil {
-		return
+	// Moderators are never screened by the new account throttle, so mary can post as often as needed.
+	var created []posts.Post
+	for i := 0; i < 5; i++ {
+		created = append(created, createPost(t, mary, community.ID, fmt.Sprintf("Post number %d", i)))
 	}
 
-	// add the customed headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
+	first := expect[posts.PostListResponse](t, call(t, "GET", "/posts?limit=2", "", nil), http.StatusOK)
+	require.Len(t, first.Posts, 2)
+	assert.Equal(t, created[4].ID, first.Posts[0].ID)
+	assert.Equal(t, created[3].ID, first.Posts[1].ID)
+	assert.True(t, first.Pagination.HasMore)
+	assert.Equal(t, created[3].ID.Hex(), first.Pagination.NextCursor)
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	second := expect[posts.PostListResponse](t, call(t, "GET", "/posts?limit=2&after="+first.Pagination.NextCursor, "", nil), http.StatusOK)
+	require.Len(t, second.Posts, 2)
+	assert.Equal(t, created[2].ID, second.Posts[0].ID)
+	assert.True(t, second.Pagination.HasMore)
 
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
+	last := expect[posts.PostListResponse](t, call(t, "GET", "/posts?limit=2&after="+second.Pagination.NextCursor, "", nil), http.StatusOK)
+	require.Len(t, last.Posts, 1)
+	assert.Equal(t, created[0].ID, last.Posts[0].ID)
+	assert.False(t, last.Pagination.HasMore)
+	assert.Empty(t, last.Pagination.NextCursor)
+
+	exact := expect[posts.PostListResponse](t, call(t, "GET", "/posts?limit=5", "", nil), http.StatusOK)
+	assert.Len(t, exact.Posts, 5)
+	assert.False(t, exact.Pagination.HasMore)
+
+	capped := expect[posts.PostListResponse](t, call(t, "GET", "/posts?limit=1000", "", nil), http.StatusOK)
+	assert.Equal(t, common.MaxPageLimit, capped.Pagination.Limit)
+
+	expectError(t, call(t, "GET", "/posts?limit=0", "", nil), http.StatusBadRequest, common.INVALID_PARAM)
+	expectError(t, call(t, "GET", "/posts?limit=abc", "", nil), http.StatusBadRequest, common.INVALID_PARAM)
+	expectError(t, call(t, "GET", "/posts?after=nope", "", nil), http.StatusBadRequest, common.INVALID_PARAM)
+	expectError(t, call(t, "GET", "/posts?community=nope", "", nil), http.StatusBadRequest, common.INVALID_PARAM)
+}
+
+func TestPinnedPostsLeadCommunityListing(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+
+	announcement := createPost(t, mary, community.ID, "Read the rules")
+	second := createPost(t, mary, community.ID, "Weekly thread")
+	third := createPost(t, mary, community.ID, "Another announcement")
+	latest := createPost(t, mary, community.ID, "Latest news")
+
+	expectError(t, call(t, "POST", "/posts/"+announcement.ID.Hex()+"/pin", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+announcement.ID.Hex()+"/pin", mary, nil), http.StatusOK)
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+second.ID.Hex()+"/pin", mary, nil), http.StatusOK)
+	expectError(t, call(t, "POST", "/posts/"+third.ID.Hex()+"/pin", mary, nil), http.StatusConflict, common.PIN_LIMIT_REACHED)
+
+	listing := expect[posts.PostListResponse](t, call(t, "GET", "/posts?limit=1&community="+community.ID.Hex(), "", nil), http.StatusOK)
+	require.Len(t, listing.Posts, 3)
+	assert.True(t, listing.Posts[0].Pinned)
+	assert.True(t, listing.Posts[1].Pinned)
+	assert.Equal(t, latest.ID, listing.Posts[2].ID)
+
+	next := expect[posts.PostListResponse](t, call(t, "GET", "/posts?limit=5&community="+community.ID.Hex()+"&after="+listing.Pagination.NextCursor, "", nil), http.StatusOK)
+	require.Len(t, next.Posts, 1)
+	assert.Equal(t, third.ID, next.Posts[0].ID)
+
+	expect[common.MessageResponse](t, call(t, "DELETE", "/posts/"+second.ID.Hex()+"/pin", mary, nil), http.StatusOK)
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+third.ID.Hex()+"/pin", mary, nil), http.StatusOK)
+	assert.Equal(t, "Post already pinned", expect[common.MessageResponse](t, call(t, "POST", "/posts/"+third.ID.Hex()+"/pin", mary, nil), http.StatusOK).Message)
+	expectError(t, call(t, "POST", "/posts/"+latest.ID.Hex()+"/pin", mary, nil), http.StatusConflict, common.PIN_LIMIT_REACHED)
+	assert.False(t, getPost(t, "", latest.ID).Pinned, "a pin over the limit is undone")
+
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+announcement.ID.Hex()+"/remove", mary, nil), http.StatusOK)
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+latest.ID.Hex()+"/pin", mary, nil), http.StatusOK)
+}
+
+func TestLockedPostsRejectCommentsAndVotes(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Heated topic")
+	vote(t, john, "/posts/"+post.ID.Hex(), 1)
+
+	expectError(t, call(t, "POST", "/posts/"+post.ID.Hex()+"/lock", john, nil), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "POST", "/posts/"+post.ID.Hex()+"/lock", mary, nil), http.StatusOK)
+
+	comment := map[string]string{"text": "Too late"}
+	expectError(t, call(t, "POST", "/posts/"+post.ID.Hex()+"/comments", john, comment), http.StatusForbidden, common.POST_LOCKED)
+	expectError(t, call(t, "POST", "/posts/"+post.ID.Hex()+"/vote", john, map[string]int{"vote": 1}), http.StatusForbidden, common.POST_LOCKED)
+	expectError(t, call(t, "DELETE", "/posts/"+post.ID.Hex()+"/vote", john, nil), http.StatusForbidden, common.POST_LOCKED)
+	up, _ := postVotes(t, post.ID)
+	assert.Equal(t, 1, up, "votes on a locked post cannot be withdrawn either")
+
+	expect[common.MessageResponse](t, call(t, "DELETE", "/posts/"+post.ID.Hex()+"/lock", mary, nil), http.StatusOK)
+	createComment(t, john, post.ID, primitive.NilObjectID, "Finally open again")
+}
+
+func TestCrosspost(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	golang := createCommunity(t, mary, "golang", communities.TypePublic)
+	rust := createCommunity(t, mary, "rust", communities.TypePublic)
+	original := createPost(t, mary, golang.ID, "Generics landed")
+
+	sameCommunity := posts.CrosspostRequest{Community: golang.ID}
+	expectError(t, call(t, "POST", "/posts/"+original.ID.Hex()+"/crosspost", mary, sameCommunity), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+
+	crosspost := expect[struct {
+		Post posts.Post `json:"post"`
+	}](t, call(t, "POST", "/posts/"+original.ID.Hex()+"/crosspost", mary, posts.CrosspostRequest{Community: rust.ID}), http.StatusCreated).Post
+	require.NotNil(t, crosspost.CrosspostParent)
+	assert.Equal(t, original.ID, crosspost.CrosspostParent.ID)
+	assert.Equal(t, original.Title, crosspost.Title)
+	assert.Equal(t, 1, getPost(t, "", original.ID).CrosspostsCount)
+
+	expect[common.MessageResponse](t, call(t, "DELETE", "/posts/"+original.ID.Hex(), mary, nil), http.StatusOK)
+	orphan := getPost(t, "", crosspost.ID)
+	require.NotNil(t, orphan.CrosspostParent)
+	assert.True(t, orphan.CrosspostParent.Deleted)
+}
+
+func TestCrosspostOnlyPublicPostsAndScreenThem(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	golang := spamFilteredCommunity(t, mary)
+	secret := createCommunity(t, mary, "secret", communities.TypePrivate)
+	expect[common.MessageResponse](t, call(t, "POST", "/communities/secret/approved_users/john", mary, nil), http.StatusOK)
+
+	private := createPost(t, mary, secret.ID, "Members only")
+	leak := posts.CrosspostRequest{Community: golang.ID}
+	expectError(t, call(t, "POST", "/posts/"+private.ID.Hex()+"/crosspost", john, leak), http.StatusForbidden, common.COMMUNITY_ACCESS_DENIED)
+
+	rust := createCommunity(t, mary, "rust", communities.TypePublic)
+	original := createPost(t, mary, rust.ID, "Generics landed")
+	spammy := posts.CrosspostRequest{Community: golang.ID, Title: "Visit my casino"}
+	held := expect[struct {
+		Post posts.Post `json:"post"`
+	}](t, call(t, "POST", "/posts/"+original.ID.Hex()+"/crosspost", john, spammy), http.StatusCreated).Post
+	assert.Equal(t, common.ModStatusFiltered, held.ModStatus)
+}
+
+func TestFollowingFeed(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Mary writes")
+
+	empty := expect[posts.PostListResponse](t, call(t, "GET", "/feed/following", john, nil), http.StatusOK)
+	assert.Empty(t, empty.Posts)
+
+	expect[common.MessageResponse](t, call(t, "POST", "/users/mary/follow", john, nil), http.StatusOK)
+	feed := expect[posts.PostListResponse](t, call(t, "GET", "/feed/following", john, nil), http.StatusOK)
+	require.Len(t, feed.Posts, 1)
+	assert.Equal(t, post.ID, feed.Posts[0].ID)
+}
+
+func TestPrivateCommunitiesHideTheirPosts(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "secret", communities.TypePrivate)
+	post := createPost(t, mary, community.ID, "Members only")
+
+	expectError(t, call(t, "GET", "/posts/"+post.ID.Hex(), john, nil), http.StatusForbidden, common.COMMUNITY_ACCESS_DENIED)
+	listing := expect[posts.PostListResponse](t, call(t, "GET", "/posts", john, nil), http.StatusOK)
+	assert.Empty(t, listing.Posts)
+
+	expect[common.MessageResponse](t, call(t, "POST", "/communities/secret/approved_users/john", mary, nil), http.StatusOK)
+	getPost(t, john, post.ID)
+	listing = expect[posts.PostListResponse](t, call(t, "GET", "/posts", john, nil), http.StatusOK)
+	assert.Len(t, listing.Posts, 1)
+
+	require.NoError(t, users.Repo().Suspend(context.Background(), "john", time.Time{}, "spam"))
+	expectError(t, call(t, "GET", "/posts/"+post.ID.Hex(), john, nil), http.StatusForbidden, common.COMMUNITY_ACCESS_DENIED)
+	listing = expect[posts.PostListResponse](t, call(t, "GET", "/posts", john, nil), http.StatusOK)
+	assert.Empty(t, listing.Posts, "suspended users browse as visitors")
+}
+
+func TestBlockedAuthorsAreSkipped(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	createPost(t, mary, community.ID, "Visible to most")
+
+	expect[common.MessageResponse](t, call(t, "POST", "/users/mary/block", john, nil), http.StatusOK)
+	listing := expect[posts.PostListResponse](t, call(t, "GET", "/posts", john, nil), http.StatusOK)
+	assert.Empty(t, listing.Posts)
+	listing = expect[posts.PostListResponse](t, call(t, "GET", "/posts", "", nil), http.StatusOK)
+	assert.Len(t, listing.Posts, 1)
+}
+
+func TestStreamPostSendsCommentEvents(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Live thread")
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/posts/" + post.ID.Hex() + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The handler subscribes before sending its headers, so the comment cannot be missed.
+	comment := createComment(t, mary, post.ID, primitive.NilObjectID, "Streaming now")
+
+	lines := bufio.NewScanner(resp.Body)
+	require.True(t, lines.Scan())
+	assert.Equal(t, "event:comment_created", lines.Text())
+	require.True(t, lines.Scan())
+	assert.True(t, strings.HasPrefix(lines.Text(), "data:"))
+	assert.Contains(t, lines.Text(), comment.ID.Hex())
+
+	missing, err := http.Get(server.URL + "/posts/" + primitive.NewObjectID().Hex() + "/stream")
+	require.NoError(t, err)
+	missing.Body.Close()
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+}
+
+func TestStreamPostLeavesOutBlockedAuthors(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	anne := signup(t, "anne")
+	community := createCommunity(t, anne, "golang", communities.TypePublic)
+	post := createPost(t, anne, community.ID, "Live thread")
+	expect[common.MessageResponse](t, call(t, "POST", "/users/john/block", mary, nil), http.StatusOK)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/posts/"+post.ID.Hex()+"/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+mary)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	// log.Println(resp)
+	createComment(t, john, post.ID, primitive.NilObjectID, "You cannot see this")
+	visible := createComment(t, anne, post.ID, primitive.NilObjectID, "Only this")
 
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
+	lines := bufio.NewScanner(resp.Body)
+	require.True(t, lines.Scan())
+	assert.Equal(t, "event:comment_created", lines.Text())
+	require.True(t, lines.Scan())
+	assert.Contains(t, lines.Text(), visible.ID.Hex(), "the blocked author's comment is skipped")
 }
@@ -1,172 +1,41 @@
 package tests
 
 import (
-	"encoding/json"
-	"log"
 	"net/http"
-	"net/http/httptest"
-	"simple-reddit/common"
-	"simple-reddit/profiles"
-	t_utils "simple-reddit/test_utils"
-	"simple-reddit/users"
 	"testing"
 
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/profiles"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestSignupForProfile(t *testing.T) {
-	var resp = common.APIResponse{}
-	var body = users.CreateUserRequest{
-		FirstName: "Albert",
-		LastName:  "Einstein",
-		Email:     "einsteinalbert@ufl.edu",
-		Username:  "albert",
-		Password:  "special-relativity",
-	}
-	var expected = common.APIResponse{
-		Status:  http.StatusCreated,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{},
-	}
+func TestProfileIsEmptyUntilFirstEdit(t *testing.T) {
+	setup(t)
+	signup(t, "mary")
 
-	req, err := t_utils.MakeRequest(t_utils.POST, users.USER_ROUTE_PREFIX+"/signup", body)
-	if err != nil {
-		return
-	}
+	response := expect[profiles.ProfileResponse](t, call(t, "GET", "/profiles/mary", "", nil), http.StatusOK)
+	assert.False(t, response.Profile.UserID.IsZero())
+	assert.Empty(t, response.Profile.DisplayName)
 
-	// add the custom headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
-
-	log.Println(resp)
-
-	assert.Equal(t, 201, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "data")
-}
-
-func TestGetProfile(t *testing.T) {
-	var resp = common.APIResponse{}
-	var body = profiles.GetProfileRequest{
-		UserName: "albert",
-	}
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{},
-	}
-
-	req, err := t_utils.MakeRequest(t_utils.POST, profiles.PROFILE_ROUTE_PREFIX, body)
-	if err != nil {
-		return
-	}
-
-	// add the customed headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
-
-	log.Println("API Response", resp)
-
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "Profile")
+	expectError(t, call(t, "GET", "/profiles/nobody", "", nil), http.StatusNotFound, common.USER_NOT_FOUND)
 }
 
-func TestEditProfile(t *testing.T) {
-	var resp = common.APIResponse{}
-	var body = profiles.EditProfileRequest{
-		FirstName: "Albert",
-		LastName:  "Einstein",
-		Email:     "einsteinalbert@ufl.edu",
-		UserName:  "albert",
-	}
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{},
-	}
-
-	req, err := t_utils.MakeRequest(t_utils.PATCH, profiles.PROFILE_ROUTE_PREFIX, body)
-	if err != nil {
-		return
-	}
-
-	// add the customed headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
-
-	log.Println(resp)
-
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "updated")
-}
-
-func TestDeleteProfile(t *testing.T) {
-	var resp = common.APIResponse{}
-	var body = profiles.DeleteProfileRequest{
-		Username: "albert",
-		Password: "special-relativity",
-	}
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{},
-	}
-
-	req, err := t_utils.MakeRequest(t_utils.POST, profiles.PROFILE_ROUTE_PREFIX+"/delete", body)
-	if err != nil {
-		return
-	}
-
-	// add the custom headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+func TestUpdateProfile(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
 
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
+	edit := profiles.Profile{DisplayName: "Mary", Description: "Gopher"}
+	expect[common.MessageResponse](t, call(t, "PUT", "/profiles/mary", mary, edit), http.StatusOK)
+	response := expect[profiles.ProfileResponse](t, call(t, "GET", "/profiles/mary", "", nil), http.StatusOK)
+	assert.Equal(t, "Mary", response.Profile.DisplayName)
+	assert.Equal(t, "Gopher", response.Profile.Description)
 
-	log.Println(resp)
+	edit.DisplayName = "Mary Jane"
+	expect[common.MessageResponse](t, call(t, "PUT", "/profiles/mary", mary, edit), http.StatusOK)
+	response = expect[profiles.ProfileResponse](t, call(t, "GET", "/profiles/mary", "", nil), http.StatusOK)
+	assert.Equal(t, "Mary Jane", response.Profile.DisplayName)
 
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "deletedProfile")
-	assert.Contains(t, resp.Data, "deletedUser")
+	expectError(t, call(t, "PUT", "/profiles/mary", john, profiles.Profile{DisplayName: "Hacked"}), http.StatusForbidden, common.FORBIDDEN)
+	expectError(t, call(t, "PUT", "/profiles/mary", "", edit), http.StatusUnauthorized, common.UNAUTHORIZED)
 }
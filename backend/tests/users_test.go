@@ -1,147 +1,132 @@
 package tests
 
 import (
-	"encoding/json"
-	"log"
+	"context"
 	"net/http"
-	"net/http/httptest"
-	"simple-reddit/common"
-	"simple-reddit/profiles"
-	t_utils "simple-reddit/test_utils"
-	"simple-reddit/users"
 	"testing"
+	"time"
 
+	"github.com/ganesh96/simple-reddit/backend/blocks"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/follows"
+	"github.com/ganesh96/simple-reddit/backend/users"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestSignup(t *testing.T) {
-	var resp = common.APIResponse{}
-	var body = users.CreateUserRequest{
-		FirstName: "Mary",
-		LastName:  "Jane",
-		Email:     "mary.jane@ufl.edu",
-		Username:  "mary_jane",
-		Password:  "password123",
-	}
-	var expected = common.APIResponse{
-		Status:  http.StatusCreated,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{},
-	}
-
-	req, err := t_utils.MakeRequest(t_utils.POST, users.USER_ROUTE_PREFIX+"/signup", body)
-	if err != nil {
-		return
-	}
+func TestSignupAndLogin(t *testing.T) {
+	setup(t)
 
-	// add the custom headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
+	token := signup(t, "mary_jane")
+	assert.NotEmpty(t, token)
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	duplicateEmail := map[string]string{"Username": "someone_else", "Email": "mary_jane@example.com", "Password": password}
+	expectError(t, call(t, "POST", "/signup", "", duplicateEmail), http.StatusConflict, common.EMAIL_ALREADY_EXISTS)
+	duplicateName := map[string]string{"Username": "mary_jane", "Email": "other@example.com", "Password": password}
+	expectError(t, call(t, "POST", "/signup", "", duplicateName), http.StatusConflict, common.USERNAME_ALREADY_EXISTS)
+	reserved := map[string]string{"Username": "AutoModerator", "Email": "bot@example.com", "Password": password}
+	expectError(t, call(t, "POST", "/signup", "", reserved), http.StatusConflict, common.USERNAME_ALREADY_EXISTS)
 
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
+	wrongPassword := users.LoginDetails{Email: "mary_jane@example.com", Password: "not-the-password"}
+	expectError(t, call(t, "POST", "/login", "", wrongPassword), http.StatusUnauthorized, common.INVALID_CREDENTIALS)
+	unknownEmail := users.LoginDetails{Email: "nobody@example.com", Password: password}
+	expectError(t, call(t, "POST", "/login", "", unknownEmail), http.StatusUnauthorized, common.INVALID_CREDENTIALS)
+}
 
-	log.Println(resp)
+func TestAuthenticationIsRequired(t *testing.T) {
+	setup(t)
 
-	assert.Equal(t, 201, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "data")
+	w := call(t, "GET", "/blocks", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = call(t, "GET", "/blocks", "not-a-token", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
-func TestLogin(t *testing.T) {
-	var resp = common.APIResponse{}
-	var body = users.LoginUserRequest{
-		Username: "mary_jane",
-		Password: "password123",
-	}
-	userDB, _ := users.GetUserDetails(body.Username)
-	userResp := users.ConvertUserDBModelToUserResponse(userDB)
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{"accessToken": "", "user": userResp},
-	}
+func TestDeleteUserOnlyDeletesYourself(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	signup(t, "john")
 
-	// // isoDateTime, err := time.Parse("YYYY-mm-ddTHH:MM:ssZ", "2022-01-21T04:41:56.616Z")
-	// var user = users.CreateUserRequest{
-	// 	FirstName: "Albert",
-	// 	LastName:  "Einstein",
-	// 	Email:     "einsteinalbert@ufl.edu",
-	// 	Username:  "albert",
-	// 	Password:  "$2a$10$QYXnNZJH.hNuNOiT8Tq8nOJy02V0mcyT5h9ARQvA2bO35rdd72Zym",
-	// }
-	// users.CreateUserInDB(user)
-	// userDB, _ := users.GetUserDetails(body.Username)
-	// userResp := users.ConvertUserDBModelToUserResponse(userDB)
-
-	req, err := t_utils.MakeRequest(t_utils.POST, users.USER_ROUTE_PREFIX+"/loginuser", body)
-	if err != nil {
-		return
-	}
+	expectError(t, call(t, "DELETE", "/users/john", mary, nil), http.StatusForbidden, common.FORBIDDEN)
+	expect[common.MessageResponse](t, call(t, "DELETE", "/users/mary", mary, nil), http.StatusOK)
 
-	// add the customed headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
-	}
+	expectError(t, call(t, "GET", "/profiles/mary", "", nil), http.StatusNotFound, common.USER_NOT_FOUND)
+	expect[struct{}](t, call(t, "GET", "/profiles/john", "", nil), http.StatusOK)
+}
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+func TestSuspendedUsersAreLockedOut(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	ctx := context.Background()
 
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
-	}
+	require.NoError(t, users.Repo().Suspend(ctx, "mary", time.Time{}, "spam"))
+	w := call(t, "GET", "/blocks", mary, nil)
+	expectError(t, w, http.StatusForbidden, common.ACCOUNT_SUSPENDED)
+	assert.Contains(t, w.Body.String(), "spam")
+	expectError(t, call(t, "POST", "/login", "", users.LoginDetails{Email: "mary@example.com", Password: password}), http.StatusForbidden, common.ACCOUNT_SUSPENDED)
+
+	require.NoError(t, users.Repo().Suspend(ctx, "mary", time.Now().Add(-time.Minute), ""))
+	expect[struct{}](t, call(t, "GET", "/blocks", mary, nil), http.StatusOK)
 
-	log.Println(resp)
+	require.NoError(t, users.Repo().Suspend(ctx, "mary", time.Now().Add(time.Hour), ""))
+	expectError(t, call(t, "GET", "/blocks", mary, nil), http.StatusForbidden, common.ACCOUNT_SUSPENDED)
+	require.NoError(t, users.Repo().Unsuspend(ctx, "mary"))
+	login(t, "mary@example.com")
 
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Equal(t, expected.Data["user"], userResp)
+	assert.ErrorIs(t, users.Repo().Suspend(ctx, "nobody", time.Time{}, ""), common.ErrNotFound)
 }
 
-func TestDeleteProfileForUser(t *testing.T) {
-	var resp = common.APIResponse{}
-	var body = profiles.DeleteProfileRequest{
-		Username: "mary_jane",
-		Password: "password123",
-	}
-	var expected = common.APIResponse{
-		Status:  http.StatusOK,
-		Message: common.API_SUCCESS,
-		Data:    map[string]interface{}{},
-	}
+func TestBlockAndUnblock(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	signup(t, "john")
 
-	req, err := t_utils.MakeRequest(t_utils.POST, profiles.PROFILE_ROUTE_PREFIX+"/delete", body)
-	if err != nil {
-		return
-	}
+	expectError(t, call(t, "POST", "/users/mary/block", mary, nil), http.StatusBadRequest, common.INVALID_PARAM)
+	expectError(t, call(t, "POST", "/users/nobody/block", mary, nil), http.StatusNotFound, common.USER_NOT_FOUND)
+	expect[common.MessageResponse](t, call(t, "POST", "/users/john/block", mary, nil), http.StatusOK)
+	expect[common.MessageResponse](t, call(t, "POST", "/users/john/block", mary, nil), http.StatusOK)
 
-	// add the custom headers
-	for key, value := range customHeaders {
-		req.Header.Add(key, value)
+	blocked := expect[blocks.BlockListResponse](t, call(t, "GET", "/blocks", mary, nil), http.StatusOK)
+	if assert.Len(t, blocked.Blocks, 1) {
+		assert.Equal(t, "john", blocked.Blocks[0].Blocked)
 	}
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	expect[common.MessageResponse](t, call(t, "DELETE", "/users/john/block", mary, nil), http.StatusOK)
+	blocked = expect[blocks.BlockListResponse](t, call(t, "GET", "/blocks", mary, nil), http.StatusOK)
+	assert.Empty(t, blocked.Blocks)
+}
+
+func TestFollowers(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+
+	expectError(t, call(t, "POST", "/users/mary/follow", mary, nil), http.StatusBadRequest, common.INVALID_PARAM)
+	expect[common.MessageResponse](t, call(t, "POST", "/users/mary/follow", john, nil), http.StatusOK)
 
-	err = json.Unmarshal(w.Body.Bytes(), &resp)
-	if err != nil {
-		log.Println(err.Error())
+	followers := expect[follows.FollowersResponse](t, call(t, "GET", "/users/mary/followers", "", nil), http.StatusOK)
+	if assert.Len(t, followers.Followers, 1) {
+		assert.Equal(t, "john", followers.Followers[0].Follower)
+	}
+	following := expect[follows.FollowingResponse](t, call(t, "GET", "/users/john/following", "", nil), http.StatusOK)
+	if assert.Len(t, following.Following, 1) {
+		assert.Equal(t, "mary", following.Following[0].Followed)
 	}
 
-	log.Println(resp)
+	expect[common.MessageResponse](t, call(t, "DELETE", "/users/mary/follow", john, nil), http.StatusOK)
+	followers = expect[follows.FollowersResponse](t, call(t, "GET", "/users/mary/followers", "", nil), http.StatusOK)
+	assert.Empty(t, followers.Followers)
+}
+
+func TestBlockingRemovesFollows(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+
+	expect[common.MessageResponse](t, call(t, "POST", "/users/mary/follow", john, nil), http.StatusOK)
+	expect[common.MessageResponse](t, call(t, "POST", "/users/john/block", mary, nil), http.StatusOK)
 
-	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, expected.Status, resp.Status)
-	assert.Equal(t, expected.Message, resp.Message)
-	assert.Contains(t, resp.Data, "deletedProfile")
-	assert.Contains(t, resp.Data, "deletedUser")
+	followers := expect[follows.FollowersResponse](t, call(t, "GET", "/users/mary/followers", "", nil), http.StatusOK)
+	assert.Empty(t, followers.Followers)
+	expectError(t, call(t, "POST", "/users/mary/follow", john, nil), http.StatusForbidden, common.USER_BLOCKED)
 }
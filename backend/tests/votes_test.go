@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ganesh96/simple-reddit/backend/comments"
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
+	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/votes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func vote(t *testing.T, token string, path string, value int) string {
+	t.Helper()
+	return expect[common.MessageResponse](t, call(t, "POST", path+"/vote", token, votes.VoteRequest{Vote: value}), http.StatusOK).Message
+}
+
+// postVotes reads the stored counters, since responses add noise to them.
+func postVotes(t *testing.T, postID primitive.ObjectID) (int, int) {
+	t.Helper()
+	post, err := posts.Repo().FindByID(context.Background(), postID)
+	require.NoError(t, err)
+	return post.UpVotes, post.DownVotes
+}
+
+func commentVotes(t *testing.T, commentID primitive.ObjectID) (int, int) {
+	t.Helper()
+	comment, err := comments.Repo().FindByID(context.Background(), commentID)
+	require.NoError(t, err)
+	return comment.UpVotes, comment.DownVotes
+}
+
+func TestPostVoteScore(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	anne := signup(t, "anne")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Vote on me")
+	path := "/posts/" + post.ID.Hex()
+
+	vote(t, john, path, 1)
+	assert.Equal(t, "Vote already applied", vote(t, john, path, 1))
+	up, down := postVotes(t, post.ID)
+	assert.Equal(t, [2]int{1, 0}, [2]int{up, down})
+
+	vote(t, john, path, -1)
+	vote(t, anne, path, -1)
+	up, down = postVotes(t, post.ID)
+	assert.Equal(t, [2]int{0, 2}, [2]int{up, down})
+
+	fetched := getPost(t, "", post.ID)
+	assert.InDelta(t, -2, fetched.UpVotes-fetched.DownVotes, 1, "fuzzing keeps the score close")
+
+	expect[common.MessageResponse](t, call(t, "DELETE", path+"/vote", john, nil), http.StatusOK)
+	assert.Equal(t, "Vote already removed", expect[common.MessageResponse](t, call(t, "DELETE", path+"/vote", john, nil), http.StatusOK).Message)
+	up, down = postVotes(t, post.ID)
+	assert.Equal(t, [2]int{0, 1}, [2]int{up, down})
+
+	expectError(t, call(t, "POST", path+"/vote", john, map[string]int{"vote": 2}), http.StatusBadRequest, common.INVALID_REQUEST_BODY)
+	expectError(t, call(t, "POST", "/posts/"+primitive.NewObjectID().Hex()+"/vote", john, votes.VoteRequest{Vote: 1}), http.StatusNotFound, common.POST_NOT_FOUND)
+	expectError(t, call(t, "POST", "/posts/nope/vote", john, votes.VoteRequest{Vote: 1}), http.StatusBadRequest, common.INVALID_PARAM)
+}
+
+func TestCommentVoteScore(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	john := signup(t, "john")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Discuss")
+	comment := createComment(t, mary, post.ID, primitive.NilObjectID, "Vote on this comment")
+	path := "/comments/" + comment.ID.Hex()
+
+	vote(t, john, path, 1)
+	up, down := commentVotes(t, comment.ID)
+	assert.Equal(t, [2]int{1, 0}, [2]int{up, down})
+
+	vote(t, john, path, -1)
+	up, down = commentVotes(t, comment.ID)
+	assert.Equal(t, [2]int{0, 1}, [2]int{up, down})
+
+	expect[common.MessageResponse](t, call(t, "DELETE", path+"/vote", john, nil), http.StatusOK)
+	up, down = commentVotes(t, comment.ID)
+	assert.Equal(t, [2]int{0, 0}, [2]int{up, down})
+
+	expectError(t, call(t, "POST", "/comments/"+primitive.NewObjectID().Hex()+"/vote", john, votes.VoteRequest{Vote: 1}), http.StatusNotFound, common.COMMENT_NOT_FOUND)
+}
+
+func TestVotesFromNewAccountsAreFlagged(t *testing.T) {
+	setup(t)
+	mary := signup(t, "mary")
+	root := admin(t, "root")
+	community := createCommunity(t, mary, "golang", communities.TypePublic)
+	post := createPost(t, mary, community.ID, "Brigade target")
+	path := "/posts/" + post.ID.Hex()
+
+	// Every test account is brand new, so the fifth voter completes a new-account cluster.
+	voters := []string{"ann", "bob", "cat", "dan", "eve"}
+	for _, username := range voters {
+		vote(t, signup(t, username), path, 1)
+	}
+	up, down := postVotes(t, post.ID)
+	assert.Equal(t, [2]int{4, 0}, [2]int{up, down}, "only the vote that completed the cluster is discounted")
+
+	expectError(t, call(t, "GET", "/admin/votes/flagged", mary, nil), http.StatusForbidden, common.FORBIDDEN)
+	expectError(t, call(t, "GET", "/admin/votes/flagged?limit=0", root, nil), http.StatusBadRequest, common.INVALID_PARAM)
+	clusters := expect[votes.FlaggedVotesResponse](t, call(t, "GET", "/admin/votes/flagged", root, nil), http.StatusOK).Clusters
+	require.Len(t, clusters, 1)
+	assert.Equal(t, post.ID, clusters[0].TargetID)
+	assert.Equal(t, len(voters), clusters[0].Votes)
+	assert.Equal(t, len(voters)-1, clusters[0].Queued, "earlier votes wait for review")
+	assert.ElementsMatch(t, voters, clusters[0].Usernames)
+	assert.Contains(t, clusters[0].Reasons, votes.ReasonNewAccountCluster)
+
+	confirm := "/admin/votes/flagged/post/" + post.ID.Hex() + "/confirm"
+	clear := "/admin/votes/flagged/post/" + post.ID.Hex() + "/clear"
+	expectError(t, call(t, "POST", confirm, mary, nil), http.StatusForbidden, common.FORBIDDEN)
+	expectError(t, call(t, "POST", "/admin/votes/flagged/user/"+post.ID.Hex()+"/confirm", root, nil), http.StatusBadRequest, common.INVALID_PARAM)
+
+	result := expect[votes.ReviewVotesResponse](t, call(t, "POST", confirm, root, votes.ReviewVotesRequest{Usernames: []string{"ann"}}), http.StatusOK)
+	assert.Equal(t, 1, result.Reviewed)
+	up, down = postVotes(t, post.ID)
+	assert.Equal(t, [2]int{3, 0}, [2]int{up, down}, "a confirmed vote stops counting")
+
+	result = expect[votes.ReviewVotesResponse](t, call(t, "POST", confirm, root, nil), http.StatusOK)
+	assert.Equal(t, len(voters), result.Reviewed)
+	up, down = postVotes(t, post.ID)
+	assert.Equal(t, [2]int{0, 0}, [2]int{up, down})
+
+	result = expect[votes.ReviewVotesResponse](t, call(t, "POST", clear, root, nil), http.StatusOK)
+	assert.Equal(t, len(voters), result.Reviewed)
+	up, down = postVotes(t, post.ID)
+	assert.Equal(t, [2]int{5, 0}, [2]int{up, down}, "cleared false positives count again")
+
+	clusters = expect[votes.FlaggedVotesResponse](t, call(t, "GET", "/admin/votes/flagged", root, nil), http.StatusOK).Clusters
+	assert.Empty(t, clusters)
+	expectError(t, call(t, "POST", clear, root, nil), http.StatusNotFound, common.VOTE_NOT_FOUND)
+
+	entries := expect[communities.ModlogResponse](t, call(t, "GET", "/communities/golang/modlog", mary, nil), http.StatusOK).Entries
+	require.Len(t, entries, 3)
+	assert.Equal(t, modlog.ActionClearVotes, entries[0].Action)
+	assert.Equal(t, modlog.ActionConfirmVotes, entries[1].Action)
+	assert.Equal(t, "root", entries[0].Moderator)
+	assert.True(t, entries[0].Admin)
+	assert.Equal(t, post.ID, entries[0].TargetID)
+}
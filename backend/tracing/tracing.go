@@ -0,0 +1,103 @@
+// Package tracing sets up OpenTelemetry for the server: a tracer provider that samples new traces at a
+// configured ratio and exports finished spans in batches, to an OTLP/gRPC collector or to stdout, and
+// W3C trace context propagation. Spans are started with the otel API through Tracer.
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"sync"
+
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/ganesh96/simple-reddit/backend"
+
+// Propagator reads and writes the W3C traceparent and tracestate headers.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// Options describe the spans Configure records.
+type Options struct {
+	ServiceName string
+	// SampleRatio is the share of new traces that are recorded. Spans continuing a caller's trace
+	// follow the caller's sampling decision.
+	SampleRatio float64
+}
+
+var (
+	providerMu sync.RWMutex
+	provider   *sdktrace.TracerProvider
+)
+
+// Configure starts recording spans and exporting them through exporter. Until then Tracer starts
+// spans that record nothing.
+func Configure(exporter sdktrace.SpanExporter, opts Options) {
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(opts.ServiceName))),
+	)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logging.Default().Warn("error exporting spans", "error", err)
+	}))
+	otel.SetTextMapPropagator(Propagator)
+	otel.SetTracerProvider(tp)
+
+	providerMu.Lock()
+	provider = tp
+	providerMu.Unlock()
+}
+
+// Enabled reports whether spans are being recorded.
+func Enabled() bool {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return provider != nil
+}
+
+// Tracer returns the tracer the server's spans are started with.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Shutdown stops recording spans and exports those still queued, giving up when ctx is done.
+func Shutdown(ctx context.Context) error {
+	providerMu.Lock()
+	tp := provider
+	provider = nil
+	providerMu.Unlock()
+	if tp == nil {
+		return nil
+	}
+
+	otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	return tp.Shutdown(ctx)
+}
+
+// NewOTLPExporter sends spans to the OTLP/gRPC collector at endpoint, with headers on every request.
+// An http endpoint is reached without TLS.
+func NewOTLPExporter(ctx context.Context, endpoint string, headers map[string]string) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(u.Host), otlptracegrpc.WithHeaders(headers)}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// NewStdoutExporter writes one JSON object per span to w, for local development.
+func NewStdoutExporter(w io.Writer) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(w))
+}
@@ -0,0 +1,177 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// recordingExporter keeps the spans it is given, even after shutdown.
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error { return nil }
+
+// remoteContext returns a context carrying the caller's span from a traceparent header.
+func remoteContext(traceparent string) context.Context {
+	carrier := propagation.MapCarrier{"traceparent": traceparent, "tracestate": "vendor=value"}
+	return Propagator.Extract(context.Background(), carrier)
+}
+
+func TestTracerIsANoOpUntilConfigured(t *testing.T) {
+	assert.False(t, Enabled())
+	_, span := Tracer().Start(context.Background(), "work")
+	assert.False(t, span.IsRecording())
+	assert.False(t, span.SpanContext().IsValid())
+	span.End()
+}
+
+func TestSpansFormATreeAndAreExportedOnShutdown(t *testing.T) {
+	exporter := &recordingExporter{}
+	Configure(exporter, Options{ServiceName: "simple-reddit-backend", SampleRatio: 1})
+	require.True(t, Enabled())
+
+	ctx, server := Tracer().Start(remoteContext("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"), "GET /posts",
+		trace.WithSpanKind(trace.SpanKindServer))
+	_, child := Tracer().Start(ctx, "find simple-reddit.posts", trace.WithSpanKind(trace.SpanKindClient))
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	server.End()
+
+	require.NoError(t, Shutdown(context.Background()))
+	assert.False(t, Enabled())
+	require.Len(t, exporter.spans, 2)
+	childData, serverData := exporter.spans[0], exporter.spans[1]
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverData.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", serverData.Parent().SpanID().String())
+	assert.Equal(t, "vendor=value", serverData.SpanContext().TraceState().String())
+	assert.Equal(t, serverData.SpanContext().SpanID(), childData.Parent().SpanID())
+	assert.Equal(t, codes.Error, childData.Status().Code)
+	assert.Equal(t, "boom", childData.Status().Description)
+	assert.Contains(t, serverData.Resource().Attributes(), semconv.ServiceName("simple-reddit-backend"))
+
+	_, span := Tracer().Start(context.Background(), "after shutdown")
+	assert.False(t, span.IsRecording())
+}
+
+func TestSamplingFollowsTheRatioAndTheCaller(t *testing.T) {
+	exporter := &recordingExporter{}
+	Configure(exporter, Options{ServiceName: "svc", SampleRatio: 0})
+
+	_, fresh := Tracer().Start(context.Background(), "new trace")
+	assert.False(t, fresh.IsRecording())
+	assert.True(t, fresh.SpanContext().IsValid(), "unsampled traces are still propagated")
+	fresh.End()
+
+	_, unsampled := Tracer().Start(remoteContext("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"), "unsampled caller")
+	assert.False(t, unsampled.IsRecording())
+	unsampled.End()
+
+	_, sampled := Tracer().Start(remoteContext("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"), "sampled caller")
+	assert.True(t, sampled.IsRecording())
+	sampled.End()
+
+	require.NoError(t, Shutdown(context.Background()))
+	require.Len(t, exporter.spans, 1)
+	assert.Equal(t, "sampled caller", exporter.spans[0].Name())
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var out bytes.Buffer
+	exporter, err := NewStdoutExporter(&out)
+	require.NoError(t, err)
+	Configure(exporter, Options{ServiceName: "svc", SampleRatio: 1})
+	_, span := Tracer().Start(context.Background(), "update simple-reddit.votes")
+	span.End()
+	require.NoError(t, Shutdown(context.Background()))
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "update simple-reddit.votes", line["Name"])
+}
+
+type collector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	mu       sync.Mutex
+	requests []*coltracepb.ExportTraceServiceRequest
+	apiKey   []string
+}
+
+func (c *collector) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	md, _ := metadata.FromIncomingContext(ctx)
+	c.apiKey = md.Get("api-key")
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func TestOTLPExporter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	received := &collector{}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, received)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	exporter, err := NewOTLPExporter(context.Background(), "http://"+listener.Addr().String(), map[string]string{"api-key": "secret"})
+	require.NoError(t, err)
+	Configure(exporter, Options{ServiceName: "simple-reddit-backend", SampleRatio: 1})
+	_, span := Tracer().Start(context.Background(), "GET /posts", trace.WithSpanKind(trace.SpanKindServer))
+	span.End()
+	require.NoError(t, Shutdown(context.Background()))
+
+	received.mu.Lock()
+	defer received.mu.Unlock()
+	require.Len(t, received.requests, 1)
+	assert.Equal(t, []string{"secret"}, received.apiKey)
+	resourceSpans := received.requests[0].ResourceSpans[0]
+	assert.Equal(t, "service.name", resourceSpans.Resource.Attributes[0].Key)
+	assert.Equal(t, "simple-reddit-backend", resourceSpans.Resource.Attributes[0].Value.GetStringValue())
+	assert.Equal(t, "GET /posts", resourceSpans.ScopeSpans[0].Spans[0].Name)
+}
+
+func TestExportFailuresDoNotBlockShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := "http://" + listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	exporter, err := NewOTLPExporter(context.Background(), unreachable, nil)
+	require.NoError(t, err)
+	Configure(exporter, Options{ServiceName: "svc", SampleRatio: 1})
+	_, span := Tracer().Start(context.Background(), "work")
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_ = Shutdown(ctx)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
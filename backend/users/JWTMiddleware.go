@@ -1,46 +1,102 @@
 package users
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/ganesh96/simple-reddit/backend/common"
 	"github.com/ganesh96/simple-reddit/backend/configs"
+	"github.com/ganesh96/simple-reddit/backend/logging"
 	"github.com/gin-gonic/gin"
 )
 
 // AuthorizeJWT is a middleware to authorize JWT tokens.
 func AuthorizeJWT() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		const bearerSchema = "Bearer "
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			common.RespondWithJSON(c, http.StatusUnauthorized, common.UNAUTHORIZED, gin.H{"error": "No authorization header provided"})
-			c.Abort()
+			common.RespondWithError(c, common.NewAPIError(http.StatusUnauthorized, common.UNAUTHORIZED, "No authorization header provided"))
 			return
 		}
 
-		if !strings.HasPrefix(authHeader, bearerSchema) {
-			common.RespondWithJSON(c, http.StatusUnauthorized, common.UNAUTHORIZED, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+		username, problem := usernameFromHeader(authHeader)
+		if problem != "" {
+			common.RespondWithError(c, common.NewAPIError(http.StatusUnauthorized, common.UNAUTHORIZED, problem))
 			return
 		}
-
-		token, err := configs.ValidateToken(authHeader[len(bearerSchema):])
-		if err != nil || !token.Valid {
-			common.RespondWithJSON(c, http.StatusUnauthorized, common.UNAUTHORIZED, gin.H{"error": "Invalid token"})
-			c.Abort()
+		// Tokens stay valid for a day, so suspensions are checked on every request rather than at sign-in only.
+		if user, err := Repo().FindByUsername(c.Request.Context(), username); err == nil && user.IsSuspended(time.Now()) {
+			respondSuspended(c, user)
 			return
 		}
 
-		claims, ok := token.Claims.(*configs.JWTClaim)
-		if !ok || claims.Username == "" {
-			common.RespondWithJSON(c, http.StatusUnauthorized, common.UNAUTHORIZED, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
+		setUsername(c, username)
+		c.Next()
+	}
+}
+
+// OptionalJWT sets the username for requests carrying a valid token and lets every other request through anonymously.
+// Suspended users are treated as anonymous, so they keep no more access than a visitor who is signed out.
+// Use it on public routes whose response depends on who is asking.
+func OptionalJWT() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			if username, problem := usernameFromHeader(authHeader); problem == "" && !isSuspended(c.Request.Context(), username) {
+				setUsername(c, username)
+			}
 		}
+		c.Next()
+	}
+}
+
+// isSuspended reports whether username is currently suspended.
+func isSuspended(ctx context.Context, username string) bool {
+	user, err := Repo().FindByUsername(ctx, username)
+	return err == nil && user.IsSuspended(time.Now())
+}
+
+// setUsername records the authenticated user for handlers and adds it to the request's logger.
+func setUsername(c *gin.Context, username string) {
+	c.Set("username", username)
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(logging.NewContext(ctx, logging.FromContext(ctx).With("username", username)))
+}
 
-		c.Set("username", claims.Username)
+// RequireAdmin only lets site administrators through. It must run after AuthorizeJWT.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAdmin(c.Request.Context(), c.GetString("username")) {
+			common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "Administrator access required"))
+			return
+		}
 		c.Next()
 	}
 }
+
+// IsAdmin reports whether username is a site administrator.
+func IsAdmin(ctx context.Context, username string) bool {
+	user, err := Repo().FindByUsername(ctx, username)
+	return err == nil && user.IsAdmin
+}
+
+// usernameFromHeader validates a bearer token and returns its username, or a description of what is wrong with it.
+func usernameFromHeader(authHeader string) (string, string) {
+	const bearerSchema = "Bearer "
+	if !strings.HasPrefix(authHeader, bearerSchema) {
+		return "", "Invalid authorization header format"
+	}
+
+	token, err := configs.ValidateToken(authHeader[len(bearerSchema):])
+	if err != nil || !token.Valid {
+		return "", "Invalid token"
+	}
+
+	claims, ok := token.Claims.(*configs.JWTClaim)
+	if !ok || claims.Username == "" {
+		return "", "Invalid token claims"
+	}
+
+	return claims.Username, ""
+}
@@ -0,0 +1,68 @@
+package users
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+)
+
+type memoryRepository struct {
+	users *memstore.Table[common.User]
+}
+
+// NewMemoryRepository keeps users in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{users: memstore.NewTable[common.User]()}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, user common.User) error {
+	return r.users.Insert(user, func(existing common.User) bool {
+		return existing.Email == user.Email || existing.Username == user.Username
+	})
+}
+
+func (r *memoryRepository) FindByEmail(ctx context.Context, email string) (common.User, error) {
+	return r.users.First(func(user common.User) bool { return user.Email == email })
+}
+
+func (r *memoryRepository) FindByUsername(ctx context.Context, username string) (common.User, error) {
+	return r.users.First(byUsername(username))
+}
+
+func (r *memoryRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	return r.users.Count(func(user common.User) bool { return user.Email == email }) > 0, nil
+}
+
+func (r *memoryRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	return r.users.Count(byUsername(username)) > 0, nil
+}
+
+func (r *memoryRepository) DeleteByUsername(ctx context.Context, username string) error {
+	r.users.Delete(byUsername(username))
+	return nil
+}
+
+func (r *memoryRepository) SetAdmin(ctx context.Context, username string, admin bool) error {
+	_, err := r.users.UpdateOne(byUsername(username), func(user *common.User) { user.IsAdmin = admin })
+	return err
+}
+
+func (r *memoryRepository) Suspend(ctx context.Context, username string, until time.Time, reason string) error {
+	_, err := r.users.UpdateOne(byUsername(username), func(user *common.User) {
+		user.Suspended, user.SuspendedUntil, user.SuspensionReason = true, until, reason
+	})
+	return err
+}
+
+func (r *memoryRepository) Unsuspend(ctx context.Context, username string) error {
+	_, err := r.users.UpdateOne(byUsername(username), func(user *common.User) {
+		user.Suspended, user.SuspendedUntil, user.SuspensionReason = false, time.Time{}, ""
+	})
+	return err
+}
+
+func byUsername(username string) func(common.User) bool {
+	return func(user common.User) bool { return user.Username == username }
+}
@@ -0,0 +1,11 @@
+package users
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var signups = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "simple_reddit_signups_total",
+	Help: "Accounts created through signup.",
+})
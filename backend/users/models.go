@@ -1,13 +1,18 @@
 package users
 
-import (
-	"github.com/ganesh96/simple-reddit/backend/configs"
-	"go.mongodb.org/mongo-driver/mongo"
-)
-
-var userCollection *mongo.Collection = configs.GetCollection("users")
+// SignupRequest holds the details of a new account. The keys keep the capitalized names signup has always accepted.
+type SignupRequest struct {
+	Username string `json:"Username"`
+	Email    string `json:"Email"`
+	Password string `json:"Password"`
+}
 
 type LoginDetails struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
+
+// LoginResponse is the data of a successful login.
+type LoginResponse struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,95 @@
+package users
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoRepository struct {
+	users *mongo.Collection
+}
+
+// NewMongoRepository stores users in the users collection of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{users: db.Collection("users")}
+}
+
+func (r *mongoRepository) Create(ctx context.Context, user common.User) error {
+	_, err := r.users.InsertOne(ctx, user)
+	return common.MongoError(err)
+}
+
+func (r *mongoRepository) FindByEmail(ctx context.Context, email string) (common.User, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *mongoRepository) FindByUsername(ctx context.Context, username string) (common.User, error) {
+	return r.findOne(ctx, bson.M{"username": username})
+}
+
+func (r *mongoRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	count, err := r.users.CountDocuments(ctx, bson.M{"email": email})
+	return count > 0, err
+}
+
+func (r *mongoRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	count, err := r.users.CountDocuments(ctx, bson.M{"username": username})
+	return count > 0, err
+}
+
+func (r *mongoRepository) DeleteByUsername(ctx context.Context, username string) error {
+	_, err := r.users.DeleteOne(ctx, bson.M{"username": username})
+	return err
+}
+
+func (r *mongoRepository) SetAdmin(ctx context.Context, username string, admin bool) error {
+	if admin {
+		return r.update(ctx, username, bson.M{"$set": bson.M{"is_admin": true}})
+	}
+	return r.update(ctx, username, bson.M{"$unset": bson.M{"is_admin": ""}})
+}
+
+func (r *mongoRepository) Suspend(ctx context.Context, username string, until time.Time, reason string) error {
+	set := bson.M{"suspended": true}
+	unset := bson.M{}
+	if until.IsZero() {
+		unset["suspended_until"] = ""
+	} else {
+		set["suspended_until"] = until
+	}
+	if reason == "" {
+		unset["suspension_reason"] = ""
+	} else {
+		set["suspension_reason"] = reason
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return r.update(ctx, username, update)
+}
+
+func (r *mongoRepository) Unsuspend(ctx context.Context, username string) error {
+	return r.update(ctx, username, bson.M{"$unset": bson.M{"suspended": "", "suspended_until": "", "suspension_reason": ""}})
+}
+
+func (r *mongoRepository) update(ctx context.Context, username string, update bson.M) error {
+	result, err := r.users.UpdateOne(ctx, bson.M{"username": username}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoRepository) findOne(ctx context.Context, filter bson.M) (common.User, error) {
+	var user common.User
+	err := r.users.FindOne(ctx, filter).Decode(&user)
+	return user, common.MongoError(err)
+}
@@ -0,0 +1,41 @@
+package users
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+)
+
+// Repository stores user accounts. Lookups that find nothing return common.ErrNotFound.
+type Repository interface {
+	// Create stores a new user, or returns common.ErrDuplicate when its email or username is taken.
+	Create(ctx context.Context, user common.User) error
+	FindByEmail(ctx context.Context, email string) (common.User, error)
+	FindByUsername(ctx context.Context, username string) (common.User, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	DeleteByUsername(ctx context.Context, username string) error
+	// SetAdmin grants or revokes the site administrator role.
+	SetAdmin(ctx context.Context, username string, admin bool) error
+	// Suspend suspends the account until until, or indefinitely when it is zero.
+	Suspend(ctx context.Context, username string, until time.Time, reason string) error
+	Unsuspend(ctx context.Context, username string) error
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+// Repo returns the repository installed with SetRepository, for packages that need to read users.
+func Repo() Repository {
+	return repository.Get()
+}
+
+// Exists reports whether username belongs to a registered user.
+func Exists(ctx context.Context, username string) (bool, error) {
+	return Repo().UsernameExists(ctx, username)
+}
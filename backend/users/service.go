@@ -1,92 +1,103 @@
 package users
 
 import (
-	"context"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/ganesh96/simple-reddit/backend/automod"
 	"github.com/ganesh96/simple-reddit/backend/common"
 	"github.com/ganesh96/simple-reddit/backend/configs"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
 func Signup(c *gin.Context) {
-	var user common.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": err.Error()})
+	var req SignupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondWithError(c, common.BindError(err))
 		return
 	}
+	user := common.User{Username: req.Username, Email: req.Email, Password: req.Password}
 
 	// Check for existing user by email
-	count, err := userCollection.CountDocuments(context.TODO(), bson.M{"email": user.Email})
+	taken, err := Repo().EmailExists(c.Request.Context(), user.Email)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Error checking for existing email"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Error checking for existing email"))
 		return
 	}
-	if count > 0 {
-		common.RespondWithJSON(c, http.StatusConflict, common.EMAIL_ALREADY_EXISTS, gin.H{"error": "User with this email already exists"})
+	if taken {
+		common.RespondWithError(c, common.NewAPIError(http.StatusConflict, common.EMAIL_ALREADY_EXISTS, "User with this email already exists"))
 		return
 	}
 
 	// Check for existing user by username
-	count, err = userCollection.CountDocuments(context.TODO(), bson.M{"username": user.Username})
+	taken, err = Repo().UsernameExists(c.Request.Context(), user.Username)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Error checking for existing username"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Error checking for existing username"))
 		return
 	}
-	if count > 0 {
-		common.RespondWithJSON(c, http.StatusConflict, common.USERNAME_ALREADY_EXISTS, gin.H{"error": "User with this username already exists"})
+	if taken || strings.EqualFold(user.Username, automod.Username) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusConflict, common.USERNAME_ALREADY_EXISTS, "User with this username already exists"))
 		return
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := HashPassword(user.Password)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to hash password"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to hash password"))
 		return
 	}
 
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
 	user.ID = primitive.NewObjectID()
 
-	_, err = userCollection.InsertOne(context.TODO(), user)
+	err = Repo().Create(c.Request.Context(), user)
+	if errors.Is(err, common.ErrDuplicate) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusConflict, common.USERNAME_ALREADY_EXISTS, "User with this email or username already exists"))
+		return
+	}
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to create user"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to create user"))
 		return
 	}
+	signups.Inc()
 
-	common.RespondWithJSON(c, http.StatusCreated, common.CREATED, gin.H{"message": "User created successfully"})
+	common.RespondWithJSON(c, http.StatusCreated, common.CREATED, common.MessageResponse{Message: "User created successfully"})
 }
 
 func Login(c *gin.Context) {
 	var loginDetails LoginDetails
 	if err := c.ShouldBindJSON(&loginDetails); err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": err.Error()})
+		common.RespondWithError(c, common.BindError(err))
 		return
 	}
 
-	var foundUser common.User
-	err := userCollection.FindOne(context.TODO(), bson.M{"email": loginDetails.Email}).Decode(&foundUser)
+	foundUser, err := Repo().FindByEmail(c.Request.Context(), loginDetails.Email)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusUnauthorized, common.INVALID_CREDENTIALS, gin.H{"error": "Invalid email or password"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusUnauthorized, common.INVALID_CREDENTIALS, "Invalid email or password"))
 		return
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(foundUser.Password), []byte(loginDetails.Password))
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusUnauthorized, common.INVALID_CREDENTIALS, gin.H{"error": "Invalid email or password"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusUnauthorized, common.INVALID_CREDENTIALS, "Invalid email or password"))
+		return
+	}
+	if foundUser.IsSuspended(time.Now()) {
+		respondSuspended(c, foundUser)
 		return
 	}
 
 	token, err := configs.GenerateToken(foundUser.Username)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to generate token"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to generate token"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"token": token})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, LoginResponse{Token: token})
 }
 
 func DeleteUser(c *gin.Context) {
@@ -94,15 +105,33 @@ func DeleteUser(c *gin.Context) {
 	authUsername := c.GetString("username")
 
 	if username != authUsername {
-		common.RespondWithJSON(c, http.StatusForbidden, common.FORBIDDEN, gin.H{"error": "You are not authorized to delete this user"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.FORBIDDEN, "You are not authorized to delete this user"))
 		return
 	}
 
-	_, err := userCollection.DeleteOne(context.TODO(), bson.M{"username": username})
+	err := Repo().DeleteByUsername(c.Request.Context(), username)
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to delete user"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to delete user"))
 		return
 	}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "User deleted successfully"})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "User deleted successfully"})
+}
+
+// respondSuspended tells a suspended user why and until when they cannot sign in.
+func respondSuspended(c *gin.Context, user common.User) {
+	msg := "This account is suspended"
+	if !user.SuspendedUntil.IsZero() {
+		msg += " until " + user.SuspendedUntil.UTC().Format(time.RFC3339)
+	}
+	if user.SuspensionReason != "" {
+		msg += ": " + user.SuspensionReason
+	}
+	common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.ACCOUNT_SUSPENDED, msg))
+}
+
+// HashPassword hashes password for storing in common.User.Password.
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hashed), err
 }
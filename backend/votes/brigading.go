@@ -0,0 +1,139 @@
+package votes
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ReasonNewAccountCluster = "new_account_cluster"
+	ReasonSameNetwork       = "same_network"
+	ReasonSingleAuthor      = "single_author"
+)
+
+const (
+	// brigadeWindow is how far back votes on the same target are compared with a new vote.
+	brigadeWindow = time.Hour
+	// newAccountAge is how young an account must be to count towards a new-account cluster.
+	newAccountAge = 72 * time.Hour
+	// newAccountClusterSize votes from new accounts on one target within brigadeWindow form a cluster.
+	newAccountClusterSize = 5
+	// sameNetworkClusterSize votes from one network block on one target within brigadeWindow form a cluster.
+	sameNetworkClusterSize = 3
+	// singleAuthorMinVotes is how many recent votes a voter needs before voting only for one author is suspicious.
+	singleAuthorMinVotes = 10
+	singleAuthorLookback = 20
+)
+
+// ipBlock groups client addresses by network so votes from one household or host can be compared:
+// a /24 for IPv4 and a /48 for IPv6.
+func ipBlock(clientIP string) string {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// suspicion is the outcome of assessing one vote: why it looks manipulated, and which earlier votes
+// on the same target belong to the same ring. Only the new vote is discounted; the earlier ones may be
+// legitimate users who share a network or joined recently, so they are queued for an administrator.
+type suspicion struct {
+	reasons []string
+	peers   map[string]RecentQuery
+}
+
+func assessVote(ctx context.Context, vote Vote, now time.Time) (suspicion, error) {
+	result := suspicion{peers: map[string]RecentQuery{}}
+	recent := RecentQuery{
+		TargetType:      vote.TargetType,
+		TargetID:        vote.TargetID,
+		ExcludeUsername: vote.Username,
+		Since:           now.Add(-brigadeWindow),
+	}
+
+	if !vote.VoterCreatedAt.IsZero() && now.Sub(vote.VoterCreatedAt) < newAccountAge {
+		query := recent
+		query.VoterCreatedSince = now.Add(-newAccountAge)
+		count, err := repo().CountRecent(ctx, query)
+		if err != nil {
+			return suspicion{}, err
+		}
+		if count+1 >= newAccountClusterSize {
+			result.reasons = append(result.reasons, ReasonNewAccountCluster)
+			result.peers[ReasonNewAccountCluster] = query
+		}
+	}
+
+	if vote.IPBlock != "" {
+		query := recent
+		query.IPBlock = vote.IPBlock
+		count, err := repo().CountRecent(ctx, query)
+		if err != nil {
+			return suspicion{}, err
+		}
+		if count+1 >= sameNetworkClusterSize {
+			result.reasons = append(result.reasons, ReasonSameNetwork)
+			result.peers[ReasonSameNetwork] = query
+		}
+	}
+
+	if vote.TargetAuthor != "" {
+		onlyAuthor, err := votesOnlyForAuthor(ctx, vote)
+		if err != nil {
+			return suspicion{}, err
+		}
+		if onlyAuthor {
+			result.reasons = append(result.reasons, ReasonSingleAuthor)
+		}
+	}
+
+	return result, nil
+}
+
+// votesOnlyForAuthor reports whether every one of the voter's recent votes went to the same author as this one.
+func votesOnlyForAuthor(ctx context.Context, vote Vote) (bool, error) {
+	recent, err := repo().LatestByUser(ctx, vote.Username, vote.TargetID, singleAuthorLookback)
+	if err != nil {
+		return false, err
+	}
+	if len(recent)+1 < singleAuthorMinVotes {
+		return false, nil
+	}
+	for _, previous := range recent {
+		if previous.TargetAuthor != vote.TargetAuthor {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// queuePeers puts the earlier votes of a detected ring in the admin review queue. They keep counting
+// until an administrator confirms them.
+func queuePeers(ctx context.Context, peers map[string]RecentQuery) error {
+	for reason, query := range peers {
+		query.Unflagged = true
+		ring, err := repo().FindRecent(ctx, query)
+		if err != nil {
+			return err
+		}
+		if len(ring) == 0 {
+			continue
+		}
+
+		ids := make([]primitive.ObjectID, len(ring))
+		for i, peer := range ring {
+			ids[i] = peer.ID
+		}
+		if err := repo().Queue(ctx, ids, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package votes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ganesh96/simple-reddit/backend/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRepository struct {
+	votes *memstore.Table[Vote]
+}
+
+// NewMemoryRepository keeps votes in process memory, for tests and running without a database.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{votes: memstore.NewTable[Vote]()}
+}
+
+func (r *memoryRepository) Find(ctx context.Context, targetType string, targetID primitive.ObjectID, username string) (Vote, error) {
+	return r.votes.First(byVoter(targetType, targetID, username))
+}
+
+func (r *memoryRepository) Save(ctx context.Context, vote Vote) error {
+	r.votes.Upsert(byVoter(vote.TargetType, vote.TargetID, vote.Username), func() Vote {
+		created := Vote{ID: vote.ID, TargetType: vote.TargetType, TargetID: vote.TargetID, Username: vote.Username, CreationDate: vote.CreationDate}
+		if created.ID.IsZero() {
+			created.ID = primitive.NewObjectID()
+		}
+		return created
+	}, func(stored *Vote) {
+		stored.Value = vote.Value
+		stored.UpdationDate = vote.UpdationDate
+		stored.TargetAuthor = vote.TargetAuthor
+		stored.IPBlock = vote.IPBlock
+		stored.Suspicious = vote.Suspicious
+		stored.VoterCreatedAt = vote.VoterCreatedAt
+		stored.SuspicionReasons = addToSet(stored.SuspicionReasons, vote.SuspicionReasons...)
+	})
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, targetType string, targetID primitive.ObjectID, username string) error {
+	r.votes.Delete(byVoter(targetType, targetID, username))
+	return nil
+}
+
+func (r *memoryRepository) CountRecent(ctx context.Context, query RecentQuery) (int64, error) {
+	return r.votes.Count(query.matches), nil
+}
+
+func (r *memoryRepository) FindRecent(ctx context.Context, query RecentQuery) ([]Vote, error) {
+	return r.votes.Find(query.matches), nil
+}
+
+func (r *memoryRepository) LatestByUser(ctx context.Context, username string, excludeTarget primitive.ObjectID, limit int64) ([]Vote, error) {
+	rows := r.votes.Find(func(vote Vote) bool { return vote.Username == username && vote.TargetID != excludeTarget })
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].UpdationDate.After(rows[j].UpdationDate) })
+	if int64(len(rows)) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+func (r *memoryRepository) Queue(ctx context.Context, ids []primitive.ObjectID, reason string) error {
+	r.votes.Update(withIDs(ids), func(vote *Vote) {
+		vote.UnderReview = true
+		vote.SuspicionReasons = addToSet(vote.SuspicionReasons, reason)
+	})
+	return nil
+}
+
+func (r *memoryRepository) FindFlagged(ctx context.Context, targetType string, targetID primitive.ObjectID, usernames []string) ([]Vote, error) {
+	selected := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		selected[username] = true
+	}
+	return r.votes.Find(func(vote Vote) bool {
+		return vote.TargetType == targetType && vote.TargetID == targetID && isFlagged(vote) &&
+			(len(usernames) == 0 || selected[vote.Username])
+	}), nil
+}
+
+func (r *memoryRepository) Review(ctx context.Context, ids []primitive.ObjectID, confirmed bool) error {
+	r.votes.Update(withIDs(ids), func(vote *Vote) {
+		vote.Suspicious = confirmed
+		vote.UnderReview = false
+		if !confirmed {
+			vote.SuspicionReasons = nil
+		}
+	})
+	return nil
+}
+
+func (r *memoryRepository) Flagged(ctx context.Context, limit int64) ([]FlaggedCluster, error) {
+	type target struct {
+		targetType string
+		targetID   primitive.ObjectID
+	}
+	byTarget := map[target]*FlaggedCluster{}
+	clusters := []*FlaggedCluster{}
+	for _, vote := range r.votes.Find(isFlagged) {
+		key := target{vote.TargetType, vote.TargetID}
+		cluster, ok := byTarget[key]
+		if !ok {
+			cluster = &FlaggedCluster{
+				TargetType:   vote.TargetType,
+				TargetID:     vote.TargetID,
+				TargetAuthor: vote.TargetAuthor,
+				Usernames:    []string{},
+				IPBlocks:     []string{},
+				Reasons:      []string{},
+				FirstVote:    vote.UpdationDate,
+				LastVote:     vote.UpdationDate,
+			}
+			byTarget[key] = cluster
+			clusters = append(clusters, cluster)
+		}
+
+		cluster.Votes++
+		if !vote.Suspicious {
+			cluster.Queued++
+		}
+		switch vote.Value {
+		case 1:
+			cluster.UpVotes++
+		case -1:
+			cluster.DownVotes++
+		}
+		cluster.Usernames = addToSet(cluster.Usernames, vote.Username)
+		if vote.IPBlock != "" {
+			cluster.IPBlocks = addToSet(cluster.IPBlocks, vote.IPBlock)
+		}
+		cluster.Reasons = addToSet(cluster.Reasons, vote.SuspicionReasons...)
+		if vote.UpdationDate.Before(cluster.FirstVote) {
+			cluster.FirstVote = vote.UpdationDate
+		}
+		if vote.UpdationDate.After(cluster.LastVote) {
+			cluster.LastVote = vote.UpdationDate
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].Votes != clusters[j].Votes {
+			return clusters[i].Votes > clusters[j].Votes
+		}
+		return clusters[i].LastVote.After(clusters[j].LastVote)
+	})
+
+	result := []FlaggedCluster{}
+	for _, cluster := range clusters {
+		if int64(len(result)) == limit {
+			break
+		}
+		result = append(result, *cluster)
+	}
+	return result, nil
+}
+
+// matches mirrors the filter the Mongo repository builds from the query.
+func (q RecentQuery) matches(vote Vote) bool {
+	if vote.TargetType != q.TargetType || vote.TargetID != q.TargetID || vote.Username == q.ExcludeUsername {
+		return false
+	}
+	if vote.UpdationDate.Before(q.Since) {
+		return false
+	}
+	if !q.VoterCreatedSince.IsZero() && vote.VoterCreatedAt.Before(q.VoterCreatedSince) {
+		return false
+	}
+	if q.IPBlock != "" && vote.IPBlock != q.IPBlock {
+		return false
+	}
+	return !q.Unflagged || !vote.Suspicious
+}
+
+func (r *memoryRepository) DeleteTargets(ctx context.Context, targetIDs []primitive.ObjectID) error {
+	selected := make(map[primitive.ObjectID]bool, len(targetIDs))
+	for _, id := range targetIDs {
+		selected[id] = true
+	}
+	r.votes.Delete(func(vote Vote) bool { return selected[vote.TargetID] })
+	return nil
+}
+
+// isFlagged mirrors the Mongo repository's flaggedFilter.
+func isFlagged(vote Vote) bool {
+	return vote.Suspicious || vote.UnderReview
+}
+
+func withIDs(ids []primitive.ObjectID) func(Vote) bool {
+	selected := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	return func(vote Vote) bool { return selected[vote.ID] }
+}
+
+func byVoter(targetType string, targetID primitive.ObjectID, username string) func(Vote) bool {
+	return func(vote Vote) bool {
+		return vote.TargetType == targetType && vote.TargetID == targetID && vote.Username == username
+	}
+}
+
+// addToSet returns a copy of set with the values it lacks appended.
+func addToSet(set []string, values ...string) []string {
+	result := append(make([]string, 0, len(set)+len(values)), set...)
+	for _, value := range values {
+		found := false
+		for _, existing := range result {
+			found = found || existing == value
+		}
+		if !found {
+			result = append(result, value)
+		}
+	}
+	return result
+}
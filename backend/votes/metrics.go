@@ -0,0 +1,27 @@
+package votes
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	votesCast = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "simple_reddit_votes_cast_total",
+		Help: "Votes cast or changed, by target type, direction and whether they were flagged as suspicious.",
+	}, []string{"target_type", "direction", "suspicious"})
+	votesRemoved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "simple_reddit_votes_removed_total",
+		Help: "Votes withdrawn, by target type.",
+	}, []string{"target_type"})
+)
+
+func countVote(vote Vote) {
+	direction := "up"
+	if vote.Value < 0 {
+		direction = "down"
+	}
+	votesCast.WithLabelValues(vote.TargetType, direction, strconv.FormatBool(vote.Suspicious)).Inc()
+}
@@ -3,9 +3,7 @@ package votes
 import (
 	"time"
 
-	"github.com/ganesh96/simple-reddit/backend/configs"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 const (
@@ -13,19 +11,68 @@ const (
 	TargetComment = "comment"
 )
 
-var VotesCollection *mongo.Collection = configs.GetCollection("votes")
-
 // Vote stores one user's latest vote for one target. Counters stay denormalized on posts/comments for fast feeds.
+// Suspicious votes are kept for review but are not included in those counters. Votes UnderReview were cast
+// before a suspicious one joined them; they still count until an administrator confirms them.
 type Vote struct {
-	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	TargetID     primitive.ObjectID `json:"target_id" bson:"target_id,omitempty"`
-	TargetType   string             `json:"target_type" bson:"target_type,omitempty"`
-	Username     string             `json:"username" bson:"username,omitempty"`
-	Value        int                `json:"value" bson:"value"`
-	CreationDate time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
-	UpdationDate time.Time          `json:"updation_date" bson:"updation_date,omitempty"`
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TargetID         primitive.ObjectID `json:"target_id" bson:"target_id,omitempty"`
+	TargetType       string             `json:"target_type" bson:"target_type,omitempty"`
+	TargetAuthor     string             `json:"target_author" bson:"target_author,omitempty"`
+	Username         string             `json:"username" bson:"username,omitempty"`
+	Value            int                `json:"value" bson:"value"`
+	VoterCreatedAt   time.Time          `json:"-" bson:"voter_created_at,omitempty"`
+	IPBlock          string             `json:"-" bson:"ip_block,omitempty"`
+	Suspicious       bool               `json:"suspicious" bson:"suspicious"`
+	UnderReview      bool               `json:"under_review" bson:"under_review,omitempty"`
+	SuspicionReasons []string           `json:"suspicion_reasons,omitempty" bson:"suspicion_reasons,omitempty"`
+	CreationDate     time.Time          `json:"creation_date" bson:"creation_date,omitempty"`
+	UpdationDate     time.Time          `json:"updation_date" bson:"updation_date,omitempty"`
+}
+
+func (v Vote) GetID() primitive.ObjectID {
+	return v.ID
+}
+
+// FlaggedCluster groups the suspicious and queued votes cast on one target for the admin report.
+// Queued counts the votes awaiting review, which are still included in the target's score.
+type FlaggedCluster struct {
+	TargetType   string             `json:"target_type" bson:"target_type"`
+	TargetID     primitive.ObjectID `json:"target_id" bson:"target_id"`
+	TargetAuthor string             `json:"target_author" bson:"target_author"`
+	Votes        int                `json:"votes" bson:"votes"`
+	Queued       int                `json:"queued" bson:"queued"`
+	UpVotes      int                `json:"up_votes" bson:"up_votes"`
+	DownVotes    int                `json:"down_votes" bson:"down_votes"`
+	Usernames    []string           `json:"usernames" bson:"usernames"`
+	IPBlocks     []string           `json:"ip_blocks" bson:"ip_blocks"`
+	Reasons      []string           `json:"reasons" bson:"reasons"`
+	FirstVote    time.Time          `json:"first_vote" bson:"first_vote"`
+	LastVote     time.Time          `json:"last_vote" bson:"last_vote"`
+}
+
+// ReviewVotesRequest selects the flagged votes of a target an administrator decides on; all of them when Usernames is empty.
+type ReviewVotesRequest struct {
+	Usernames []string `json:"usernames" binding:"max=500"`
+}
+
+// VoteResponse is the data of a saved vote.
+type VoteResponse struct {
+	Message string `json:"message"`
+	Vote    int    `json:"vote"`
+}
+
+// FlaggedVotesResponse is the data of the admin report of suspicious votes.
+type FlaggedVotesResponse struct {
+	Clusters []FlaggedCluster `json:"clusters"`
+}
+
+// ReviewVotesResponse is the data of an administrator's decision on flagged votes.
+type ReviewVotesResponse struct {
+	Message  string `json:"message"`
+	Reviewed int    `json:"reviewed"`
 }
 
 type VoteRequest struct {
-	Vote int `json:"vote" binding:"required"`
+	Vote int `json:"vote" binding:"required,oneof=1 -1"`
 }
@@ -0,0 +1,194 @@
+package votes
+
+import (
+	"context"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	votes *mongo.Collection
+}
+
+// NewMongoRepository stores votes in the votes collection of db.
+func NewMongoRepository(db *mongo.Database) Repository {
+	return &mongoRepository{votes: db.Collection("votes")}
+}
+
+func (r *mongoRepository) Find(ctx context.Context, targetType string, targetID primitive.ObjectID, username string) (Vote, error) {
+	var vote Vote
+	err := r.votes.FindOne(ctx, voteKey(targetType, targetID, username)).Decode(&vote)
+	return vote, common.MongoError(err)
+}
+
+func (r *mongoRepository) Save(ctx context.Context, vote Vote) error {
+	id := vote.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"value":            vote.Value,
+			"updation_date":    vote.UpdationDate,
+			"target_author":    vote.TargetAuthor,
+			"ip_block":         vote.IPBlock,
+			"suspicious":       vote.Suspicious,
+			"voter_created_at": vote.VoterCreatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id":           id,
+			"target_type":   vote.TargetType,
+			"target_id":     vote.TargetID,
+			"username":      vote.Username,
+			"creation_date": vote.CreationDate,
+		},
+	}
+	if len(vote.SuspicionReasons) > 0 {
+		update["$addToSet"] = bson.M{"suspicion_reasons": bson.M{"$each": vote.SuspicionReasons}}
+	}
+	_, err := r.votes.UpdateOne(ctx, voteKey(vote.TargetType, vote.TargetID, vote.Username), update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoRepository) Delete(ctx context.Context, targetType string, targetID primitive.ObjectID, username string) error {
+	_, err := r.votes.DeleteOne(ctx, voteKey(targetType, targetID, username))
+	return err
+}
+
+func (r *mongoRepository) CountRecent(ctx context.Context, query RecentQuery) (int64, error) {
+	return r.votes.CountDocuments(ctx, recentFilter(query))
+}
+
+func (r *mongoRepository) FindRecent(ctx context.Context, query RecentQuery) ([]Vote, error) {
+	cursor, err := r.votes.Find(ctx, recentFilter(query), options.Find().SetProjection(bson.M{"value": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Vote
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) LatestByUser(ctx context.Context, username string, excludeTarget primitive.ObjectID, limit int64) ([]Vote, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "updation_date", Value: -1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"target_author": 1})
+	filter := bson.M{"username": username, "target_id": bson.M{"$ne": excludeTarget}}
+
+	cursor, err := r.votes.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Vote
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) Queue(ctx context.Context, ids []primitive.ObjectID, reason string) error {
+	update := bson.M{"$set": bson.M{"under_review": true}, "$addToSet": bson.M{"suspicion_reasons": reason}}
+	_, err := r.votes.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, update)
+	return err
+}
+
+func (r *mongoRepository) Flagged(ctx context.Context, limit int64) ([]FlaggedCluster, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: flaggedFilter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           bson.M{"target_type": "$target_type", "target_id": "$target_id"},
+			"target_type":   bson.M{"$first": "$target_type"},
+			"target_id":     bson.M{"$first": "$target_id"},
+			"target_author": bson.M{"$first": "$target_author"},
+			"votes":         bson.M{"$sum": 1},
+			"queued":        bson.M{"$sum": bson.M{"$cond": bson.A{"$suspicious", 0, 1}}},
+			"up_votes":      bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$value", 1}}, 1, 0}}},
+			"down_votes":    bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$value", -1}}, 1, 0}}},
+			"usernames":     bson.M{"$addToSet": "$username"},
+			"ip_blocks":     bson.M{"$addToSet": "$ip_block"},
+			"reasons":       bson.M{"$push": "$suspicion_reasons"},
+			"first_vote":    bson.M{"$min": "$updation_date"},
+			"last_vote":     bson.M{"$max": "$updation_date"},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"reasons": bson.M{"$reduce": bson.M{
+			"input":        "$reasons",
+			"initialValue": bson.A{},
+			"in":           bson.M{"$setUnion": bson.A{"$$value", bson.M{"$ifNull": bson.A{"$$this", bson.A{}}}}},
+		}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "votes", Value: -1}, {Key: "last_vote", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.votes.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	clusters := []FlaggedCluster{}
+	err = cursor.All(ctx, &clusters)
+	return clusters, err
+}
+
+func (r *mongoRepository) FindFlagged(ctx context.Context, targetType string, targetID primitive.ObjectID, usernames []string) ([]Vote, error) {
+	filter := bson.M{"target_type": targetType, "target_id": targetID, "$or": flaggedFilter["$or"]}
+	if len(usernames) > 0 {
+		filter["username"] = bson.M{"$in": usernames}
+	}
+	cursor, err := r.votes.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []Vote
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+func (r *mongoRepository) Review(ctx context.Context, ids []primitive.ObjectID, confirmed bool) error {
+	update := bson.M{"$set": bson.M{"suspicious": true}, "$unset": bson.M{"under_review": ""}}
+	if !confirmed {
+		update = bson.M{"$set": bson.M{"suspicious": false}, "$unset": bson.M{"under_review": "", "suspicion_reasons": ""}}
+	}
+	_, err := r.votes.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, update)
+	return err
+}
+
+func (r *mongoRepository) DeleteTargets(ctx context.Context, targetIDs []primitive.ObjectID) error {
+	_, err := r.votes.DeleteMany(ctx, bson.M{"target_id": bson.M{"$in": targetIDs}})
+	return err
+}
+
+// flaggedFilter selects the votes the admin report lists.
+var flaggedFilter = bson.M{"$or": bson.A{bson.M{"suspicious": true}, bson.M{"under_review": true}}}
+
+func voteKey(targetType string, targetID primitive.ObjectID, username string) bson.M {
+	return bson.M{"target_type": targetType, "target_id": targetID, "username": username}
+}
+
+func recentFilter(query RecentQuery) bson.M {
+	filter := bson.M{
+		"target_type":   query.TargetType,
+		"target_id":     query.TargetID,
+		"username":      bson.M{"$ne": query.ExcludeUsername},
+		"updation_date": bson.M{"$gte": query.Since},
+	}
+	if !query.VoterCreatedSince.IsZero() {
+		filter["voter_created_at"] = bson.M{"$gte": query.VoterCreatedSince}
+	}
+	if query.IPBlock != "" {
+		filter["ip_block"] = query.IPBlock
+	}
+	if query.Unflagged {
+		filter["suspicious"] = bson.M{"$ne": true}
+	}
+	return filter
+}
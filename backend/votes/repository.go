@@ -0,0 +1,57 @@
+package votes
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganesh96/simple-reddit/backend/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RecentQuery selects the recent votes other users cast on one target, which the brigading checks compare a new vote with.
+type RecentQuery struct {
+	TargetType      string
+	TargetID        primitive.ObjectID
+	ExcludeUsername string
+	Since           time.Time
+	// VoterCreatedSince, when set, selects votes from accounts created at or after it.
+	VoterCreatedSince time.Time
+	IPBlock           string
+	// Unflagged selects only votes not already marked suspicious.
+	Unflagged bool
+}
+
+// Repository stores votes, one per user and target. Lookups that find nothing return common.ErrNotFound.
+type Repository interface {
+	Find(ctx context.Context, targetType string, targetID primitive.ObjectID, username string) (Vote, error)
+	// Save records the vote of vote.Username on its target, replacing the previous one but keeping its ID and
+	// creation date. vote.SuspicionReasons are added to the reasons already recorded.
+	Save(ctx context.Context, vote Vote) error
+	Delete(ctx context.Context, targetType string, targetID primitive.ObjectID, username string) error
+	CountRecent(ctx context.Context, query RecentQuery) (int64, error)
+	FindRecent(ctx context.Context, query RecentQuery) ([]Vote, error)
+	// LatestByUser returns up to limit of the user's votes on targets other than excludeTarget, most recently updated first.
+	LatestByUser(ctx context.Context, username string, excludeTarget primitive.ObjectID, limit int64) ([]Vote, error)
+	// Queue marks the votes with ids for administrator review because of reason, without making them suspicious.
+	Queue(ctx context.Context, ids []primitive.ObjectID, reason string) error
+	// Flagged groups suspicious and queued votes by target, largest clusters first, returning up to limit of them.
+	Flagged(ctx context.Context, limit int64) ([]FlaggedCluster, error)
+	// FindFlagged returns the suspicious and queued votes on one target, only those of usernames unless it is empty.
+	FindFlagged(ctx context.Context, targetType string, targetID primitive.ObjectID, usernames []string) ([]Vote, error)
+	// Review records an administrator's decision on the votes with ids and takes them off the queue.
+	// Confirmed votes become suspicious; cleared ones stop being suspicious and lose their recorded reasons.
+	Review(ctx context.Context, ids []primitive.ObjectID, confirmed bool) error
+	// DeleteTargets removes every vote on the posts or comments with targetIDs.
+	DeleteTargets(ctx context.Context, targetIDs []primitive.ObjectID) error
+}
+
+var repository common.Holder[Repository]
+
+// SetRepository installs the repository used by the handlers.
+func SetRepository(r Repository) {
+	repository.Set(r)
+}
+
+func repo() Repository {
+	return repository.Get()
+}
@@ -1,19 +1,24 @@
 package votes
 
 import (
+	"context"
 	"errors"
-	"log"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ganesh96/simple-reddit/backend/comments"
 	"github.com/ganesh96/simple-reddit/backend/common"
+	"github.com/ganesh96/simple-reddit/backend/communities"
+	"github.com/ganesh96/simple-reddit/backend/events"
+	"github.com/ganesh96/simple-reddit/backend/logging"
+	"github.com/ganesh96/simple-reddit/backend/modlog"
 	"github.com/ganesh96/simple-reddit/backend/posts"
+	"github.com/ganesh96/simple-reddit/backend/users"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func VotePost(c *gin.Context) {
@@ -35,136 +40,356 @@ func DeleteCommentVote(c *gin.Context) {
 func upsertVote(c *gin.Context, targetType string, paramName string) {
 	targetID, err := primitive.ObjectIDFromHex(c.Param(paramName))
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid target ID"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid target ID"))
 		return
 	}
 
 	var req VoteRequest
-	if err := c.ShouldBindJSON(&req); err != nil || (req.Vote != 1 && req.Vote != -1) {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_REQUEST_BODY, gin.H{"error": "vote must be 1 or -1"})
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondWithError(c, common.BindError(err))
 		return
 	}
 
-	targetCollection, err := targetCollection(targetType)
-	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid target type"})
-		return
-	}
-
-	count, err := targetCollection.CountDocuments(c.Request.Context(), bson.M{"_id": targetID})
-	if err != nil || count == 0 {
-		common.RespondWithJSON(c, http.StatusNotFound, common.POST_NOT_FOUND, gin.H{"error": "Target not found"})
+	target, ok := findVotableTarget(c, targetType, targetID)
+	if !ok {
 		return
 	}
 
 	username := c.GetString("username")
-	filter := bson.M{"target_type": targetType, "target_id": targetID, "username": username}
 
 	oldVote := 0
-	var existing Vote
-	err = VotesCollection.FindOne(c.Request.Context(), filter).Decode(&existing)
+	existing, err := repo().Find(c.Request.Context(), targetType, targetID, username)
 	if err == nil {
 		oldVote = existing.Value
-	} else if !errors.Is(err, mongo.ErrNoDocuments) {
-		log.Printf("Error finding vote: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to read vote"})
+	} else if !errors.Is(err, common.ErrNotFound) {
+		logging.FromContext(c.Request.Context()).Error("error finding vote", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to read vote"))
 		return
 	}
 
 	if oldVote == req.Vote {
-		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Vote already applied"})
+		common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Vote already applied"})
 		return
 	}
 
 	now := time.Now()
-	update := bson.M{
-		"$set": bson.M{"value": req.Vote, "updation_date": now},
-		"$setOnInsert": bson.M{
-			"_id":           primitive.NewObjectID(),
-			"target_type":  targetType,
-			"target_id":    targetID,
-			"username":     username,
-			"creation_date": now,
-		},
-	}
-	_, err = VotesCollection.UpdateOne(c.Request.Context(), filter, update, options.Update().SetUpsert(true))
+	vote := Vote{
+		TargetID:     targetID,
+		TargetType:   targetType,
+		TargetAuthor: target.Username,
+		Username:     username,
+		Value:        req.Vote,
+		IPBlock:      ipBlock(c.ClientIP()),
+		CreationDate: now,
+		UpdationDate: now,
+	}
+	if voter, err := users.Repo().FindByUsername(c.Request.Context(), username); err == nil {
+		vote.VoterCreatedAt = voter.ID.Timestamp()
+	}
+
+	assessment, err := assessVote(c.Request.Context(), vote, now)
 	if err != nil {
-		log.Printf("Error saving vote: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to save vote"})
+		logging.FromContext(c.Request.Context()).Error("error assessing vote", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to save vote"))
+		return
+	}
+	// A flagged vote stays flagged until an administrator clears it, so flipping it cannot sneak it back into the score.
+	vote.Suspicious = existing.Suspicious || len(assessment.reasons) > 0
+	vote.SuspicionReasons = assessment.reasons
+
+	if err := repo().Save(c.Request.Context(), vote); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error saving vote", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to save vote"))
 		return
 	}
+	countVote(vote)
 
-	inc := voteCounterDelta(oldVote, req.Vote)
-	_, err = targetCollection.UpdateOne(c.Request.Context(), bson.M{"_id": targetID}, bson.M{"$inc": inc})
+	if len(assessment.peers) > 0 {
+		if err := queuePeers(c.Request.Context(), assessment.peers); err != nil {
+			logging.FromContext(c.Request.Context()).Error("error queueing vote cluster for review", "error", err)
+		}
+	}
+	delta := voteCounterDelta(countedValue(oldVote, existing.Suspicious), countedValue(req.Vote, vote.Suspicious))
+	counters, err := applyVoteCounterDelta(c.Request.Context(), targetType, targetID, delta)
 	if err != nil {
-		log.Printf("Error updating vote counters: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to update vote counters"})
+		logging.FromContext(c.Request.Context()).Error("error updating vote counters", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update vote counters"))
 		return
 	}
+	publishVoteCounts(targetType, counters)
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Vote saved successfully", "vote": req.Vote})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, VoteResponse{Message: "Vote saved successfully", Vote: req.Vote})
 }
 
 func deleteVote(c *gin.Context, targetType string, paramName string) {
 	targetID, err := primitive.ObjectIDFromHex(c.Param(paramName))
 	if err != nil {
-		common.RespondWithJSON(c, http.StatusBadRequest, common.INVALID_PARAM, gin.H{"error": "Invalid target ID"})
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid target ID"))
 		return
 	}
 
 	username := c.GetString("username")
-	filter := bson.M{"target_type": targetType, "target_id": targetID, "username": username}
 
-	var existing Vote
-	if err := VotesCollection.FindOne(c.Request.Context(), filter).Decode(&existing); err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Vote already removed"})
+	existing, err := repo().Find(c.Request.Context(), targetType, targetID, username)
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Vote already removed"})
 			return
 		}
-		log.Printf("Error finding vote: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to read vote"})
+		logging.FromContext(c.Request.Context()).Error("error finding vote", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to read vote"))
 		return
 	}
 
-	_, err = VotesCollection.DeleteOne(c.Request.Context(), filter)
-	if err != nil {
-		log.Printf("Error deleting vote: %v", err)
-		common.RespondWithJSON(c, http.StatusInternalServerError, common.MONGO_DB_ERROR, gin.H{"error": "Failed to delete vote"})
+	if _, ok := findVotableTarget(c, targetType, targetID); !ok {
 		return
 	}
 
-	targetCollection, err := targetCollection(targetType)
-	if err == nil {
-		_, _ = targetCollection.UpdateOne(c.Request.Context(), bson.M{"_id": targetID}, bson.M{"$inc": voteCounterDelta(existing.Value, 0)})
+	if err := repo().Delete(c.Request.Context(), targetType, targetID, username); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error deleting vote", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to delete vote"))
+		return
+	}
+	votesRemoved.WithLabelValues(targetType).Inc()
+
+	delta := voteCounterDelta(countedValue(existing.Value, existing.Suspicious), 0)
+	if counters, err := applyVoteCounterDelta(c.Request.Context(), targetType, targetID, delta); err == nil {
+		publishVoteCounts(targetType, counters)
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, common.MessageResponse{Message: "Vote removed successfully"})
+}
+
+// findVotableTarget loads the post or comment a vote is cast on or withdrawn from, and checks that the caller
+// may write to its community and that its post is not locked. When it returns false the error response has
+// already been written.
+func findVotableTarget(c *gin.Context, targetType string, targetID primitive.ObjectID) (voteCounters, bool) {
+	target, err := findTarget(c.Request.Context(), targetType, targetID)
+	if errors.Is(err, errUnsupportedTarget) {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid target type"))
+		return voteCounters{}, false
+	}
+	if err != nil {
+		common.RespondWithError(c, targetNotFound(targetType))
+		return voteCounters{}, false
 	}
+	post, ok := posts.FindAccessiblePost(c, target.postID(targetType), communities.AccessWrite)
+	if !ok {
+		return voteCounters{}, false
+	}
+	if post.Locked {
+		common.RespondWithError(c, common.NewAPIError(http.StatusForbidden, common.POST_LOCKED, "This post is locked and no longer accepts votes"))
+		return voteCounters{}, false
+	}
+	return target, true
+}
+
+// countedValue is the part of a vote reflected in displayed scores; suspicious votes count for nothing.
+func countedValue(value int, suspicious bool) int {
+	if suspicious {
+		return 0
+	}
+	return value
+}
 
-	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, gin.H{"message": "Vote removed successfully"})
+// counterDelta is the change a vote makes to its target's counters.
+type counterDelta struct {
+	upVotes   int
+	downVotes int
 }
 
-func voteCounterDelta(oldVote int, newVote int) bson.M {
-	inc := bson.M{"up_votes": 0, "down_votes": 0}
+func (d counterDelta) add(other counterDelta) counterDelta {
+	return counterDelta{upVotes: d.upVotes + other.upVotes, downVotes: d.downVotes + other.downVotes}
+}
+
+func voteCounterDelta(oldVote int, newVote int) counterDelta {
+	var delta counterDelta
 	if oldVote == 1 {
-		inc["up_votes"] = inc["up_votes"].(int) - 1
+		delta.upVotes--
 	}
 	if oldVote == -1 {
-		inc["down_votes"] = inc["down_votes"].(int) - 1
+		delta.downVotes--
 	}
 	if newVote == 1 {
-		inc["up_votes"] = inc["up_votes"].(int) + 1
+		delta.upVotes++
 	}
 	if newVote == -1 {
-		inc["down_votes"] = inc["down_votes"].(int) + 1
+		delta.downVotes++
+	}
+	return delta
+}
+
+// voteCounters is the subset of a post or comment needed to check access and broadcast its new score.
+type voteCounters struct {
+	ID        primitive.ObjectID
+	PostID    primitive.ObjectID
+	Community primitive.ObjectID
+	Username  string
+	UpVotes   int
+	DownVotes int
+}
+
+// postID returns the post a vote target belongs to: itself for posts, its parent post for comments.
+func (v voteCounters) postID(targetType string) primitive.ObjectID {
+	if targetType == TargetPost {
+		return v.ID
+	}
+	return v.PostID
+}
+
+var errUnsupportedTarget = errors.New("unsupported target type")
+
+func findTarget(ctx context.Context, targetType string, targetID primitive.ObjectID) (voteCounters, error) {
+	switch targetType {
+	case TargetPost:
+		post, err := posts.Repo().FindByID(ctx, targetID)
+		return postCounters(post), err
+	case TargetComment:
+		comment, err := comments.Repo().FindByID(ctx, targetID)
+		return commentCounters(comment), err
+	default:
+		return voteCounters{}, errUnsupportedTarget
 	}
-	return inc
 }
 
-func targetCollection(targetType string) (*mongo.Collection, error) {
+func applyVoteCounterDelta(ctx context.Context, targetType string, targetID primitive.ObjectID, delta counterDelta) (voteCounters, error) {
 	switch targetType {
 	case TargetPost:
-		return posts.PostCollection, nil
+		post, err := posts.Repo().AddCounters(ctx, targetID, posts.Counters{UpVotes: delta.upVotes, DownVotes: delta.downVotes})
+		return postCounters(post), err
 	case TargetComment:
-		return comments.CommentsCollection, nil
+		comment, err := comments.Repo().AddVotes(ctx, targetID, delta.upVotes, delta.downVotes)
+		return commentCounters(comment), err
 	default:
-		return nil, errors.New("unsupported target type")
+		return voteCounters{}, errUnsupportedTarget
+	}
+}
+
+func postCounters(post posts.Post) voteCounters {
+	return voteCounters{ID: post.ID, Community: post.Community, Username: post.Username, UpVotes: post.UpVotes, DownVotes: post.DownVotes}
+}
+
+func commentCounters(comment comments.Comment) voteCounters {
+	return voteCounters{ID: comment.ID, PostID: comment.PostID, Community: comment.Community, Username: comment.Username, UpVotes: comment.UpVotes, DownVotes: comment.DownVotes}
+}
+
+func publishVoteCounts(targetType string, counters voteCounters) {
+	upVotes, downVotes := common.FuzzVoteCounts(counters.ID, counters.UpVotes, counters.DownVotes)
+	events.PublishPostEvent(counters.postID(targetType), events.VotesChanged, gin.H{
+		"target_type": targetType,
+		"target_id":   counters.ID,
+		"up_votes":    upVotes,
+		"down_votes":  downVotes,
+	})
+}
+
+func targetNotFound(targetType string) *common.APIError {
+	if targetType == TargetComment {
+		return common.NewAPIError(http.StatusNotFound, common.COMMENT_NOT_FOUND, "Comment not found")
+	}
+	return common.NewAPIError(http.StatusNotFound, common.POST_NOT_FOUND, "Post not found")
+}
+
+// GetFlaggedVotes lists targets that received suspicious votes, largest clusters first, for administrators to review.
+func GetFlaggedVotes(c *gin.Context) {
+	limit := int64(50)
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 1 || parsed > 200 {
+			common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "limit must be between 1 and 200"))
+			return
+		}
+		limit = parsed
+	}
+
+	clusters, err := repo().Flagged(c.Request.Context(), limit)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error aggregating flagged votes", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to retrieve flagged votes"))
+		return
+	}
+
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, FlaggedVotesResponse{Clusters: clusters})
+}
+
+// DeleteTargets deletes every vote on the posts or comments with targetIDs, once they are deleted themselves.
+func DeleteTargets(ctx context.Context, targetIDs []primitive.ObjectID) error {
+	return repo().DeleteTargets(ctx, targetIDs)
+}
+
+// ConfirmFlaggedVotes discounts a target's suspicious and queued votes from its score and clears the queue.
+func ConfirmFlaggedVotes(c *gin.Context) {
+	reviewFlaggedVotes(c, true)
+}
+
+// ClearFlaggedVotes restores a target's suspicious and queued votes to its score, for false positives.
+func ClearFlaggedVotes(c *gin.Context) {
+	reviewFlaggedVotes(c, false)
+}
+
+func reviewFlaggedVotes(c *gin.Context, confirmed bool) {
+	targetType := c.Param("targetType")
+	if targetType != TargetPost && targetType != TargetComment {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid target type"))
+		return
+	}
+	targetID, err := primitive.ObjectIDFromHex(c.Param("targetId"))
+	if err != nil {
+		common.RespondWithError(c, common.NewAPIError(http.StatusBadRequest, common.INVALID_PARAM, "Invalid target ID"))
+		return
+	}
+
+	var req ReviewVotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		common.RespondWithError(c, common.BindError(err))
+		return
+	}
+
+	flagged, err := repo().FindFlagged(c.Request.Context(), targetType, targetID, req.Usernames)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error finding flagged votes", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to review votes"))
+		return
+	}
+	if len(flagged) == 0 {
+		common.RespondWithError(c, common.NewAPIError(http.StatusNotFound, common.VOTE_NOT_FOUND, "No flagged votes on this target"))
+		return
+	}
+
+	ids := make([]primitive.ObjectID, len(flagged))
+	var delta counterDelta
+	for i, vote := range flagged {
+		ids[i] = vote.ID
+		delta = delta.add(voteCounterDelta(countedValue(vote.Value, vote.Suspicious), countedValue(vote.Value, confirmed)))
+	}
+	if err := repo().Review(c.Request.Context(), ids, confirmed); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error reviewing votes", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to review votes"))
+		return
+	}
+	counters, err := applyVoteCounterDelta(c.Request.Context(), targetType, targetID, delta)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("error updating vote counters", "error", err)
+		common.RespondWithError(c, common.NewAPIError(http.StatusInternalServerError, common.MONGO_DB_ERROR, "Failed to update vote counters"))
+		return
+	}
+	publishVoteCounts(targetType, counters)
+
+	message, action := "Votes cleared and counted again", modlog.ActionClearVotes
+	if confirmed {
+		message, action = "Votes confirmed as suspicious", modlog.ActionConfirmVotes
 	}
+	community, _ := communities.FindByID(c.Request.Context(), counters.Community)
+	modlog.Record(c.Request.Context(), modlog.Entry{
+		CommunityID:   counters.Community,
+		CommunityName: community.Name,
+		Moderator:     c.GetString("username"),
+		Admin:         true,
+		Action:        action,
+		TargetType:    targetType,
+		TargetID:      targetID,
+		TargetUser:    counters.Username,
+		Details:       fmt.Sprintf("%d votes", len(flagged)),
+	})
+	common.RespondWithJSON(c, http.StatusOK, common.SUCCESS, ReviewVotesResponse{Message: message, Reviewed: len(flagged)})
 }
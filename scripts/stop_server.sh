@@ -4,7 +4,15 @@ echo $SIMPLE_REDDIT_PID
 if [[ -n $SIMPLE_REDDIT_PID ]]; 
 then
     echo "Stopping Server."
-    kill -s 9 $SIMPLE_REDDIT_PID
+    # SIGTERM lets the server drain in-flight requests; it gives up after 20 seconds.
+    kill -s TERM $SIMPLE_REDDIT_PID
+    for attempt in $(seq 1 30); do
+        kill -0 $SIMPLE_REDDIT_PID 2>/dev/null || break
+        sleep 1
+    done
+    if kill -0 $SIMPLE_REDDIT_PID 2>/dev/null; then
+        kill -s 9 $SIMPLE_REDDIT_PID
+    fi
     echo "Stopped Server."
 else
     echo "Server is already stopped."
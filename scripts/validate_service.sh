@@ -1,2 +1,10 @@
 #!/bin/bash
-curl http://localhost:8080/ping
+# Wait for the API to report ready: MongoDB reachable and indexes ensured.
+for attempt in $(seq 1 30); do
+    if curl -fsS http://localhost:8080/readyz; then
+        exit 0
+    fi
+    sleep 2
+done
+echo "service did not become ready" >&2
+exit 1